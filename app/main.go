@@ -4,11 +4,22 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
 	"github.com/codecrafters-io/redis-starter-go/internal/command"
 	"github.com/codecrafters-io/redis-starter-go/internal/config"
+	"github.com/codecrafters-io/redis-starter-go/internal/notify"
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/rdb"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 	"github.com/codecrafters-io/redis-starter-go/internal/server"
+	"github.com/codecrafters-io/redis-starter-go/internal/slowlog"
+	"github.com/codecrafters-io/redis-starter-go/internal/stats"
 	"github.com/codecrafters-io/redis-starter-go/internal/storage"
 	"github.com/codecrafters-io/redis-starter-go/internal/storage/memory"
 )
@@ -22,8 +33,21 @@ func main() {
 	cfg := config.NewConfig()
 	cfg.LoadFromArgs()
 
+	// Pub/sub message routing shared by all clients
+	hub := pubsub.NewHub()
+
+	// Keyspace notifications, reconfigurable with CONFIG SET
+	notifier := notify.NewNotifier(hub)
+	if err := notifier.Configure(cfg.NotifyKeyspaceEvents); err != nil {
+		fmt.Printf("Warning: Invalid notify-keyspace-events: %v\n", err)
+	}
+	cfg.OnChange("notify-keyspace-events", func(value string) {
+		notifier.Configure(value)
+	})
+
 	// In-memory key-value store
 	var store = memory.NewStore()
+	store.SetNotifier(notifier)
 
 	// Load RDB file if exists
 	err := loadRDBData(store, cfg.DbFilePath())
@@ -31,6 +55,9 @@ func main() {
 		fmt.Printf("Warning: Failed to load RDB file: %v\n", err)
 	}
 
+	// Snapshots of the store are saved to the same file
+	saver := rdb.NewSaver(store, cfg.DbFilePath, command.ServerVersion)
+
 	// Set up replication if needed
 	if cfg.ReplicationConfig.Role == "slave" {
 		_, err := cfg.ReplicationConfig.HandshakeWithMaster()
@@ -41,12 +68,99 @@ func main() {
 	}
 
 	// Set up command registry and register commands
+	serverStats := stats.NewStats()
 	registry := command.NewRegistry()
-	registerCommands(registry, store, cfg)
+
+	// Users and their permissions. requirepass sets the default user's
+	// password, on top of the users loaded from the aclfile.
+	acls := acl.New(func(name string) bool {
+		_, ok := registry.Original(name)
+		return ok
+	})
+	acls.Log().SetMaxLen(cfg.ACLLogMaxLen)
+	cfg.OnChange("acllog-max-len", func(value string) {
+		n, _ := strconv.Atoi(value)
+		acls.Log().SetMaxLen(n)
+	})
+
+	// Every command passes through the stats, then the permission checks
+	// and the refusal of writes while saving fails, then the slow log, which
+	// only times commands that were allowed to run
+	commandStats := stats.NewCommandStats()
+	slowLog := slowlog.NewLog(cfg.SlowLogMaxLen)
+	cfg.OnChange("slowlog-max-len", func(value string) {
+		n, _ := strconv.Atoi(value)
+		slowLog.SetMaxLen(n)
+	})
+	registry.Use(command.NewStatsMiddleware(commandStats))
+	registry.Use(command.NewACLMiddleware(acls))
+	registry.Use(command.NewDiskErrorMiddleware(func() bool {
+		return cfg.GetStopWritesOnBgsaveError() && len(cfg.GetSavePoints()) > 0 && saver.LastBgsaveFailed()
+	}))
+	registry.Use(command.NewSlowLogMiddleware(slowLog, cfg.GetSlowLogSlowerThan))
+	if err := registerCommands(registry, store, cfg, hub, acls, serverStats, commandStats, slowLog, saver); err != nil {
+		fmt.Printf("Error registering commands: %v\n", err)
+		os.Exit(1)
+	}
+
+	if cfg.ACLFile != "" {
+		if err := acls.Load(cfg.ACLFile); err != nil && !os.IsNotExist(err) {
+			fmt.Printf("Error loading ACL file: %v\n", err)
+			os.Exit(1)
+		}
+	}
+	if cfg.RequirePass != "" {
+		acls.SetDefaultPassword(cfg.RequirePass)
+	}
+	cfg.OnChange("requirepass", func(value string) {
+		acls.SetDefaultPassword(value)
+	})
 
 	// Create and start server
-	parser := resp.NewParser()
-	redisServer := server.NewServer("0.0.0.0", cfg.Port, registry, parser)
+	parser := resp.NewStreamParser()
+	parser.SetLimits(cfg.ProtocolLimits())
+	for _, key := range []string{"proto-max-bulk-len", "proto-max-multibulk-len", "client-query-buffer-limit"} {
+		cfg.OnChange(key, func(string) {
+			parser.SetLimits(cfg.ProtocolLimits())
+		})
+	}
+
+	outputLimits := client.NewOutputLimits()
+	outputLimits.Set(cfg.OutputLimits())
+	cfg.OnChange("client-output-buffer-limit", func(string) {
+		outputLimits.Set(cfg.OutputLimits())
+	})
+
+	redisServer := server.NewServer(cfg, registry, parser, hub, outputLimits, serverStats)
+	redisServer.SetACL(acls)
+	redisServer.SetSaver(saver)
+	if err := registry.Register(command.NewShutdownCommand(redisServer)); err != nil {
+		fmt.Printf("Error registering commands: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Rename or disable commands once they are all registered
+	for _, rename := range cfg.RenameCommands {
+		if err := registry.Rename(rename.Name, rename.NewName); err != nil {
+			fmt.Printf("Error in rename-command: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// Certificates are reloaded whenever their settings change, e.g. after
+	// rotating the files in place and setting the same paths again
+	for _, key := range []string{"tls-cert-file", "tls-key-file", "tls-ca-cert-file", "tls-auth-clients"} {
+		cfg.OnChange(key, func(string) {
+			if cfg.TLSPort == 0 {
+				return
+			}
+			if err := redisServer.ReloadTLS(); err != nil {
+				fmt.Printf("Failed to reload TLS configuration: %v\n", err)
+			}
+		})
+	}
+	go shutdownOnSignal(redisServer)
+	go expireKeys(store, redisServer.Done())
 
 	fmt.Printf("Starting Redis server on port %d\n", cfg.Port)
 	err = redisServer.Start()
@@ -56,24 +170,108 @@ func main() {
 	}
 }
 
-// registerCommands registers all supported commands with the registry
-func registerCommands(registry command.Registry, store storage.Storage, cfg *config.Config) {
-	// Basic commands
-	registry.Register(&command.PingCommand{})
-	registry.Register(&command.EchoCommand{})
-	registry.Register(command.NewGetCommand(store))
-	registry.Register(command.NewSetCommand(store))
-	registry.Register(command.NewKeysCommand(store))
+// registerCommands registers all supported commands with the registry,
+// failing on the first one that can't be registered
+func registerCommands(registry command.Registry, store storage.Storage, cfg *config.Config, hub *pubsub.Hub,
+	acls *acl.ACL, serverStats *stats.Stats, commandStats *stats.CommandStats, slowLog *slowlog.Log,
+	saver *rdb.Saver) error {
+	handlers := []command.Handler{
+		// Basic commands
+		&command.PingCommand{},
+		command.NewHelloCommand(cfg.ReplicationConfig, acls),
+		command.NewAuthCommand(acls),
+		command.NewACLCommand(acls, registry, cfg.ACLFile),
+		command.NewCommandCommand(registry),
+		&command.EchoCommand{},
+		command.NewGetCommand(store),
+		command.NewSetCommand(store),
+		command.NewKeysCommand(store),
+		command.NewDelCommand(store),
+		command.NewIncrCommand(store),
+		command.NewDecrCommand(store),
+		command.NewIncrByCommand(store),
+		command.NewDecrByCommand(store),
+		command.NewTTLCommand(store),
+		command.NewPTTLCommand(store),
+
+		// Commands that need configuration
+		command.NewInfoCommand(
+			command.InfoSection{Name: "persistence", Info: saver.Info},
+			command.InfoSection{Name: "stats", Info: serverStats.Info},
+			command.InfoSection{Name: "commandstats", Info: commandStats.Info},
+			command.InfoSection{Name: "replication", Info: cfg.GetReplicationInfo},
+		),
+		command.NewConfigCommand(cfg),
+		command.NewSlowLogCommand(slowLog),
+
+		// Persistence commands
+		command.NewSaveCommand(saver),
+		command.NewBgSaveCommand(saver),
+		command.NewLastSaveCommand(saver),
+
+		// Replication-related commands
+		command.NewReplConfCommand(),
+		command.NewPSyncCommand(cfg.ReplicationConfig),
 
-	// Commands that need configuration
-	registry.Register(command.NewInfoCommand(cfg))
-	registry.Register(command.NewConfigCommand(cfg))
+		// Pub/sub commands, classic and sharded
+		command.NewSubscribeCommand(hub),
+		command.NewUnsubscribeCommand(hub),
+		command.NewPSubscribeCommand(hub),
+		command.NewPUnsubscribeCommand(hub),
+		command.NewPublishCommand(hub),
+		command.NewSSubscribeCommand(hub),
+		command.NewSUnsubscribeCommand(hub),
+		command.NewSPublishCommand(hub),
+		command.NewPubSubCommand(hub),
 
-	// Replication-related commands
-	registry.Register(command.NewReplConfCommand())
-	registry.Register(command.NewPSyncCommand(cfg.ReplicationConfig))
+		// TODO: Add more commands here
+	}
+
+	for _, handler := range handlers {
+		if err := registry.Register(handler); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-	// TODO: Add more commands here
+// shutdownOnSignal shuts the server down gracefully on SIGINT or SIGTERM.
+// If the shutdown fails, e.g. because the dataset could not be saved, the
+// server keeps running.
+func shutdownOnSignal(redisServer *server.Server) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM)
+
+	for sig := range signals {
+		fmt.Printf("Received %v, scheduling shutdown...\n", sig)
+		if err := redisServer.Shutdown(nil, command.ShutdownOptions{}); err != nil {
+			fmt.Printf("Errors trying to shut down the server: %v\n", err)
+		}
+	}
+}
+
+// Active expiry runs every activeExpireInterval for at most
+// activeExpireBudget, the share of its time Redis gives it by default
+const (
+	activeExpireInterval = 100 * time.Millisecond
+	activeExpireBudget   = 25 * time.Millisecond
+)
+
+// expireKeys periodically removes expired keys that are never accessed again,
+// so that their expired notifications are published close to their TTL. It
+// stops once done is closed.
+func expireKeys(store *memory.Store, done <-chan struct{}) {
+	ticker := time.NewTicker(activeExpireInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			store.ActiveExpire(activeExpireBudget)
+		}
+	}
 }
 
 func loadRDBData(store storage.Storage, filename string) error {
@@ -0,0 +1,307 @@
+package acl
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/auth"
+)
+
+// ErrNoUser is returned when checking permissions of a user that no longer
+// exists, e.g. after ACL DELUSER
+var ErrNoUser = errors.New("user does not exist")
+
+// Request describes a command invocation for permission checks
+type Request struct {
+	// Command and Subcommand are lowercase; Subcommand is only set for
+	// container commands such as CONFIG
+	Command    string
+	Subcommand string
+	Categories Category
+
+	Keys     []Key
+	Channels []string
+	// Patterns are channel patterns, as given to PSUBSCRIBE
+	Patterns []string
+}
+
+// Key is a key accessed by a command and how it is accessed
+type Key struct {
+	Name  string
+	Read  bool
+	Write bool
+}
+
+// Denied reports a permission check failure
+type Denied struct {
+	// Reason is "command", "key" or "channel"
+	Reason string
+	// Object is the command, key or channel that was denied
+	Object string
+}
+
+func (d *Denied) Error() string {
+	switch d.Reason {
+	case "key":
+		return "No permissions to access a key"
+	case "channel":
+		return "No permissions to access a channel"
+	default:
+		return fmt.Sprintf("No permissions to run the '%s' command", d.Object)
+	}
+}
+
+// ACL holds the users allowed to connect and what each of them may do
+type ACL struct {
+	// known reports whether a command exists, to validate rules
+	known func(name string) bool
+
+	mu    sync.RWMutex
+	users map[string]*User
+
+	log *Log
+}
+
+// Ensure ACL implements auth.Authenticator
+var _ auth.Authenticator = (*ACL)(nil)
+
+// New creates an ACL with only the default user, which may run every
+// command without a password. known reports whether a command name exists.
+func New(known func(name string) bool) *ACL {
+	return &ACL{
+		known: known,
+		users: map[string]*User{auth.DefaultUser: defaultUser()},
+		log:   NewLog(defaultLogLen),
+	}
+}
+
+// defaultUser creates the default user of a fresh server
+func defaultUser() *User {
+	return &User{
+		Name:     auth.DefaultUser,
+		Enabled:  true,
+		NoPass:   true,
+		Commands: []string{"+@all"},
+		Keys:     []KeyPattern{{Pattern: "*", Read: true, Write: true}},
+		Channels: []string{"*"},
+	}
+}
+
+// Log returns the log of denied commands and failed authentications
+func (a *ACL) Log() *Log {
+	return a.log
+}
+
+// User returns a user by name
+func (a *ACL) User(name string) (*User, bool) {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	u, ok := a.users[name]
+	return u, ok
+}
+
+// Users returns all users sorted by name
+func (a *ACL) Users() []*User {
+	a.mu.RLock()
+	defer a.mu.RUnlock()
+
+	users := make([]*User, 0, len(a.users))
+	for _, u := range a.users {
+		users = append(users, u)
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].Name < users[j].Name })
+	return users
+}
+
+// SetUser creates or modifies a user by applying rules in order. Either all
+// rules apply or, if one is invalid, none do.
+func (a *ACL) SetUser(name string, rules []string) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	u, ok := a.users[name]
+	if ok {
+		u = u.clone()
+	} else {
+		u = newUser(name)
+	}
+
+	for _, rule := range rules {
+		if err := u.apply(rule, a.known); err != nil {
+			return &RuleError{Rule: rule, Err: err}
+		}
+	}
+
+	a.users[name] = u
+	return nil
+}
+
+// RuleError reports an invalid ACL rule
+type RuleError struct {
+	Rule string
+	Err  error
+}
+
+func (e *RuleError) Error() string {
+	return fmt.Sprintf("Error in ACL SETUSER modifier '%s': %v", e.Rule, e.Err)
+}
+
+// DelUser deletes users, returning how many existed. The default user
+// cannot be deleted.
+func (a *ACL) DelUser(names ...string) (int, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for _, name := range names {
+		if name == auth.DefaultUser {
+			return 0, errors.New("The 'default' user cannot be removed")
+		}
+	}
+
+	deleted := 0
+	for _, name := range names {
+		if _, ok := a.users[name]; ok {
+			delete(a.users, name)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// SetDefaultPassword makes password the only password of the default user,
+// the way requirepass does. An empty password removes the need for one.
+func (a *ACL) SetDefaultPassword(password string) {
+	rules := []string{"nopass"}
+	if password != "" {
+		rules = []string{"resetpass", ">" + password}
+	}
+	a.SetUser(auth.DefaultUser, rules)
+}
+
+// Authenticate checks a password, logging failed attempts
+func (a *ACL) Authenticate(username, password string) error {
+	u, ok := a.User(username)
+	if !ok || !u.Enabled || !u.checkPassword(password) {
+		a.log.Add(Entry{Reason: "auth", Context: "toplevel", Object: "AUTH", Username: username})
+		return auth.ErrWrongPass
+	}
+	return nil
+}
+
+// Required reports whether new connections must authenticate, which is the
+// case unless the default user is enabled and has no password
+func (a *ACL) Required() bool {
+	u, ok := a.User(auth.DefaultUser)
+	return !ok || !u.Enabled || !u.NoPass
+}
+
+// Check verifies that username may run req. A *Denied error describes
+// which permission is missing.
+func (a *ACL) Check(username string, req Request) error {
+	u, ok := a.User(username)
+	if !ok {
+		return ErrNoUser
+	}
+
+	object := req.Command
+	if req.Subcommand != "" {
+		object += "|" + req.Subcommand
+	}
+	if !u.canRun(req) {
+		return &Denied{Reason: "command", Object: object}
+	}
+	for _, key := range req.Keys {
+		if !u.canAccessKey(key) {
+			return &Denied{Reason: "key", Object: key.Name}
+		}
+	}
+	for _, channel := range req.Channels {
+		if !u.canAccessChannel(channel) {
+			return &Denied{Reason: "channel", Object: channel}
+		}
+	}
+	for _, pat := range req.Patterns {
+		if !u.canUsePattern(pat) {
+			return &Denied{Reason: "channel", Object: pat}
+		}
+	}
+	return nil
+}
+
+// Save writes every user to an ACL file, replacing it atomically
+func (a *ACL) Save(path string) error {
+	var b strings.Builder
+	for _, u := range a.Users() {
+		b.WriteString(u.String())
+		b.WriteString("\n")
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "temp-acl-*.acl")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.WriteString(b.String()); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
+
+// Load replaces all users with those defined in an ACL file, one
+// "user <name> <rules...>" line each. If any line is invalid nothing is
+// changed. A default user is created if the file does not define one.
+func (a *ACL) Load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	users := make(map[string]*User)
+	scanner := bufio.NewScanner(f)
+	for lineno := 1; scanner.Scan(); lineno++ {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], "#") {
+			continue
+		}
+		if fields[0] != "user" || len(fields) < 2 {
+			return fmt.Errorf("%s:%d: should start with user keyword", path, lineno)
+		}
+
+		name := fields[1]
+		if _, ok := users[name]; ok {
+			return fmt.Errorf("%s:%d: duplicate user '%s' found", path, lineno, name)
+		}
+		u := newUser(name)
+		for _, rule := range fields[2:] {
+			if err := u.apply(rule, a.known); err != nil {
+				return fmt.Errorf("%s:%d: %v. Use ACL SETUSER to check the rule '%s'", path, lineno, err, rule)
+			}
+		}
+		users[name] = u
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if _, ok := users[auth.DefaultUser]; !ok {
+		users[auth.DefaultUser] = defaultUser()
+	}
+
+	a.mu.Lock()
+	a.users = users
+	a.mu.Unlock()
+	return nil
+}
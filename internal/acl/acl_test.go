@@ -0,0 +1,277 @@
+package acl
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/auth"
+)
+
+// knownCommands are the commands rules may name in tests
+var knownCommands = []string{"get", "set", "keys", "config", "publish", "subscribe"}
+
+func newTestACL() *ACL {
+	return New(func(name string) bool { return slices.Contains(knownCommands, name) })
+}
+
+func TestSetUser(t *testing.T) {
+	hash := hashPassword("pass")
+	tests := []struct {
+		name    string
+		rules   []string
+		want    string
+		wantErr bool
+	}{
+		{"new user", nil, "user alice off resetchannels -@all", false},
+		{"password", []string{"on", ">pass"}, "user alice on #" + hash + " resetchannels -@all", false},
+		{"hashed password", []string{"#" + hash}, "user alice off #" + hash + " resetchannels -@all", false},
+		{"removed password", []string{">pass", "<pass"}, "user alice off resetchannels -@all", false},
+		{"removed hash", []string{">pass", "!" + hash}, "user alice off resetchannels -@all", false},
+		{"nopass", []string{">pass", "nopass"}, "user alice off nopass resetchannels -@all", false},
+		{"password after nopass", []string{"nopass", ">pass"}, "user alice off #" + hash + " resetchannels -@all", false},
+		{"key patterns", []string{"~cache:*", "%R~ro:*", "%W~log:*", "%RW~rw:*"}, "user alice off ~cache:* %R~ro:* %W~log:* ~rw:* resetchannels -@all", false},
+		{"key permissions merge", []string{"%R~k", "%W~k"}, "user alice off ~k resetchannels -@all", false},
+		{"allkeys", []string{"~a", "allkeys"}, "user alice off ~* resetchannels -@all", false},
+		{"resetkeys", []string{"~a", "resetkeys"}, "user alice off resetchannels -@all", false},
+		{"channels", []string{"&news.*", "&news.*", "&alerts"}, "user alice off &news.* &alerts -@all", false},
+		{"allchannels", []string{"allchannels"}, "user alice off &* -@all", false},
+		{"commands", []string{"+@read", "-KEYS", "+config|get"}, "user alice off resetchannels -@all +@read -keys +config|get", false},
+		{"allcommands", []string{"+get", "allcommands", "-set"}, "user alice off resetchannels +@all -set", false},
+		{"-@all resets", []string{"+@all", "-@all", "+get"}, "user alice off resetchannels -@all +get", false},
+		{"reset", []string{"on", ">pass", "~*", "+@all", "reset"}, "user alice off resetchannels -@all", false},
+		{"sanitize-payload", []string{"sanitize-payload"}, "user alice off resetchannels -@all", false},
+		{"unknown command", []string{"+nosuch"}, "", true},
+		{"unknown category", []string{"+@nosuch"}, "", true},
+		{"empty subcommand", []string{"+config|"}, "", true},
+		{"bad key permission", []string{"%X~k"}, "", true},
+		{"no key permission", []string{"%~k"}, "", true},
+		{"short hash", []string{"#abc"}, "", true},
+		{"uppercase hash", []string{"#" + "ABCDEF" + hash[6:]}, "", true},
+		{"selector", []string{"(~k +get)"}, "", true},
+		{"unknown rule", []string{"bogus"}, "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestACL()
+			err := a.SetUser("alice", tt.rules)
+			if tt.wantErr {
+				var ruleErr *RuleError
+				if !errors.As(err, &ruleErr) {
+					t.Fatalf("SetUser() = %v, want a RuleError", err)
+				}
+				if _, ok := a.User("alice"); ok {
+					t.Error("user created despite the error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SetUser() = %v", err)
+			}
+			u, _ := a.User("alice")
+			if got := u.String(); got != tt.want {
+				t.Errorf("user\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+// An invalid rule leaves the user as it was
+func TestSetUserAtomic(t *testing.T) {
+	a := newTestACL()
+	if err := a.SetUser("alice", []string{"on", "+get"}); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := a.User("alice")
+	if err := a.SetUser("alice", []string{"off", "+set", "+nosuch"}); err == nil {
+		t.Fatal("SetUser() accepted an unknown command")
+	}
+	after, _ := a.User("alice")
+	if after.String() != before.String() {
+		t.Errorf("user changed to %q by a failed SetUser", after.String())
+	}
+}
+
+func TestCheck(t *testing.T) {
+	a := newTestACL()
+	rules := []string{"on", "nopass", "+@read", "-keys", "+set", "+config|get", "~cache:*", "%R~ro:*", "%W~wo:*", "&news.*"}
+	if err := a.SetUser("alice", rules); err != nil {
+		t.Fatal(err)
+	}
+
+	read := CategoryRead | CategoryString
+	tests := []struct {
+		name       string
+		req        Request
+		wantReason string
+	}{
+		{"category", Request{Command: "get", Categories: read, Keys: []Key{{Name: "cache:1", Read: true}}}, ""},
+		{"command excluded from category", Request{Command: "keys", Categories: CategoryRead | CategoryKeyspace}, "command"},
+		{"command outside categories", Request{Command: "del", Categories: CategoryWrite | CategoryKeyspace}, "command"},
+		{"command", Request{Command: "set", Categories: CategoryWrite, Keys: []Key{{Name: "cache:1", Write: true}}}, ""},
+		{"subcommand", Request{Command: "config", Subcommand: "get", Categories: CategoryAdmin}, ""},
+		{"other subcommand", Request{Command: "config", Subcommand: "set", Categories: CategoryAdmin}, "command"},
+		{"key outside patterns", Request{Command: "get", Categories: read, Keys: []Key{{Name: "other", Read: true}}}, "key"},
+		{"read-only key read", Request{Command: "get", Categories: read, Keys: []Key{{Name: "ro:1", Read: true}}}, ""},
+		{"read-only key written", Request{Command: "set", Categories: CategoryWrite, Keys: []Key{{Name: "ro:1", Write: true}}}, "key"},
+		{"write-only key written", Request{Command: "set", Categories: CategoryWrite, Keys: []Key{{Name: "wo:1", Write: true}}}, ""},
+		{"write-only key read", Request{Command: "get", Categories: read, Keys: []Key{{Name: "wo:1", Read: true}}}, "key"},
+		{"one key denied", Request{Command: "get", Categories: read, Keys: []Key{{Name: "cache:1", Read: true}, {Name: "x", Read: true}}}, "key"},
+		{"channel", Request{Command: "get", Categories: read, Channels: []string{"news.sport"}}, ""},
+		{"channel outside patterns", Request{Command: "get", Categories: read, Channels: []string{"alerts"}}, "channel"},
+		{"pattern given verbatim", Request{Command: "get", Categories: read, Patterns: []string{"news.*"}}, ""},
+		{"narrower pattern", Request{Command: "get", Categories: read, Patterns: []string{"news.sport.*"}}, "channel"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := a.Check("alice", tt.req)
+			if tt.wantReason == "" {
+				if err != nil {
+					t.Errorf("Check() = %v", err)
+				}
+				return
+			}
+			var denied *Denied
+			if !errors.As(err, &denied) || denied.Reason != tt.wantReason {
+				t.Errorf("Check() = %v, want %s denied", err, tt.wantReason)
+			}
+		})
+	}
+
+	if err := a.Check("nobody", Request{Command: "get"}); !errors.Is(err, ErrNoUser) {
+		t.Errorf("Check() for a missing user = %v, want ErrNoUser", err)
+	}
+}
+
+func TestDelUser(t *testing.T) {
+	a := newTestACL()
+	for _, name := range []string{"alice", "bob"} {
+		if err := a.SetUser(name, nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if _, err := a.DelUser("alice", auth.DefaultUser); err == nil {
+		t.Error("DelUser() removed the default user")
+	}
+	if _, ok := a.User("alice"); !ok {
+		t.Error("DelUser() removed users despite failing")
+	}
+	if n, err := a.DelUser("alice", "bob", "carol"); n != 2 || err != nil {
+		t.Errorf("DelUser() = %d, %v, want 2", n, err)
+	}
+	if users := a.Users(); len(users) != 1 || users[0].Name != auth.DefaultUser {
+		t.Errorf("users left: %v", users)
+	}
+}
+
+func TestSaveLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "users.acl")
+	a := newTestACL()
+	if err := a.SetUser("alice", []string{"on", ">pass", "~cache:*", "%R~ro:*", "&news.*", "+@read", "-keys"}); err != nil {
+		t.Fatal(err)
+	}
+	a.SetDefaultPassword("secret")
+	if err := a.Save(path); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	loaded := newTestACL()
+	if err := loaded.Load(path); err != nil {
+		t.Fatalf("Load() = %v", err)
+	}
+	got, want := loaded.Users(), a.Users()
+	if len(got) != len(want) {
+		t.Fatalf("loaded %d users, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].String() != want[i].String() {
+			t.Errorf("loaded\n%s\nwant\n%s", got[i], want[i])
+		}
+	}
+	if err := loaded.Authenticate("alice", "pass"); err != nil {
+		t.Errorf("Authenticate() after Load = %v", err)
+	}
+}
+
+func TestLoadErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		contents string
+		wantErr  bool
+	}{
+		{"comments and blank lines", "# users\n\nuser alice on nopass +get\n", false},
+		{"no default user", "user alice on\n", false},
+		{"missing keyword", "alice on\n", true},
+		{"missing name", "user\n", true},
+		{"duplicate user", "user alice on\nuser alice off\n", true},
+		{"invalid rule", "user alice on +nosuch\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := newTestACL()
+			if err := a.SetUser("existing", nil); err != nil {
+				t.Fatal(err)
+			}
+			path := filepath.Join(t.TempDir(), "users.acl")
+			if err := os.WriteFile(path, []byte(tt.contents), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			err := a.Load(path)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Load() = %v, want error %v", err, tt.wantErr)
+			}
+			_, existing := a.User("existing")
+			if existing != tt.wantErr {
+				t.Errorf("previous users kept = %v, want %v", existing, tt.wantErr)
+			}
+			if _, ok := a.User(auth.DefaultUser); !ok {
+				t.Error("no default user")
+			}
+		})
+	}
+}
+
+func TestDefaultPassword(t *testing.T) {
+	a := newTestACL()
+	if a.Required() {
+		t.Fatal("Required() by default")
+	}
+	if err := a.Authenticate(auth.DefaultUser, "anything"); err != nil {
+		t.Errorf("Authenticate() without a password = %v", err)
+	}
+
+	a.SetDefaultPassword("secret")
+	if !a.Required() {
+		t.Error("Required() = false with requirepass")
+	}
+	if err := a.Authenticate(auth.DefaultUser, "secret"); err != nil {
+		t.Errorf("Authenticate() = %v", err)
+	}
+	if err := a.Authenticate(auth.DefaultUser, "wrong"); !errors.Is(err, auth.ErrWrongPass) {
+		t.Errorf("Authenticate() with a wrong password = %v, want ErrWrongPass", err)
+	}
+	if err := a.Authenticate("nobody", "secret"); !errors.Is(err, auth.ErrWrongPass) {
+		t.Errorf("Authenticate() for a missing user = %v, want ErrWrongPass", err)
+	}
+	if entries := a.Log().Entries(10); len(entries) != 2 || entries[0].Reason != "auth" || entries[0].Username != "nobody" {
+		t.Errorf("ACL LOG %+v, want two auth entries, newest for nobody", entries)
+	}
+
+	// Changing requirepass replaces the previous password
+	a.SetDefaultPassword("other")
+	if err := a.Authenticate(auth.DefaultUser, "secret"); err == nil {
+		t.Error("old password still accepted")
+	}
+
+	a.SetDefaultPassword("")
+	if a.Required() {
+		t.Error("Required() after clearing requirepass")
+	}
+}
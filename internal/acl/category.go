@@ -0,0 +1,90 @@
+package acl
+
+import "strings"
+
+// Category is a set of ACL command categories, such as @read or @admin
+type Category uint32
+
+const (
+	CategoryKeyspace Category = 1 << iota
+	CategoryRead
+	CategoryWrite
+	CategorySet
+	CategorySortedSet
+	CategoryList
+	CategoryHash
+	CategoryString
+	CategoryBitmap
+	CategoryHyperLogLog
+	CategoryGeo
+	CategoryStream
+	CategoryPubSub
+	CategoryAdmin
+	CategoryFast
+	CategorySlow
+	CategoryBlocking
+	CategoryDangerous
+	CategoryConnection
+	CategoryTransaction
+	CategoryScripting
+)
+
+// categoryNames lists every category by name, in the order ACL CAT reports
+// them
+var categoryNames = []struct {
+	name     string
+	category Category
+}{
+	{"keyspace", CategoryKeyspace},
+	{"read", CategoryRead},
+	{"write", CategoryWrite},
+	{"set", CategorySet},
+	{"sortedset", CategorySortedSet},
+	{"list", CategoryList},
+	{"hash", CategoryHash},
+	{"string", CategoryString},
+	{"bitmap", CategoryBitmap},
+	{"hyperloglog", CategoryHyperLogLog},
+	{"geo", CategoryGeo},
+	{"stream", CategoryStream},
+	{"pubsub", CategoryPubSub},
+	{"admin", CategoryAdmin},
+	{"fast", CategoryFast},
+	{"slow", CategorySlow},
+	{"blocking", CategoryBlocking},
+	{"dangerous", CategoryDangerous},
+	{"connection", CategoryConnection},
+	{"transaction", CategoryTransaction},
+	{"scripting", CategoryScripting},
+}
+
+// CategoryNames returns the names of all categories
+func CategoryNames() []string {
+	names := make([]string, len(categoryNames))
+	for i, entry := range categoryNames {
+		names[i] = entry.name
+	}
+	return names
+}
+
+// ParseCategory looks up a category by name, without the leading '@'
+func ParseCategory(name string) (Category, bool) {
+	name = strings.ToLower(name)
+	for _, entry := range categoryNames {
+		if entry.name == name {
+			return entry.category, true
+		}
+	}
+	return 0, false
+}
+
+// Names returns the names of the categories in the set
+func (c Category) Names() []string {
+	var names []string
+	for _, entry := range categoryNames {
+		if c&entry.category != 0 {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
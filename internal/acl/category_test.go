@@ -0,0 +1,33 @@
+package acl
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestCategories(t *testing.T) {
+	names := CategoryNames()
+	if len(names) != 21 {
+		t.Errorf("%d categories, want 21", len(names))
+	}
+	for _, name := range names {
+		c, ok := ParseCategory(name)
+		if !ok {
+			t.Errorf("ParseCategory(%q) failed", name)
+			continue
+		}
+		if got := c.Names(); !slices.Equal(got, []string{name}) {
+			t.Errorf("%s.Names() = %v", name, got)
+		}
+	}
+
+	if c, ok := ParseCategory("READ"); !ok || c != CategoryRead {
+		t.Errorf("ParseCategory is case-sensitive")
+	}
+	if _, ok := ParseCategory("all"); ok {
+		t.Errorf("ParseCategory(all) succeeded")
+	}
+	if got := (CategoryWrite | CategoryKeyspace | CategoryFast).Names(); !slices.Equal(got, []string{"keyspace", "write", "fast"}) {
+		t.Errorf("Names() = %v, want them in ACL CAT order", got)
+	}
+}
@@ -0,0 +1,95 @@
+package acl
+
+import (
+	"sync"
+	"time"
+)
+
+// defaultLogLen is the default number of entries kept in the ACL log
+const defaultLogLen = 128
+
+// Entry is an ACL LOG entry. Repeated failures with the same reason,
+// context, object and username are folded into one entry.
+type Entry struct {
+	ID         int64
+	Count      int
+	Reason     string
+	Context    string
+	Object     string
+	Username   string
+	ClientInfo string
+	Created    time.Time
+	Updated    time.Time
+}
+
+// Log records denied commands and failed authentications, newest first
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+	maxLen  int
+	nextID  int64
+}
+
+// NewLog creates a log keeping at most maxLen entries
+func NewLog(maxLen int) *Log {
+	return &Log{maxLen: maxLen}
+}
+
+// SetMaxLen changes how many entries are kept, dropping the oldest ones
+func (l *Log) SetMaxLen(maxLen int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxLen = maxLen
+	l.trim()
+}
+
+// Add records a failure, folding it into a recent identical entry
+func (l *Log) Add(e Entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	for i, existing := range l.entries {
+		if existing.Reason == e.Reason && existing.Context == e.Context &&
+			existing.Object == e.Object && existing.Username == e.Username {
+			existing.Count++
+			existing.Updated = now
+			existing.ClientInfo = e.ClientInfo
+			copy(l.entries[1:i+1], l.entries[:i])
+			l.entries[0] = existing
+			return
+		}
+	}
+
+	e.ID = l.nextID
+	l.nextID++
+	e.Count = 1
+	e.Created, e.Updated = now, now
+	l.entries = append([]Entry{e}, l.entries...)
+	l.trim()
+}
+
+// Entries returns up to count of the newest entries
+func (l *Log) Entries(count int) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	count = min(count, len(l.entries))
+	return append([]Entry(nil), l.entries[:count]...)
+}
+
+// Reset removes every entry
+func (l *Log) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = nil
+}
+
+// trim drops entries beyond maxLen. Callers must hold l.mu.
+func (l *Log) trim() {
+	if len(l.entries) > l.maxLen {
+		l.entries = l.entries[:l.maxLen]
+	}
+}
@@ -0,0 +1,39 @@
+package acl
+
+import "testing"
+
+func TestLog(t *testing.T) {
+	l := NewLog(3)
+	for _, e := range []Entry{
+		{Reason: "command", Object: "keys", Username: "alice"},
+		{Reason: "key", Object: "secret", Username: "alice"},
+		{Reason: "command", Object: "keys", Username: "alice", ClientInfo: "id=2"},
+		{Reason: "command", Object: "keys", Username: "bob"},
+	} {
+		l.Add(e)
+	}
+
+	entries := l.Entries(10)
+	if len(entries) != 3 {
+		t.Fatalf("%d entries, want 3", len(entries))
+	}
+	// Repeated failures are folded into one entry, moved to the front
+	if e := entries[1]; e.Username != "alice" || e.Object != "keys" || e.Count != 2 || e.ClientInfo != "id=2" || e.ID != 0 {
+		t.Errorf("folded entry %+v", e)
+	}
+	if e := entries[0]; e.Username != "bob" || e.Count != 1 || e.ID != 2 {
+		t.Errorf("newest entry %+v", e)
+	}
+	if got := l.Entries(1); len(got) != 1 || got[0].Username != "bob" {
+		t.Errorf("Entries(1) = %+v", got)
+	}
+
+	l.SetMaxLen(1)
+	if got := l.Entries(10); len(got) != 1 || got[0].Username != "bob" {
+		t.Errorf("entries after SetMaxLen(1) = %+v", got)
+	}
+	l.Reset()
+	if got := l.Entries(10); len(got) != 0 {
+		t.Errorf("entries after Reset = %+v", got)
+	}
+}
@@ -0,0 +1,334 @@
+package acl
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/pattern"
+)
+
+// KeyPattern grants read and/or write access to keys matching Pattern
+type KeyPattern struct {
+	Pattern string
+	Read    bool
+	Write   bool
+}
+
+// String formats the pattern as an ACL rule: ~pattern for full access,
+// %R~pattern or %W~pattern otherwise
+func (k KeyPattern) String() string {
+	switch {
+	case k.Read && k.Write:
+		return "~" + k.Pattern
+	case k.Read:
+		return "%R~" + k.Pattern
+	default:
+		return "%W~" + k.Pattern
+	}
+}
+
+// User is an ACL user. Users are immutable once stored in an ACL; changes
+// are applied to a copy that replaces the original.
+type User struct {
+	Name    string
+	Enabled bool
+	NoPass  bool
+
+	// Passwords holds the hex-encoded SHA-256 of each valid password
+	Passwords []string
+
+	// Commands holds the command rules in the order they were given, e.g.
+	// "+@read", "-keys" or "+config|get". Later rules override earlier ones.
+	Commands []string
+
+	Keys     []KeyPattern
+	Channels []string
+}
+
+// newUser creates a user the way ACL SETUSER does: disabled, without
+// passwords and with no permissions at all
+func newUser(name string) *User {
+	return &User{Name: name}
+}
+
+// clone returns a deep copy of the user
+func (u *User) clone() *User {
+	c := *u
+	c.Passwords = slices.Clone(u.Passwords)
+	c.Commands = slices.Clone(u.Commands)
+	c.Keys = slices.Clone(u.Keys)
+	c.Channels = slices.Clone(u.Channels)
+	return &c
+}
+
+// hashPassword returns the hex-encoded SHA-256 of a password
+func hashPassword(password string) string {
+	sum := sha256.Sum256([]byte(password))
+	return hex.EncodeToString(sum[:])
+}
+
+// apply applies a single ACL rule. known reports whether a command exists.
+func (u *User) apply(rule string, known func(name string) bool) error {
+	lower := strings.ToLower(rule)
+	switch {
+	case lower == "on":
+		u.Enabled = true
+	case lower == "off":
+		u.Enabled = false
+	case lower == "nopass":
+		u.NoPass = true
+		u.Passwords = nil
+	case lower == "resetpass":
+		u.NoPass = false
+		u.Passwords = nil
+	case lower == "allkeys":
+		u.Keys = []KeyPattern{{Pattern: "*", Read: true, Write: true}}
+	case lower == "resetkeys":
+		u.Keys = nil
+	case lower == "allchannels":
+		u.Channels = []string{"*"}
+	case lower == "resetchannels":
+		u.Channels = nil
+	case lower == "allcommands":
+		u.Commands = []string{"+@all"}
+	case lower == "nocommands":
+		u.Commands = nil
+	case lower == "reset":
+		*u = *newUser(u.Name)
+	case lower == "sanitize-payload" || lower == "skip-sanitize-payload":
+		// Payload sanitization only applies to RESTORE, which is not supported
+	case strings.HasPrefix(rule, ">"):
+		u.addPassword(hashPassword(rule[1:]))
+	case strings.HasPrefix(rule, "<"):
+		u.removePassword(hashPassword(rule[1:]))
+	case strings.HasPrefix(rule, "#") || strings.HasPrefix(rule, "!"):
+		hash := rule[1:]
+		if !validHash(hash) {
+			return fmt.Errorf("The password hash must be exactly 64 characters and contain only lowercase hexadecimal characters")
+		}
+		if rule[0] == '#' {
+			u.addPassword(hash)
+		} else {
+			u.removePassword(hash)
+		}
+	case strings.HasPrefix(rule, "~"):
+		u.addKeyPattern(KeyPattern{Pattern: rule[1:], Read: true, Write: true})
+	case strings.HasPrefix(rule, "%"):
+		perms, pat, ok := strings.Cut(rule[1:], "~")
+		if !ok || perms == "" {
+			return fmt.Errorf("Syntax error")
+		}
+		key := KeyPattern{Pattern: pat}
+		for _, p := range strings.ToUpper(perms) {
+			switch p {
+			case 'R':
+				key.Read = true
+			case 'W':
+				key.Write = true
+			default:
+				return fmt.Errorf("Syntax error")
+			}
+		}
+		u.addKeyPattern(key)
+	case strings.HasPrefix(rule, "&"):
+		if !slices.Contains(u.Channels, rule[1:]) {
+			u.Channels = append(u.Channels, rule[1:])
+		}
+	case strings.HasPrefix(rule, "+") || strings.HasPrefix(rule, "-"):
+		return u.addCommandRule(lower, known)
+	case strings.HasPrefix(rule, "("):
+		return fmt.Errorf("Selectors are not supported")
+	default:
+		return fmt.Errorf("Syntax error")
+	}
+
+	return nil
+}
+
+func (u *User) addPassword(hash string) {
+	u.NoPass = false
+	if !slices.Contains(u.Passwords, hash) {
+		u.Passwords = append(u.Passwords, hash)
+	}
+}
+
+func (u *User) removePassword(hash string) {
+	u.Passwords = slices.DeleteFunc(u.Passwords, func(h string) bool { return h == hash })
+}
+
+func (u *User) addKeyPattern(key KeyPattern) {
+	for i, existing := range u.Keys {
+		if existing.Pattern == key.Pattern {
+			u.Keys[i].Read = existing.Read || key.Read
+			u.Keys[i].Write = existing.Write || key.Write
+			return
+		}
+	}
+	u.Keys = append(u.Keys, key)
+}
+
+// addCommandRule validates and records a +/- command or category rule.
+// +@all and -@all override everything before them, so they reset the list.
+func (u *User) addCommandRule(rule string, known func(name string) bool) error {
+	target := rule[1:]
+	switch {
+	case target == "@all":
+		u.Commands = nil
+		if rule[0] == '+' {
+			u.Commands = []string{"+@all"}
+		}
+		return nil
+	case strings.HasPrefix(target, "@"):
+		if _, ok := ParseCategory(target[1:]); !ok {
+			return fmt.Errorf("Unknown command or category name in ACL")
+		}
+	default:
+		name, sub, isSub := strings.Cut(target, "|")
+		if !known(name) || (isSub && sub == "") {
+			return fmt.Errorf("Unknown command or category name in ACL")
+		}
+	}
+
+	u.Commands = append(u.Commands, rule)
+	return nil
+}
+
+// validHash reports whether s looks like a hex-encoded SHA-256
+func validHash(s string) bool {
+	if len(s) != 64 {
+		return false
+	}
+	for i := 0; i < len(s); i++ {
+		if !(s[i] >= '0' && s[i] <= '9' || s[i] >= 'a' && s[i] <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// checkPassword reports whether password is valid for the user. Every stored
+// digest is compared in constant time, so the time taken doesn't reveal
+// which one, if any, matched.
+func (u *User) checkPassword(password string) bool {
+	if u.NoPass {
+		return true
+	}
+
+	hash := []byte(hashPassword(password))
+	match := 0
+	for _, stored := range u.Passwords {
+		match |= subtle.ConstantTimeCompare([]byte(stored), hash)
+	}
+	return match == 1
+}
+
+// canRun reports whether the command rules allow the request's command
+func (u *User) canRun(req Request) bool {
+	allowed := false
+	for _, rule := range u.Commands {
+		target := rule[1:]
+		var matches bool
+		switch {
+		case target == "@all":
+			matches = true
+		case strings.HasPrefix(target, "@"):
+			category, _ := ParseCategory(target[1:])
+			matches = req.Categories&category != 0
+		default:
+			name, sub, isSub := strings.Cut(target, "|")
+			matches = name == req.Command && (!isSub || sub == req.Subcommand)
+		}
+		if matches {
+			allowed = rule[0] == '+'
+		}
+	}
+	return allowed
+}
+
+// canAccessKey reports whether some key pattern grants the access key needs
+func (u *User) canAccessKey(key Key) bool {
+	for _, k := range u.Keys {
+		if (key.Read && !k.Read) || (key.Write && !k.Write) {
+			continue
+		}
+		if pattern.Match(k.Pattern, key.Name) {
+			return true
+		}
+	}
+	return false
+}
+
+// canAccessChannel reports whether a channel matches a channel pattern
+func (u *User) canAccessChannel(channel string) bool {
+	for _, p := range u.Channels {
+		if pattern.Match(p, channel) {
+			return true
+		}
+	}
+	return false
+}
+
+// canUsePattern reports whether the user may subscribe to a channel
+// pattern, which must be one of its channel patterns verbatim
+func (u *User) canUsePattern(pat string) bool {
+	return slices.Contains(u.Channels, "*") || slices.Contains(u.Channels, pat)
+}
+
+// CommandRules describes the command permissions as ACL rules
+func (u *User) CommandRules() string {
+	rules := u.Commands
+	if len(rules) == 0 || rules[0] != "+@all" {
+		rules = append([]string{"-@all"}, rules...)
+	}
+	return strings.Join(rules, " ")
+}
+
+// KeyRules describes the key permissions as ACL rules
+func (u *User) KeyRules() string {
+	rules := make([]string, len(u.Keys))
+	for i, k := range u.Keys {
+		rules[i] = k.String()
+	}
+	return strings.Join(rules, " ")
+}
+
+// ChannelRules describes the channel permissions as ACL rules
+func (u *User) ChannelRules() string {
+	rules := make([]string, len(u.Channels))
+	for i, c := range u.Channels {
+		rules[i] = "&" + c
+	}
+	return strings.Join(rules, " ")
+}
+
+// String describes the user as an ACL LIST line, which can be loaded back
+// from an ACL file
+func (u *User) String() string {
+	parts := []string{"user", u.Name}
+	if u.Enabled {
+		parts = append(parts, "on")
+	} else {
+		parts = append(parts, "off")
+	}
+	if u.NoPass {
+		parts = append(parts, "nopass")
+	}
+	for _, hash := range u.Passwords {
+		parts = append(parts, "#"+hash)
+	}
+	if keys := u.KeyRules(); keys != "" {
+		parts = append(parts, keys)
+	}
+	if channels := u.ChannelRules(); channels != "" {
+		parts = append(parts, channels)
+	} else {
+		parts = append(parts, "resetchannels")
+	}
+	parts = append(parts, u.CommandRules())
+
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,38 @@
+package acl
+
+import "testing"
+
+func TestCheckPassword(t *testing.T) {
+	tests := []struct {
+		name     string
+		rules    []string
+		password string
+		want     bool
+	}{
+		{"no passwords", nil, "secret", false},
+		{"nopass", []string{"nopass"}, "anything", true},
+		{"match", []string{">secret"}, "secret", true},
+		{"mismatch", []string{">secret"}, "Secret", false},
+		{"empty password", []string{">secret"}, "", false},
+		{"first of several", []string{">one", ">two", ">three"}, "one", true},
+		{"last of several", []string{">one", ">two", ">three"}, "three", true},
+		{"none of several", []string{">one", ">two", ">three"}, "four", false},
+		{"hash", []string{"#" + hashPassword("secret")}, "secret", true},
+		{"removed", []string{">one", ">two", "<one"}, "one", false},
+		{"reset", []string{">secret", "resetpass"}, "secret", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := newUser("test")
+			for _, rule := range tt.rules {
+				if err := u.apply(rule, nil); err != nil {
+					t.Fatalf("apply(%q) = %v", rule, err)
+				}
+			}
+			if got := u.checkPassword(tt.password); got != tt.want {
+				t.Errorf("checkPassword(%q) = %v, want %v", tt.password, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package auth
+
+import "errors"
+
+// DefaultUser is the user every connection is authenticated as initially
+const DefaultUser = "default"
+
+// ErrWrongPass is returned for invalid credentials. Its text is sent to the
+// client verbatim, so it carries the Redis error code.
+var ErrWrongPass = errors.New("WRONGPASS invalid username-password pair or user is disabled.")
+
+// Authenticator verifies credentials presented with AUTH or HELLO
+type Authenticator interface {
+	// Authenticate returns nil if password is valid for username
+	Authenticate(username, password string) error
+
+	// Required reports whether new connections must authenticate before
+	// running commands
+	Required() bool
+}
+
+// NoPass accepts any password for the default user, which is how a server
+// without a configured password behaves
+type NoPass struct{}
+
+// Ensure NoPass implements Authenticator
+var _ Authenticator = NoPass{}
+
+// Authenticate accepts the default user with any password
+func (NoPass) Authenticate(username, password string) error {
+	if username != DefaultUser {
+		return ErrWrongPass
+	}
+	return nil
+}
+
+// Required is always false
+func (NoPass) Required() bool {
+	return false
+}
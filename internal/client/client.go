@@ -0,0 +1,429 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/auth"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// ErrClosed is returned when writing to a client whose connection has been
+// closed, possibly for exceeding its output buffer limits
+var ErrClosed = errors.New("client connection closed")
+
+// lastID is the most recently assigned client ID
+var lastID atomic.Int64
+
+// Flag marks a client as having a special role
+type Flag uint8
+
+const (
+	// FlagReplica marks a replica receiving the replication stream
+	FlagReplica Flag = 1 << iota
+	// FlagPubSub marks a client subscribed to at least one channel or pattern
+	FlagPubSub
+	// FlagBlocked marks a client waiting in a blocking command
+	FlagBlocked
+)
+
+// Client represents a connected client and its per-connection state
+type Client struct {
+	id     int64
+	conn   net.Conn
+	limits *OutputLimits
+
+	// mu guards the fields below, since pub/sub messages are queued for the
+	// client from other clients' goroutines
+	mu       sync.Mutex
+	protocol resp.Protocol
+	name     string
+	user     string
+	authed   bool
+	flags    Flag
+
+	// lastInteraction is when the client last sent a command
+	lastInteraction time.Time
+
+	// Output not yet handed to the writer goroutine, and the size of the
+	// batch it is currently writing. drained is signaled whenever a batch
+	// has been written.
+	out      []byte
+	inFlight int
+	drained  *sync.Cond
+	wake     chan struct{}
+
+	// softSince is when the output first exceeded the soft limit
+	softSince     time.Time
+	limitExceeded bool
+	closed        bool
+	err           error
+	closeOnce     sync.Once
+}
+
+// New creates a client for an accepted connection and starts writing its
+// output in the background. Clients start out speaking RESP2 as the default
+// user. Output is bounded by limits, which may be nil for no limits.
+func New(conn net.Conn, limits *OutputLimits) *Client {
+	c := &Client{
+		id:       lastID.Add(1),
+		conn:     conn,
+		limits:   limits,
+		protocol: resp.RESP2,
+		user:     auth.DefaultUser,
+		wake:     make(chan struct{}, 1),
+
+		lastInteraction: time.Now(),
+	}
+	c.drained = sync.NewCond(&c.mu)
+
+	go c.writeLoop()
+	return c
+}
+
+// ID returns the unique, monotonically increasing client ID
+func (c *Client) ID() int64 {
+	return c.id
+}
+
+// Conn returns the client's connection, which the server reads commands
+// from. Output must go through the client instead.
+func (c *Client) Conn() net.Conn {
+	return c.conn
+}
+
+// RemoteAddr returns the address of the connected peer. Like Redis,
+// clients connected over a Unix socket are reported as "<path>:0".
+func (c *Client) RemoteAddr() string {
+	if addr, ok := c.conn.LocalAddr().(*net.UnixAddr); ok {
+		return addr.Name + ":0"
+	}
+	return c.conn.RemoteAddr().String()
+}
+
+// Info describes the client for logs, e.g. in ACL LOG entries
+func (c *Client) Info() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return fmt.Sprintf("id=%d addr=%s name=%s user=%s resp=%d", c.id, c.RemoteAddr(), c.name, c.user, c.protocol)
+}
+
+// Protocol returns the RESP version negotiated by the client
+func (c *Client) Protocol() resp.Protocol {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.protocol
+}
+
+// SetProtocol switches the RESP version used for replies to the client
+func (c *Client) SetProtocol(proto resp.Protocol) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.protocol = proto
+}
+
+// Name returns the name set with HELLO SETNAME
+func (c *Client) Name() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.name
+}
+
+// SetName sets the client name
+func (c *Client) SetName(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.name = name
+}
+
+// User returns the name of the user the client is authenticated as
+func (c *Client) User() string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.user
+}
+
+// SetUser records the user the client authenticated as
+func (c *Client) SetUser(user string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.user = user
+}
+
+// Authenticated reports whether the client may run commands
+func (c *Client) Authenticated() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.authed
+}
+
+// SetAuthenticated records whether the client has authenticated
+func (c *Client) SetAuthenticated(authed bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.authed = authed
+}
+
+// HasFlag reports whether the client has any of the given flags set
+func (c *Client) HasFlag(flag Flag) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.flags&flag != 0
+}
+
+// SetFlag sets or clears flag
+func (c *Client) SetFlag(flag Flag, on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if on {
+		c.flags |= flag
+	} else {
+		c.flags &^= flag
+	}
+}
+
+// Touch records that the client just sent a command
+func (c *Client) Touch() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lastInteraction = time.Now()
+}
+
+// Idle returns how long ago the client last sent a command
+func (c *Client) Idle() time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return time.Since(c.lastInteraction)
+}
+
+// Class returns the class whose output buffer limits apply to the client
+func (c *Client) Class() Class {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.class()
+}
+
+func (c *Client) class() Class {
+	switch {
+	case c.flags&FlagReplica != 0:
+		return ClassReplica
+	case c.flags&FlagPubSub != 0:
+		return ClassPubSub
+	default:
+		return ClassNormal
+	}
+}
+
+// OutputLimitExceeded reports whether the client was disconnected for
+// exceeding its output buffer limits
+func (c *Client) OutputLimitExceeded() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.limitExceeded
+}
+
+// Pending returns the number of output bytes not yet written to the
+// connection
+func (c *Client) Pending() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return len(c.out) + c.inFlight
+}
+
+// CloseGracefully sends pending output, giving up after timeout, and then
+// closes the connection
+func (c *Client) CloseGracefully(timeout time.Duration) error {
+	c.conn.SetWriteDeadline(time.Now().Add(timeout))
+	c.Flush()
+	return c.Close()
+}
+
+// maxRetainedOutput is the largest output buffer kept for reuse after a
+// write; larger buffers are released so one big reply doesn't pin memory
+const maxRetainedOutput = 64 * 1024
+
+// Write appends a reply, encoded in the client's negotiated protocol, to the
+// output buffer. Nothing is sent until Flush is called, so replies to a
+// pipeline of commands go out in a single write.
+func (c *Client) Write(value resp.RedisValue) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.append(value)
+}
+
+// Flush sends the buffered replies to the client, waiting until they have
+// been written
+func (c *Client) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.signal()
+	for (len(c.out) > 0 || c.inFlight > 0) && !c.closed && c.err == nil {
+		c.drained.Wait()
+	}
+
+	if c.err != nil {
+		return c.err
+	}
+	if c.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// Push delivers an out-of-band message such as a pub/sub message. It is
+// queued together with any buffered replies and sent in the background,
+// so a slow client never blocks the publisher; instead its output grows
+// until it hits the limits of its class.
+func (c *Client) Push(value resp.RedisValue) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if err := c.append(value); err != nil {
+		return err
+	}
+	c.signal()
+	return nil
+}
+
+// append serializes value into the output buffer and enforces the output
+// limits. Callers must hold c.mu.
+func (c *Client) append(value resp.RedisValue) error {
+	if c.closed {
+		return ErrClosed
+	}
+
+	c.out = append(c.out, value.Serialize(c.protocol)...)
+	c.checkLimits()
+	if c.closed {
+		return ErrClosed
+	}
+	return nil
+}
+
+// CheckOutputLimits disconnects the client if its output has stayed over
+// the soft limit for longer than allowed. Limits are otherwise only checked
+// as output is queued or written, which a client whose connection stalled
+// with no new output would escape, so the server calls this periodically.
+func (c *Client) CheckOutputLimits() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.checkLimits()
+	}
+}
+
+// checkLimits disconnects the client if its pending output is over the hard
+// limit of its class, or has been over the soft limit for longer than the
+// soft limit allows. Callers must hold c.mu.
+func (c *Client) checkLimits() {
+	limit := c.limits.Get(c.class())
+	size := int64(len(c.out) + c.inFlight)
+
+	exceeded := limit.Hard > 0 && size >= limit.Hard
+	if limit.Soft > 0 && size >= limit.Soft {
+		if c.softSince.IsZero() {
+			c.softSince = time.Now()
+		} else if time.Since(c.softSince) > limit.SoftSeconds {
+			exceeded = true
+		}
+	} else {
+		c.softSince = time.Time{}
+	}
+
+	if !exceeded {
+		return
+	}
+
+	fmt.Printf("Client id=%d addr=%s scheduled to be closed ASAP for overcoming of output buffer limits.\n", c.id, c.RemoteAddr())
+	c.limitExceeded = true
+	c.closeLocked()
+}
+
+// signal wakes up the writer goroutine. Callers must hold c.mu.
+func (c *Client) signal() {
+	if len(c.out) == 0 || c.closed {
+		return
+	}
+
+	select {
+	case c.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writeLoop writes queued output to the connection until the client is
+// closed, taking whatever has accumulated since the previous write as the
+// next batch
+func (c *Client) writeLoop() {
+	for range c.wake {
+		c.mu.Lock()
+		for len(c.out) > 0 && !c.closed {
+			batch := c.out
+			c.out = nil
+			c.inFlight = len(batch)
+			c.mu.Unlock()
+
+			_, err := c.conn.Write(batch)
+
+			c.mu.Lock()
+			c.inFlight = 0
+			if c.out == nil && cap(batch) <= maxRetainedOutput {
+				c.out = batch[:0]
+			}
+			if err != nil && c.err == nil {
+				c.err = err
+			}
+			if c.err != nil {
+				c.closeLocked()
+			}
+			c.checkLimits()
+			c.drained.Broadcast()
+		}
+		c.mu.Unlock()
+	}
+}
+
+// Close closes the underlying connection, discarding unsent output
+func (c *Client) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.closeLocked()
+}
+
+// closeLocked closes the connection and stops the writer. Callers must
+// hold c.mu.
+func (c *Client) closeLocked() error {
+	var err error
+	c.closeOnce.Do(func() {
+		c.closed = true
+		c.out = nil
+		close(c.wake)
+		c.drained.Broadcast()
+		err = c.conn.Close()
+	})
+	return err
+}
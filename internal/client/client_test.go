@@ -0,0 +1,167 @@
+package client
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// reply serializes to 100 bytes
+var reply = resp.BulkString{Value: make([]byte, 93)}
+
+// newBlockedClient returns a client whose peer never reads, so all its
+// output stays pending
+func newBlockedClient(t *testing.T, limits *OutputLimits) *Client {
+	t.Helper()
+	local, remote := net.Pipe()
+	c := New(remote, limits)
+	t.Cleanup(func() {
+		c.Close()
+		local.Close()
+	})
+	return c
+}
+
+func TestClass(t *testing.T) {
+	tests := []struct {
+		flags Flag
+		want  Class
+	}{
+		{0, ClassNormal},
+		{FlagBlocked, ClassNormal},
+		{FlagPubSub, ClassPubSub},
+		{FlagReplica, ClassReplica},
+		{FlagReplica | FlagPubSub, ClassReplica},
+	}
+
+	for _, tt := range tests {
+		c := newBlockedClient(t, nil)
+		c.SetFlag(tt.flags, true)
+		if got := c.Class(); got != tt.want {
+			t.Errorf("Class() with flags %b = %s, want %s", tt.flags, got, tt.want)
+		}
+	}
+}
+
+func TestOutputLimits(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags Flag
+		class Class
+		limit OutputLimit
+
+		// pushes replies are pushed, then after wait, if set, one more
+		pushes     int
+		wait       time.Duration
+		wantClosed bool
+	}{
+		{"normal clients are unlimited", 0, ClassPubSub, OutputLimit{Hard: 100}, 10, 0, false},
+		{"below the hard limit", FlagPubSub, ClassPubSub, OutputLimit{Hard: 301}, 3, 0, false},
+		{"hard limit", FlagPubSub, ClassPubSub, OutputLimit{Hard: 300}, 3, 0, true},
+		{"replica hard limit", FlagReplica, ClassReplica, OutputLimit{Hard: 300}, 3, 0, true},
+		{"soft limit briefly", FlagPubSub, ClassPubSub, OutputLimit{Soft: 100, SoftSeconds: time.Hour}, 5, 0, false},
+		{"soft limit for too long", FlagPubSub, ClassPubSub, OutputLimit{Soft: 100, SoftSeconds: 10 * time.Millisecond}, 2, 20 * time.Millisecond, true},
+		{"below the soft limit", FlagPubSub, ClassPubSub, OutputLimit{Soft: 1000, SoftSeconds: time.Millisecond}, 2, 5 * time.Millisecond, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limits := NewOutputLimits()
+			classes := DefaultOutputLimits
+			classes[tt.class] = tt.limit
+			limits.Set(classes)
+
+			c := newBlockedClient(t, limits)
+			c.SetFlag(tt.flags, true)
+
+			var err error
+			for range tt.pushes {
+				if err = c.Push(reply); err != nil {
+					break
+				}
+			}
+			if tt.wait > 0 && err == nil {
+				time.Sleep(tt.wait)
+				err = c.Push(reply)
+			}
+
+			if c.OutputLimitExceeded() != tt.wantClosed {
+				t.Errorf("OutputLimitExceeded() = %v, want %v", c.OutputLimitExceeded(), tt.wantClosed)
+			}
+			if tt.wantClosed {
+				if !errors.Is(err, ErrClosed) {
+					t.Errorf("Push() = %v, want ErrClosed", err)
+				}
+				if err := c.Write(reply); !errors.Is(err, ErrClosed) {
+					t.Errorf("Write() after disconnection = %v, want ErrClosed", err)
+				}
+			} else if err != nil {
+				t.Errorf("Push() = %v", err)
+			}
+		})
+	}
+}
+
+// A client whose writer is stalled with no new output is still disconnected
+// once it has been over the soft limit for too long
+func TestCheckOutputLimits(t *testing.T) {
+	limits := NewOutputLimits()
+	classes := DefaultOutputLimits
+	classes[ClassPubSub] = OutputLimit{Soft: 100, SoftSeconds: 10 * time.Millisecond}
+	limits.Set(classes)
+
+	c := newBlockedClient(t, limits)
+	c.SetFlag(FlagPubSub, true)
+	for range 2 {
+		if err := c.Push(reply); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	c.CheckOutputLimits()
+	if c.OutputLimitExceeded() {
+		t.Fatal("disconnected before the soft limit's time was up")
+	}
+	time.Sleep(20 * time.Millisecond)
+	c.CheckOutputLimits()
+	if !c.OutputLimitExceeded() {
+		t.Error("not disconnected after staying over the soft limit")
+	}
+	if err := c.Push(reply); !errors.Is(err, ErrClosed) {
+		t.Errorf("Push() after disconnection = %v, want ErrClosed", err)
+	}
+}
+
+// Limits apply to output written so far but not yet sent
+func TestPendingOutput(t *testing.T) {
+	local, remote := net.Pipe()
+	defer local.Close()
+	c := New(remote, nil)
+	defer c.Close()
+
+	c.Write(reply)
+	c.Write(reply)
+	if got := c.Pending(); got != 200 {
+		t.Errorf("Pending() = %d before flushing, want 200", got)
+	}
+
+	go func() {
+		buf := make([]byte, 200)
+		for n := 0; n < len(buf); {
+			m, err := local.Read(buf[n:])
+			if err != nil {
+				return
+			}
+			n += m
+		}
+	}()
+	if err := c.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if got := c.Pending(); got != 0 {
+		t.Errorf("Pending() = %d after flushing, want 0", got)
+	}
+}
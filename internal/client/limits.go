@@ -0,0 +1,90 @@
+package client
+
+import (
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// Class groups clients that share output buffer limits
+type Class int
+
+const (
+	// ClassNormal covers regular clients
+	ClassNormal Class = iota
+	// ClassReplica covers replicas receiving the replication stream
+	ClassReplica
+	// ClassPubSub covers clients subscribed to at least one channel or pattern
+	ClassPubSub
+
+	// NumClasses is the number of client classes
+	NumClasses
+)
+
+// classNames are the names used for each class in client-output-buffer-limit
+var classNames = [NumClasses]string{"normal", "replica", "pubsub"}
+
+// String returns the class name
+func (c Class) String() string {
+	return classNames[c]
+}
+
+// OutputLimit bounds the output buffer of a client class. A client is
+// disconnected as soon as its pending output exceeds Hard, or once it has
+// stayed above Soft for SoftSeconds. Zero disables a limit.
+type OutputLimit struct {
+	Hard        int64
+	Soft        int64
+	SoftSeconds time.Duration
+}
+
+// DefaultOutputLimits matches the Redis defaults: normal clients are
+// unlimited, replicas and subscribers are not
+var DefaultOutputLimits = [NumClasses]OutputLimit{
+	ClassNormal:  {},
+	ClassReplica: {Hard: 256 * 1024 * 1024, Soft: 64 * 1024 * 1024, SoftSeconds: 60 * time.Second},
+	ClassPubSub:  {Hard: 32 * 1024 * 1024, Soft: 8 * 1024 * 1024, SoftSeconds: 60 * time.Second},
+}
+
+// OutputLimits holds the output buffer limits of every class and can be
+// updated while clients are connected
+type OutputLimits struct {
+	limits atomic.Pointer[[NumClasses]OutputLimit]
+}
+
+// NewOutputLimits creates output limits set to DefaultOutputLimits
+func NewOutputLimits() *OutputLimits {
+	l := &OutputLimits{}
+	defaults := DefaultOutputLimits
+	l.limits.Store(&defaults)
+	return l
+}
+
+// Get returns the limit of a class. It is safe to call on a nil
+// OutputLimits, which imposes no limits.
+func (l *OutputLimits) Get(class Class) OutputLimit {
+	if l == nil {
+		return OutputLimit{}
+	}
+	return l.limits.Load()[class]
+}
+
+// Set replaces the limits of every class
+func (l *OutputLimits) Set(limits [NumClasses]OutputLimit) {
+	l.limits.Store(&limits)
+}
+
+// ParseClass parses a class name as used in client-output-buffer-limit,
+// accepting "slave" as an alias for "replica"
+func ParseClass(name string) (Class, bool) {
+	switch strings.ToLower(name) {
+	case "normal":
+		return ClassNormal, true
+	case "replica", "slave":
+		return ClassReplica, true
+	case "pubsub":
+		return ClassPubSub, true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,326 @@
+package command
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// ACLCommand implements the ACL command
+type ACLCommand struct {
+	acl      *acl.ACL
+	registry Registry
+	aclFile  string
+}
+
+// Ensure ACLCommand implements ClientHandler and Describer
+var (
+	_ ClientHandler = (*ACLCommand)(nil)
+	_ Describer     = (*ACLCommand)(nil)
+)
+
+// NewACLCommand creates an ACL command handler. Categories are resolved
+// with the metadata of the commands in registry; ACL SAVE and LOAD use
+// aclFile, if set.
+func NewACLCommand(a *acl.ACL, registry Registry, aclFile string) *ACLCommand {
+	return &ACLCommand{acl: a, registry: registry, aclFile: aclFile}
+}
+
+func (c *ACLCommand) Name() string {
+	return "ACL"
+}
+
+func (c *ACLCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -2, Categories: adminCategories,
+		Summary: "A container for Access List Control commands.", Since: "6.0.0", Group: "server",
+		Subcommands: map[string]Metadata{
+			"cat":     {Arity: -2, Flags: FlagNoScript | FlagLoading | FlagStale, Categories: acl.CategorySlow, Summary: "Lists the ACL categories, or the commands inside a category.", Since: "6.0.0"},
+			"deluser": {Arity: -3, Flags: adminFlags, Categories: adminCategories, Summary: "Deletes ACL users, and terminates their connections.", Since: "6.0.0"},
+			"dryrun":  {Arity: -4, Flags: adminFlags, Categories: adminCategories, Summary: "Simulates the execution of a command by a user, without executing the command.", Since: "7.0.0"},
+			"genpass": {Arity: -2, Flags: FlagNoScript | FlagLoading | FlagStale, Categories: acl.CategorySlow, Summary: "Generates a pseudorandom, secure password that can be used to identify ACL users.", Since: "6.0.0"},
+			"getuser": {Arity: 3, Flags: adminFlags, Categories: adminCategories, Summary: "Lists the ACL rules of a user.", Since: "6.0.0"},
+			"list":    {Arity: 2, Flags: adminFlags, Categories: adminCategories, Summary: "Dumps the effective rules in ACL file format.", Since: "6.0.0"},
+			"load":    {Arity: 2, Flags: adminFlags, Categories: adminCategories, Summary: "Reloads the rules from the configured ACL file.", Since: "6.0.0"},
+			"log":     {Arity: -2, Flags: adminFlags, Categories: adminCategories, Summary: "Lists recent security events generated due to ACL rules.", Since: "6.0.0"},
+			"save":    {Arity: 2, Flags: adminFlags, Categories: adminCategories, Summary: "Saves the effective ACL rules in the configured ACL file.", Since: "6.0.0"},
+			"setuser": {Arity: -3, Flags: adminFlags, Categories: adminCategories, Summary: "Creates and modifies an ACL user and its rules.", Since: "6.0.0"},
+			"users":   {Arity: 2, Flags: adminFlags, Categories: adminCategories, Summary: "Lists all ACL users.", Since: "6.0.0"},
+			"whoami":  {Arity: 2, Flags: FlagNoScript | FlagLoading | FlagStale, Categories: acl.CategorySlow, Summary: "Returns the authenticated username of the current connection.", Since: "6.0.0"},
+		},
+	}
+}
+
+func (c *ACLCommand) Execute(args [][]byte) resp.RedisValue {
+	return c.ExecuteClient(nil, args)
+}
+
+func (c *ACLCommand) ExecuteClient(cl *client.Client, args [][]byte) resp.RedisValue {
+	subcommand := strings.ToLower(string(args[0]))
+	args = args[1:]
+
+	// Arity is checked by the dispatcher; only the maximum of subcommands
+	// taking an optional argument is left to check here
+	if maxArgs, ok := aclOptionalArg[subcommand]; ok && len(args) > maxArgs {
+		return wrongArgs("acl|" + subcommand)
+	}
+
+	switch subcommand {
+	case "setuser":
+		return c.handleSetUser(args)
+	case "getuser":
+		return c.handleGetUser(string(args[0]))
+	case "deluser":
+		return c.handleDelUser(args)
+	case "list":
+		users := c.acl.Users()
+		lines := make([]resp.RedisValue, len(users))
+		for i, u := range users {
+			lines[i] = resp.NewBulkString(u.String())
+		}
+		return resp.Array{Values: lines}
+	case "users":
+		users := c.acl.Users()
+		names := make([]resp.RedisValue, len(users))
+		for i, u := range users {
+			names[i] = resp.NewBulkString(u.Name)
+		}
+		return resp.Array{Values: names}
+	case "whoami":
+		if cl == nil {
+			return resp.Error{Value: "ERR 'acl|whoami' requires a client connection"}
+		}
+		return resp.NewBulkString(cl.User())
+	case "cat":
+		return c.handleCat(args)
+	case "log":
+		return c.handleLog(args)
+	case "save":
+		return c.handleSave()
+	case "load":
+		return c.handleLoad()
+	case "genpass":
+		return c.handleGenPass(args)
+	case "dryrun":
+		return c.handleDryRun(args)
+	default:
+		return resp.Error{Value: fmt.Sprintf("ERR unknown subcommand '%s'. Try ACL HELP.", subcommand)}
+	}
+}
+
+// aclOptionalArg holds the maximum number of arguments of the ACL
+// subcommands taking an optional argument
+var aclOptionalArg = map[string]int{
+	"cat":     1,
+	"log":     1,
+	"genpass": 1,
+}
+
+func (c *ACLCommand) handleSetUser(args [][]byte) resp.RedisValue {
+	rules := make([]string, len(args)-1)
+	for i, arg := range args[1:] {
+		rules[i] = string(arg)
+	}
+
+	if err := c.acl.SetUser(string(args[0]), rules); err != nil {
+		return resp.Error{Value: "ERR " + err.Error()}
+	}
+	return resp.SimpleString{Value: "OK"}
+}
+
+func (c *ACLCommand) handleGetUser(name string) resp.RedisValue {
+	u, ok := c.acl.User(name)
+	if !ok {
+		return resp.Null{}
+	}
+
+	flags := []resp.RedisValue{resp.NewBulkString("off")}
+	if u.Enabled {
+		flags[0] = resp.NewBulkString("on")
+	}
+	if u.NoPass {
+		flags = append(flags, resp.NewBulkString("nopass"))
+	}
+
+	passwords := make([]resp.RedisValue, len(u.Passwords))
+	for i, hash := range u.Passwords {
+		passwords[i] = resp.NewBulkString(hash)
+	}
+
+	return resp.Map{Entries: []resp.MapEntry{
+		{Key: resp.NewBulkString("flags"), Value: resp.Array{Values: flags}},
+		{Key: resp.NewBulkString("passwords"), Value: resp.Array{Values: passwords}},
+		{Key: resp.NewBulkString("commands"), Value: resp.NewBulkString(u.CommandRules())},
+		{Key: resp.NewBulkString("keys"), Value: resp.NewBulkString(u.KeyRules())},
+		{Key: resp.NewBulkString("channels"), Value: resp.NewBulkString(u.ChannelRules())},
+		{Key: resp.NewBulkString("selectors"), Value: resp.Array{Values: []resp.RedisValue{}}},
+	}}
+}
+
+func (c *ACLCommand) handleDelUser(args [][]byte) resp.RedisValue {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = string(arg)
+	}
+
+	deleted, err := c.acl.DelUser(names...)
+	if err != nil {
+		return resp.Error{Value: "ERR " + err.Error()}
+	}
+	return resp.Integer{Value: int64(deleted)}
+}
+
+// handleCat lists the categories, or the commands in a category
+func (c *ACLCommand) handleCat(args [][]byte) resp.RedisValue {
+	if len(args) == 0 {
+		names := acl.CategoryNames()
+		values := make([]resp.RedisValue, len(names))
+		for i, name := range names {
+			values[i] = resp.NewBulkString(name)
+		}
+		return resp.Array{Values: values}
+	}
+
+	category, ok := acl.ParseCategory(string(args[0]))
+	if !ok {
+		return resp.Error{Value: fmt.Sprintf("ERR Unknown category '%s'", args[0])}
+	}
+
+	var names []string
+	for _, handler := range c.registry.GetAll() {
+		meta, _ := c.registry.OriginalMetadata(handler.Name())
+		name := strings.ToLower(handler.Name())
+		if meta.Categories&category != 0 {
+			names = append(names, name)
+		}
+		for sub, subMeta := range meta.Subcommands {
+			if subMeta.Categories&category != 0 && meta.Categories&category == 0 {
+				names = append(names, name+"|"+sub)
+			}
+		}
+	}
+	sort.Strings(names)
+
+	values := make([]resp.RedisValue, len(names))
+	for i, name := range names {
+		values[i] = resp.NewBulkString(name)
+	}
+	return resp.Array{Values: values}
+}
+
+// handleLog returns the newest ACL LOG entries, 10 by default, or clears
+// the log with RESET
+func (c *ACLCommand) handleLog(args [][]byte) resp.RedisValue {
+	count := 10
+	if len(args) == 1 {
+		if strings.EqualFold(string(args[0]), "RESET") {
+			c.acl.Log().Reset()
+			return resp.SimpleString{Value: "OK"}
+		}
+		n, err := strconv.Atoi(string(args[0]))
+		if err != nil || n < 0 {
+			return resp.Error{Value: "ERR value is out of range, must be positive"}
+		}
+		count = n
+	}
+
+	now := time.Now()
+	entries := c.acl.Log().Entries(count)
+	values := make([]resp.RedisValue, len(entries))
+	for i, e := range entries {
+		values[i] = resp.Map{Entries: []resp.MapEntry{
+			{Key: resp.NewBulkString("count"), Value: resp.Integer{Value: int64(e.Count)}},
+			{Key: resp.NewBulkString("reason"), Value: resp.NewBulkString(e.Reason)},
+			{Key: resp.NewBulkString("context"), Value: resp.NewBulkString(e.Context)},
+			{Key: resp.NewBulkString("object"), Value: resp.NewBulkString(e.Object)},
+			{Key: resp.NewBulkString("username"), Value: resp.NewBulkString(e.Username)},
+			{Key: resp.NewBulkString("age-seconds"), Value: resp.Double{Value: now.Sub(e.Created).Seconds()}},
+			{Key: resp.NewBulkString("client-info"), Value: resp.NewBulkString(e.ClientInfo)},
+			{Key: resp.NewBulkString("entry-id"), Value: resp.Integer{Value: e.ID}},
+			{Key: resp.NewBulkString("timestamp-created"), Value: resp.Integer{Value: e.Created.UnixMilli()}},
+			{Key: resp.NewBulkString("timestamp-last-updated"), Value: resp.Integer{Value: e.Updated.UnixMilli()}},
+		}}
+	}
+	return resp.Array{Values: values}
+}
+
+// errNoACLFile is returned by ACL SAVE and LOAD without an aclfile
+var errNoACLFile = errors.New("ERR This Redis instance is not configured to use an ACL file. You may want to specify users via the ACL SETUSER command and then issue a CONFIG REWRITE (assuming you have a Redis configuration file set) in order to store users in the Redis configuration.")
+
+func (c *ACLCommand) handleSave() resp.RedisValue {
+	if c.aclFile == "" {
+		return resp.Error{Value: errNoACLFile.Error()}
+	}
+	if err := c.acl.Save(c.aclFile); err != nil {
+		return resp.Error{Value: fmt.Sprintf("ERR There was an error trying to save the ACLs. Please check the server logs for more information: %v", err)}
+	}
+	return resp.SimpleString{Value: "OK"}
+}
+
+func (c *ACLCommand) handleLoad() resp.RedisValue {
+	if c.aclFile == "" {
+		return resp.Error{Value: errNoACLFile.Error()}
+	}
+	if err := c.acl.Load(c.aclFile); err != nil {
+		return resp.Error{Value: "ERR " + err.Error()}
+	}
+	return resp.SimpleString{Value: "OK"}
+}
+
+// handleGenPass returns a random password of the given number of bits,
+// 256 by default, as hex
+func (c *ACLCommand) handleGenPass(args [][]byte) resp.RedisValue {
+	bits := 256
+	if len(args) == 1 {
+		n, err := strconv.Atoi(string(args[0]))
+		if err != nil || n <= 0 || n > 4096 {
+			return resp.Error{Value: "ERR ACL GENPASS argument must be the number of bits for the output password, a positive number up to 4096"}
+		}
+		bits = n
+	}
+
+	chars := (bits + 3) / 4
+	buf := make([]byte, (chars+1)/2)
+	rand.Read(buf)
+	return resp.NewBulkString(hex.EncodeToString(buf)[:chars])
+}
+
+// handleDryRun checks whether a user could run a command without running it
+func (c *ACLCommand) handleDryRun(args [][]byte) resp.RedisValue {
+	username := string(args[0])
+	if _, ok := c.acl.User(username); !ok {
+		return resp.Error{Value: fmt.Sprintf("ERR User '%s' not found", username)}
+	}
+
+	handler, ok := c.registry.Get(string(args[1]))
+	if !ok {
+		return resp.Error{Value: fmt.Sprintf("ERR Command '%s' not found", args[1])}
+	}
+	meta, _ := c.registry.Metadata(string(args[1]))
+	if reply := meta.CheckArity(args[1:]); reply != nil {
+		return reply
+	}
+
+	// Rules refer to commands by their original name, even if renamed
+	cmdArgs := append([][]byte{[]byte(handler.Name())}, args[2:]...)
+	err := c.acl.Check(username, meta.Request(cmdArgs))
+	var denied *acl.Denied
+	if errors.As(err, &denied) {
+		switch denied.Reason {
+		case "command":
+			return resp.NewBulkString(fmt.Sprintf("User %s has no permissions to run the '%s' command", username, denied.Object))
+		default:
+			return resp.NewBulkString(fmt.Sprintf("User %s has no permissions to access the '%s' %s", username, denied.Object, denied.Reason))
+		}
+	}
+	return resp.SimpleString{Value: "OK"}
+}
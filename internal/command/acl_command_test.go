@@ -0,0 +1,182 @@
+package command
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage/memory"
+)
+
+// newACLRegistry returns a registry checking permissions against a new
+// ACL, with the ACL command using aclFile
+func newACLRegistry(t *testing.T, aclFile string) (*DefaultRegistry, *acl.ACL) {
+	t.Helper()
+	r := NewRegistry()
+	acls := acl.New(func(name string) bool {
+		_, ok := r.Original(name)
+		return ok
+	})
+	store, hub := memory.NewStore(), pubsub.NewHub()
+	for _, handler := range []Handler{
+		NewGetCommand(store),
+		NewSetCommand(store),
+		NewKeysCommand(store),
+		NewPublishCommand(hub),
+		NewAuthCommand(acls),
+		NewACLCommand(acls, r, aclFile),
+	} {
+		if err := r.Register(handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	r.Use(NewACLMiddleware(acls))
+	return r, acls
+}
+
+// run runs a command through the registry's middleware, as the server does
+func run(r *DefaultRegistry, c *client.Client, args ...string) resp.RedisValue {
+	handler, _ := r.Get(args[0])
+	meta, _ := r.Metadata(args[0])
+	return r.Run(&Context{Client: c, Args: bytesArgs(args...), Metadata: meta, Handler: handler})
+}
+
+// bulks returns an array of bulk strings
+func bulks(values ...string) resp.Array {
+	array := resp.Array{Values: make([]resp.RedisValue, len(values))}
+	for i, v := range values {
+		array.Values[i] = resp.NewBulkString(v)
+	}
+	return array
+}
+
+func TestACLCommand(t *testing.T) {
+	aclFile := filepath.Join(t.TempDir(), "users.acl")
+	r, _ := newACLRegistry(t, aclFile)
+	c := newTestClient(t)
+	ok := resp.SimpleString{Value: "OK"}
+
+	steps := []struct {
+		args []string
+		want resp.RedisValue
+	}{
+		{[]string{"ACL", "SETUSER", "alice", "on", "nopass", "~cache:*", "&news.*", "+@read", "-keys"}, ok},
+		{[]string{"ACL", "SETUSER", "alice", "+nosuch"}, resp.Error{Value: "ERR Error in ACL SETUSER modifier '+nosuch': Unknown command or category name in ACL"}},
+		{[]string{"ACL", "GETUSER", "alice"}, resp.Map{Entries: []resp.MapEntry{
+			{Key: resp.NewBulkString("flags"), Value: bulks("on", "nopass")},
+			{Key: resp.NewBulkString("passwords"), Value: bulks()},
+			{Key: resp.NewBulkString("commands"), Value: resp.NewBulkString("-@all +@read -keys")},
+			{Key: resp.NewBulkString("keys"), Value: resp.NewBulkString("~cache:*")},
+			{Key: resp.NewBulkString("channels"), Value: resp.NewBulkString("&news.*")},
+			{Key: resp.NewBulkString("selectors"), Value: bulks()},
+		}}},
+		{[]string{"ACL", "GETUSER", "nobody"}, resp.Null{}},
+		{[]string{"ACL", "USERS"}, bulks("alice", "default")},
+		{[]string{"ACL", "LIST"}, bulks("user alice on nopass ~cache:* &news.* -@all +@read -keys", "user default on nopass ~* &* +@all")},
+		{[]string{"ACL", "WHOAMI"}, resp.NewBulkString("default")},
+		{[]string{"ACL", "DRYRUN", "alice", "get", "cache:1"}, ok},
+		{[]string{"ACL", "DRYRUN", "alice", "get", "other"}, resp.NewBulkString("User alice has no permissions to access the 'other' key")},
+		{[]string{"ACL", "DRYRUN", "alice", "set", "cache:1", "v"}, resp.NewBulkString("User alice has no permissions to run the 'set' command")},
+		{[]string{"ACL", "DRYRUN", "alice", "publish", "alerts", "hi"}, resp.NewBulkString("User alice has no permissions to run the 'publish' command")},
+		{[]string{"ACL", "DRYRUN", "alice", "get"}, resp.Error{Value: "ERR wrong number of arguments for 'get' command"}},
+		{[]string{"ACL", "DRYRUN", "nobody", "get", "k"}, resp.Error{Value: "ERR User 'nobody' not found"}},
+		{[]string{"ACL", "DRYRUN", "alice", "nosuch"}, resp.Error{Value: "ERR Command 'nosuch' not found"}},
+		{[]string{"ACL", "CAT", "pubsub"}, bulks("publish")},
+		{[]string{"ACL", "CAT", "keyspace"}, bulks("keys")},
+		{[]string{"ACL", "CAT", "nosuch"}, resp.Error{Value: "ERR Unknown category 'nosuch'"}},
+		{[]string{"ACL", "CAT", "read", "write"}, resp.Error{Value: "ERR wrong number of arguments for 'acl|cat' command"}},
+		{[]string{"ACL", "GENPASS", "0"}, resp.Error{Value: "ERR ACL GENPASS argument must be the number of bits for the output password, a positive number up to 4096"}},
+		{[]string{"ACL", "SAVE"}, ok},
+		{[]string{"ACL", "DELUSER", "default"}, resp.Error{Value: "ERR The 'default' user cannot be removed"}},
+		{[]string{"ACL", "DELUSER", "alice", "bob"}, resp.Integer{Value: 1}},
+		{[]string{"ACL", "USERS"}, bulks("default")},
+		{[]string{"ACL", "LOAD"}, ok},
+		{[]string{"ACL", "USERS"}, bulks("alice", "default")},
+		{[]string{"ACL", "LOG"}, resp.Array{Values: []resp.RedisValue{}}},
+		{[]string{"ACL", "NOSUCH"}, resp.Error{Value: "ERR unknown subcommand 'nosuch'. Try ACL HELP."}},
+	}
+
+	for _, step := range steps {
+		assertReply(t, run(r, c, step.args...), step.want)
+		if t.Failed() {
+			t.Fatalf("%v failed", step.args)
+		}
+	}
+
+	if got, ok := run(r, c, "ACL", "CAT").(resp.Array); !ok || len(got.Values) != len(acl.CategoryNames()) {
+		t.Errorf("ACL CAT = %v, want every category", got)
+	}
+	if got, ok := run(r, c, "ACL", "GENPASS", "10").(resp.BulkString); !ok || len(got.Value) != 3 {
+		t.Errorf("ACL GENPASS 10 = %v, want 3 hex digits", got)
+	}
+}
+
+func TestACLFileNotConfigured(t *testing.T) {
+	r, _ := newACLRegistry(t, "")
+	c := newTestClient(t)
+	for _, sub := range []string{"SAVE", "LOAD"} {
+		assertReply(t, run(r, c, "ACL", sub), resp.Error{Value: errNoACLFile.Error()})
+	}
+}
+
+func TestACLMiddleware(t *testing.T) {
+	r, acls := newACLRegistry(t, "")
+	if err := acls.SetUser("alice", []string{"on", ">pass", "%R~cache:*", "&news.*", "+@read", "-keys", "+set", "+publish"}); err != nil {
+		t.Fatal(err)
+	}
+	c := newTestClient(t)
+	assertReply(t, run(r, c, "AUTH", "alice", "pass"), resp.SimpleString{Value: "OK"})
+
+	steps := []struct {
+		args []string
+		want resp.RedisValue
+	}{
+		{[]string{"GET", "cache:1"}, resp.Null{}},
+		{[]string{"GET", "secret"}, resp.Error{Value: "NOPERM No permissions to access a key"}},
+		{[]string{"SET", "cache:1", "v"}, resp.Error{Value: "NOPERM No permissions to access a key"}},
+		{[]string{"KEYS", "*"}, resp.Error{Value: "NOPERM User alice has no permissions to run the 'keys' command"}},
+		{[]string{"ACL", "WHOAMI"}, resp.Error{Value: "NOPERM User alice has no permissions to run the 'acl|whoami' command"}},
+		{[]string{"PUBLISH", "news.today", "hi"}, resp.Integer{Value: 0}},
+		{[]string{"PUBLISH", "alerts", "hi"}, resp.Error{Value: "NOPERM No permissions to access a channel"}},
+		{[]string{"GET", "secret"}, resp.Error{Value: "NOPERM No permissions to access a key"}},
+		{[]string{"AUTH", "alice", "wrong"}, resp.Error{Value: "WRONGPASS invalid username-password pair or user is disabled."}},
+	}
+
+	for _, step := range steps {
+		assertReply(t, run(r, c, step.args...), step.want)
+	}
+
+	// Each denial is logged once, with repeats counted
+	entries := acls.Log().Entries(10)
+	want := []struct {
+		reason, object string
+		count          int
+	}{
+		{"auth", "AUTH", 1},
+		{"key", "secret", 2},
+		{"channel", "alerts", 1},
+		{"command", "acl|whoami", 1},
+		{"command", "keys", 1},
+		{"key", "cache:1", 1},
+	}
+	if len(entries) != len(want) {
+		t.Fatalf("ACL LOG has %d entries, want %d: %+v", len(entries), len(want), entries)
+	}
+	for i, w := range want {
+		if e := entries[i]; e.Reason != w.reason || e.Object != w.object || e.Count != w.count || e.Username != "alice" {
+			t.Errorf("entry %d = %+v, want %s %s x%d", i, e, w.reason, w.object, w.count)
+		}
+	}
+
+	// Deleting the user logs its clients out
+	if _, err := acls.DelUser("alice"); err != nil {
+		t.Fatal(err)
+	}
+	assertReply(t, run(r, c, "GET", "cache:1"), resp.Error{Value: "NOAUTH Authentication required."})
+	if c.Authenticated() {
+		t.Error("client still authenticated as a deleted user")
+	}
+}
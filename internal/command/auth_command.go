@@ -0,0 +1,61 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/internal/auth"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// AuthCommand implements the AUTH command
+type AuthCommand struct {
+	auth auth.Authenticator
+}
+
+// Ensure AuthCommand implements ClientHandler and Describer
+var (
+	_ ClientHandler = (*AuthCommand)(nil)
+	_ Describer     = (*AuthCommand)(nil)
+)
+
+func NewAuthCommand(authenticator auth.Authenticator) *AuthCommand {
+	return &AuthCommand{auth: authenticator}
+}
+
+func (c *AuthCommand) Name() string {
+	return "AUTH"
+}
+
+func (c *AuthCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -2, Flags: authFlags, Categories: connCategories,
+		Summary: "Authenticates the connection.", Since: "1.0.0", Group: "connection",
+	}
+}
+
+func (c *AuthCommand) Execute(args [][]byte) resp.RedisValue {
+	return resp.Error{Value: "ERR 'auth' requires a client connection"}
+}
+
+func (c *AuthCommand) ExecuteClient(cl *client.Client, args [][]byte) resp.RedisValue {
+	var username, password string
+	switch len(args) {
+	case 1:
+		// The single-argument form authenticates the default user
+		if !c.auth.Required() {
+			return resp.Error{Value: "ERR AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?"}
+		}
+		username, password = auth.DefaultUser, string(args[0])
+	case 2:
+		username, password = string(args[0]), string(args[1])
+	default:
+		return wrongArgs("auth")
+	}
+
+	if err := c.auth.Authenticate(username, password); err != nil {
+		return resp.Error{Value: err.Error()}
+	}
+
+	cl.SetUser(username)
+	cl.SetAuthenticated(true)
+	return resp.SimpleString{Value: "OK"}
+}
@@ -0,0 +1,45 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/auth"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+func TestAuthCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		required   bool
+		args       []string
+		want       resp.RedisValue
+		wantUser   string
+		wantAuthed bool
+	}{
+		{"password", true, []string{"secret"}, resp.SimpleString{Value: "OK"}, auth.DefaultUser, true},
+		{"username and password", true, []string{"alice", "secret"}, resp.SimpleString{Value: "OK"}, "alice", true},
+		{"wrong password", true, []string{"nope"}, resp.Error{Value: "WRONGPASS invalid username-password pair or user is disabled."}, auth.DefaultUser, false},
+		{"unknown user", true, []string{"bob", "secret"}, resp.Error{Value: "WRONGPASS invalid username-password pair or user is disabled."}, auth.DefaultUser, false},
+		{"no password configured", false, []string{"secret"}, resp.Error{Value: "ERR AUTH <password> called without any password configured for the default user. Are you sure your configuration is correct?"}, auth.DefaultUser, false},
+		{"too many arguments", true, []string{"alice", "secret", "extra"}, resp.Error{Value: "ERR wrong number of arguments for 'auth' command"}, auth.DefaultUser, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			authenticator := fakeAuthenticator{username: "alice", password: "secret", required: tt.required}
+			if len(tt.args) == 1 {
+				authenticator.username = auth.DefaultUser
+			}
+			c := newTestClient(t)
+			c.SetAuthenticated(false)
+
+			assertReply(t, NewAuthCommand(authenticator).ExecuteClient(c, bytesArgs(tt.args...)), tt.want)
+			if c.User() != tt.wantUser {
+				t.Errorf("user %q, want %q", c.User(), tt.wantUser)
+			}
+			if c.Authenticated() != tt.wantAuthed {
+				t.Errorf("authenticated = %v, want %v", c.Authenticated(), tt.wantAuthed)
+			}
+		})
+	}
+}
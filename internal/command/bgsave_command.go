@@ -0,0 +1,53 @@
+package command
+
+import (
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// BgSaveCommand implements the BGSAVE command
+type BgSaveCommand struct {
+	saver Saver
+}
+
+// Ensure BgSaveCommand implements Handler and Describer
+var (
+	_ Handler   = (*BgSaveCommand)(nil)
+	_ Describer = (*BgSaveCommand)(nil)
+)
+
+func NewBgSaveCommand(saver Saver) *BgSaveCommand {
+	return &BgSaveCommand{saver: saver}
+}
+
+func (c *BgSaveCommand) Name() string {
+	return "BGSAVE"
+}
+
+func (c *BgSaveCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -1, Flags: FlagAdmin | FlagNoScript, Categories: adminCategories,
+		Summary: "Asynchronously saves the database(s) to disk.", Since: "1.0.0", Group: "server",
+	}
+}
+
+func (c *BgSaveCommand) Execute(args [][]byte) resp.RedisValue {
+	schedule := false
+	if len(args) > 0 {
+		if len(args) > 1 || !strings.EqualFold(string(args[0]), "SCHEDULE") {
+			return resp.Error{Value: "ERR syntax error"}
+		}
+		schedule = true
+	}
+
+	scheduled, err := c.saver.BackgroundSave(schedule)
+	switch {
+	case err != nil:
+		return resp.Error{Value: "ERR " + err.Error()}
+	case scheduled:
+		return resp.SimpleString{Value: "Background saving scheduled"}
+	default:
+		return resp.SimpleString{Value: "Background saving started"}
+	}
+}
@@ -1,24 +1,73 @@
 package command
 
-import "github.com/codecrafters-io/redis-starter-go/internal/resp"
+import (
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
 
 // Handler defines the interface for command handling
 type Handler interface {
 	// Name returns the command name (e.g., "GET", "SET")
 	Name() string
 
-	// Execute runs the command with the given arguments
-	Execute(args []string) resp.RedisValue
+	// Execute runs the command with the given binary-safe arguments. The
+	// arguments may point into the connection's read buffer and are only
+	// valid until Execute returns: handlers must copy anything they keep.
+	Execute(args [][]byte) resp.RedisValue
+}
+
+// ClientHandler is implemented by handlers that need the calling client,
+// e.g. to manage its pub/sub subscriptions. The dispatcher prefers
+// ExecuteClient over Execute when a handler implements it.
+type ClientHandler interface {
+	Handler
+
+	// ExecuteClient runs the command on behalf of the given client
+	ExecuteClient(c *client.Client, args [][]byte) resp.RedisValue
+}
+
+// Describer is implemented by handlers that describe the command they
+// implement. The registry only accepts handlers that do, so that arity,
+// flags and ACL categories are never missing.
+type Describer interface {
+	// Describe returns the metadata of the command
+	Describe() Metadata
 }
 
 // Registry maintains a mapping of command names to their handlers
 type Registry interface {
-	// Register adds a command handler to the registry
-	Register(handler Handler)
+	// Register adds a command handler to the registry. It fails if the
+	// handler doesn't implement Describer.
+	Register(handler Handler) error
 
-	// Get retrieves a command handler by name
+	// Get retrieves a command handler by the name clients call it by
 	Get(name string) (Handler, bool)
 
-	// GetAll returns all registered handlers
+	// Original retrieves a command handler by its original name, regardless
+	// of renames, for internal callers
+	Original(name string) (Handler, bool)
+
+	// GetAll returns all handlers clients can call
 	GetAll() []Handler
+
+	// Names returns the lowercase names clients call commands by, sorted
+	Names() []string
+
+	// Metadata describes a command by the name clients call it by
+	Metadata(name string) (Metadata, bool)
+
+	// OriginalMetadata describes a command by its original name
+	OriginalMetadata(name string) (Metadata, bool)
+
+	// Rename changes the name clients call a command by. An empty newName
+	// disables the command.
+	Rename(name, newName string) error
+
+	// Use appends a middleware to the chain every command passes through.
+	// Middlewares run in the order they were added, before any command is
+	// run.
+	Use(middleware Middleware)
+
+	// Run passes a command through the middleware chain to its handler
+	Run(ctx *Context) resp.RedisValue
 }
@@ -0,0 +1,314 @@
+package command
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/pattern"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// CommandCommand implements the COMMAND command, which describes the
+// registered commands to clients
+type CommandCommand struct {
+	registry Registry
+}
+
+// Ensure CommandCommand implements Handler and Describer
+var (
+	_ Handler   = (*CommandCommand)(nil)
+	_ Describer = (*CommandCommand)(nil)
+)
+
+// NewCommandCommand creates a COMMAND handler describing the commands in
+// registry
+func NewCommandCommand(registry Registry) *CommandCommand {
+	return &CommandCommand{registry: registry}
+}
+
+func (c *CommandCommand) Name() string {
+	return "COMMAND"
+}
+
+func (c *CommandCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -1, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryConnection,
+		Summary: "Returns detailed information about all commands.", Since: "2.8.13", Group: "server",
+		Subcommands: map[string]Metadata{
+			"count":           {Arity: 2, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryConnection, Summary: "Returns a count of commands.", Since: "2.8.13"},
+			"docs":            {Arity: -2, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryConnection, Summary: "Returns documentary information about one, multiple or all commands.", Since: "7.0.0"},
+			"getkeys":         {Arity: -3, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryConnection, Summary: "Extracts the key names from an arbitrary command.", Since: "2.8.13"},
+			"getkeysandflags": {Arity: -3, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryConnection, Summary: "Extracts the key names and access flags for an arbitrary command.", Since: "7.0.0"},
+			"info":            {Arity: -2, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryConnection, Summary: "Returns information about one, multiple or all commands.", Since: "2.8.13"},
+			"list":            {Arity: -2, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryConnection, Summary: "Returns a list of command names.", Since: "7.0.0"},
+		},
+	}
+}
+
+func (c *CommandCommand) Execute(args [][]byte) resp.RedisValue {
+	if len(args) == 0 {
+		return c.handleInfo(nil)
+	}
+
+	subcommand := strings.ToLower(string(args[0]))
+	args = args[1:]
+	switch subcommand {
+	case "count":
+		return resp.Integer{Value: int64(len(c.registry.Names()))}
+	case "info":
+		return c.handleInfo(args)
+	case "docs":
+		return c.handleDocs(args)
+	case "list":
+		return c.handleList(args)
+	case "getkeys":
+		return c.handleGetKeys(args, false)
+	case "getkeysandflags":
+		return c.handleGetKeys(args, true)
+	default:
+		return resp.Error{Value: fmt.Sprintf("ERR unknown subcommand '%s'. Try COMMAND HELP.", subcommand)}
+	}
+}
+
+// handleInfo describes the named commands, or every command if names is
+// empty. Commands are named as clients call them, so renamed commands are
+// described by their new name, and unknown or disabled ones are reported
+// as null.
+func (c *CommandCommand) handleInfo(names [][]byte) resp.RedisValue {
+	if len(names) == 0 {
+		for _, name := range c.registry.Names() {
+			names = append(names, []byte(name))
+		}
+	}
+
+	values := make([]resp.RedisValue, len(names))
+	for i, name := range names {
+		meta, ok := c.registry.Metadata(string(name))
+		if !ok {
+			values[i] = resp.Null{}
+			continue
+		}
+		values[i] = commandInfo(strings.ToLower(string(name)), meta)
+	}
+	return resp.Array{Values: values}
+}
+
+// commandInfo is the COMMAND INFO reply for a command or subcommand
+func commandInfo(name string, meta Metadata) resp.RedisValue {
+	flags := meta.Flags.Names()
+	if meta.Categories&acl.CategoryFast != 0 {
+		flags = append(flags, "fast")
+	}
+	flagValues := make([]resp.RedisValue, len(flags))
+	for i, flag := range flags {
+		flagValues[i] = resp.SimpleString{Value: flag}
+	}
+
+	categories := meta.Categories.Names()
+	categoryValues := make([]resp.RedisValue, len(categories))
+	for i, category := range categories {
+		categoryValues[i] = resp.SimpleString{Value: "@" + category}
+	}
+
+	var keySpecs []resp.RedisValue
+	if meta.Keys.First > 0 {
+		keySpecs = append(keySpecs, keySpec(meta))
+	}
+
+	var subcommands []resp.RedisValue
+	for _, sub := range sortedSubcommands(meta) {
+		subcommands = append(subcommands, commandInfo(name+"|"+sub, meta.Subcommands[sub]))
+	}
+
+	return resp.Array{Values: []resp.RedisValue{
+		resp.NewBulkString(name),
+		resp.Integer{Value: int64(meta.Arity)},
+		resp.Set{Values: flagValues},
+		resp.Integer{Value: int64(meta.Keys.First)},
+		resp.Integer{Value: int64(meta.Keys.Last)},
+		resp.Integer{Value: int64(meta.Keys.Step)},
+		resp.Set{Values: categoryValues},
+		resp.Array{Values: []resp.RedisValue{}},
+		resp.Array{Values: keySpecs},
+		resp.Array{Values: subcommands},
+	}}
+}
+
+// keySpec describes the key arguments of a command as a key specification:
+// a range of arguments starting at a fixed index
+func keySpec(meta Metadata) resp.RedisValue {
+	lastKey := meta.Keys.Last
+	if lastKey >= 0 {
+		lastKey -= meta.Keys.First
+	}
+
+	flags := make([]resp.RedisValue, 0, 2)
+	for _, flag := range keyFlags(meta) {
+		flags = append(flags, resp.SimpleString{Value: flag})
+	}
+
+	return resp.Map{Entries: []resp.MapEntry{
+		{Key: resp.NewBulkString("flags"), Value: resp.Set{Values: flags}},
+		{Key: resp.NewBulkString("begin_search"), Value: resp.Map{Entries: []resp.MapEntry{
+			{Key: resp.NewBulkString("type"), Value: resp.NewBulkString("index")},
+			{Key: resp.NewBulkString("spec"), Value: resp.Map{Entries: []resp.MapEntry{
+				{Key: resp.NewBulkString("index"), Value: resp.Integer{Value: int64(meta.Keys.First)}},
+			}}},
+		}}},
+		{Key: resp.NewBulkString("find_keys"), Value: resp.Map{Entries: []resp.MapEntry{
+			{Key: resp.NewBulkString("type"), Value: resp.NewBulkString("range")},
+			{Key: resp.NewBulkString("spec"), Value: resp.Map{Entries: []resp.MapEntry{
+				{Key: resp.NewBulkString("lastkey"), Value: resp.Integer{Value: int64(lastKey)}},
+				{Key: resp.NewBulkString("keystep"), Value: resp.Integer{Value: int64(max(meta.Keys.Step, 1))}},
+				{Key: resp.NewBulkString("limit"), Value: resp.Integer{Value: 0}},
+			}}},
+		}}},
+	}}
+}
+
+// keyFlags are the access flags of the keys of a command
+func keyFlags(meta Metadata) []string {
+	if meta.Flags&FlagWrite != 0 {
+		return []string{"RW", "UPDATE"}
+	}
+	return []string{"RO", "ACCESS"}
+}
+
+// handleDocs documents the named commands, or every command if names is
+// empty. Commands are named as clients call them, and unknown or disabled
+// ones are left out.
+func (c *CommandCommand) handleDocs(names [][]byte) resp.RedisValue {
+	if len(names) == 0 {
+		for _, name := range c.registry.Names() {
+			names = append(names, []byte(name))
+		}
+	}
+
+	var entries []resp.MapEntry
+	for _, name := range names {
+		meta, ok := c.registry.Metadata(string(name))
+		if !ok {
+			continue
+		}
+		lower := strings.ToLower(string(name))
+		entries = append(entries, resp.MapEntry{
+			Key:   resp.NewBulkString(lower),
+			Value: commandDocs(lower, meta, meta.Group),
+		})
+	}
+	return resp.Map{Entries: entries}
+}
+
+// commandDocs is the COMMAND DOCS reply for a command or subcommand
+func commandDocs(name string, meta Metadata, group string) resp.RedisValue {
+	entries := []resp.MapEntry{
+		{Key: resp.NewBulkString("summary"), Value: resp.NewBulkString(meta.Summary)},
+		{Key: resp.NewBulkString("since"), Value: resp.NewBulkString(meta.Since)},
+		{Key: resp.NewBulkString("group"), Value: resp.NewBulkString(group)},
+	}
+
+	if meta.Subcommands != nil {
+		var subcommands []resp.MapEntry
+		for _, sub := range sortedSubcommands(meta) {
+			subcommands = append(subcommands, resp.MapEntry{
+				Key:   resp.NewBulkString(name + "|" + sub),
+				Value: commandDocs(name+"|"+sub, meta.Subcommands[sub], group),
+			})
+		}
+		entries = append(entries, resp.MapEntry{
+			Key:   resp.NewBulkString("subcommands"),
+			Value: resp.Map{Entries: subcommands},
+		})
+	}
+	return resp.Map{Entries: entries}
+}
+
+// handleList lists the names clients call the commands and their
+// subcommands by, optionally filtered by FILTERBY MODULE, ACLCAT or PATTERN
+func (c *CommandCommand) handleList(args [][]byte) resp.RedisValue {
+	match := func(name string, meta Metadata) bool { return true }
+	switch {
+	case len(args) == 0:
+	case len(args) == 3 && strings.EqualFold(string(args[0]), "FILTERBY"):
+		filter := string(args[2])
+		switch strings.ToUpper(string(args[1])) {
+		case "MODULE":
+			// No modules are loaded, so no command belongs to one
+			match = func(string, Metadata) bool { return false }
+		case "ACLCAT":
+			category, ok := acl.ParseCategory(filter)
+			match = func(_ string, meta Metadata) bool {
+				return ok && meta.Categories&category != 0
+			}
+		case "PATTERN":
+			match = func(name string, _ Metadata) bool {
+				return pattern.Match(filter, name)
+			}
+		default:
+			return resp.Error{Value: "ERR syntax error"}
+		}
+	default:
+		return resp.Error{Value: "ERR syntax error"}
+	}
+
+	var values []resp.RedisValue
+	for _, name := range c.registry.Names() {
+		meta, _ := c.registry.Metadata(name)
+		if match(name, meta) {
+			values = append(values, resp.NewBulkString(name))
+		}
+		for _, sub := range sortedSubcommands(meta) {
+			if match(name+"|"+sub, meta.Subcommands[sub]) {
+				values = append(values, resp.NewBulkString(name+"|"+sub))
+			}
+		}
+	}
+	return resp.Array{Values: values}
+}
+
+// handleGetKeys extracts the keys from a full command, named as clients
+// call it, along with their access flags if withFlags is set
+func (c *CommandCommand) handleGetKeys(args [][]byte, withFlags bool) resp.RedisValue {
+	meta, ok := c.registry.Metadata(string(args[0]))
+	if !ok {
+		return resp.Error{Value: "ERR Invalid command specified"}
+	}
+	if meta.CheckArity(args) != nil {
+		return resp.Error{Value: "ERR Invalid number of arguments specified for command"}
+	}
+	if sub, ok := meta.Subcommand(args); ok {
+		meta = sub
+	}
+
+	keys := meta.Keys.Args(args)
+	if len(keys) == 0 {
+		return resp.Error{Value: "ERR The command has no key arguments"}
+	}
+
+	flags := keyFlags(meta)
+	values := make([]resp.RedisValue, len(keys))
+	for i, key := range keys {
+		values[i] = resp.BulkString{Value: key}
+		if withFlags {
+			flagValues := make([]resp.RedisValue, len(flags))
+			for j, flag := range flags {
+				flagValues[j] = resp.SimpleString{Value: flag}
+			}
+			values[i] = resp.Array{Values: []resp.RedisValue{values[i], resp.Set{Values: flagValues}}}
+		}
+	}
+	return resp.Array{Values: values}
+}
+
+// sortedSubcommands returns the names of the subcommands of a container
+// command, sorted
+func sortedSubcommands(meta Metadata) []string {
+	names := make([]string, 0, len(meta.Subcommands))
+	for name := range meta.Subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,221 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// newCommandRegistry returns a registry with a few commands, including a
+// container, and the COMMAND command describing them
+func newCommandRegistry(t *testing.T) (*DefaultRegistry, *CommandCommand) {
+	t.Helper()
+	r := NewRegistry()
+	command := NewCommandCommand(r)
+	for _, handler := range []Handler{&PingCommand{}, NewGetCommand(nil), NewSetCommand(nil), NewConfigCommand(nil), command} {
+		if err := r.Register(handler); err != nil {
+			t.Fatalf("Register(%s) = %v", handler.Name(), err)
+		}
+	}
+	return r, command
+}
+
+// keyFlagSet is the set of key access flags in COMMAND replies
+func keyFlagSet(flags ...string) resp.Set {
+	values := make([]resp.RedisValue, len(flags))
+	for i, flag := range flags {
+		values[i] = resp.SimpleString{Value: flag}
+	}
+	return resp.Set{Values: values}
+}
+
+func TestCommandCommand(t *testing.T) {
+	_, command := newCommandRegistry(t)
+
+	tests := []struct {
+		name string
+		args []string
+		want resp.RedisValue
+	}{
+		{"count", []string{"COUNT"}, resp.Integer{Value: 5}},
+		{"list", []string{"LIST"}, bulks(
+			"command", "command|count", "command|docs", "command|getkeys", "command|getkeysandflags", "command|info", "command|list",
+			"config", "config|get", "config|set", "get", "ping", "set",
+		)},
+		{"list by pattern", []string{"LIST", "FILTERBY", "PATTERN", "config*"}, bulks("config", "config|get", "config|set")},
+		{"list by pattern matching subcommands", []string{"LIST", "filterby", "pattern", "*|get*"}, bulks("command|getkeys", "command|getkeysandflags", "config|get")},
+		{"list by category", []string{"LIST", "FILTERBY", "ACLCAT", "string"}, bulks("get", "set")},
+		{"list by container category", []string{"LIST", "FILTERBY", "ACLCAT", "dangerous"}, bulks("config", "config|get", "config|set")},
+		{"list by unknown category", []string{"LIST", "FILTERBY", "ACLCAT", "nosuch"}, resp.Array{}},
+		{"list by module", []string{"LIST", "FILTERBY", "MODULE", "json"}, resp.Array{}},
+		{"list by unknown filter", []string{"LIST", "FILTERBY", "NAME", "get"}, resp.Error{Value: "ERR syntax error"}},
+		{"list without filter", []string{"LIST", "FILTERBY"}, resp.Error{Value: "ERR syntax error"}},
+		{"info unknown", []string{"INFO", "nosuch"}, resp.Array{Values: []resp.RedisValue{resp.Null{}}}},
+		{"docs unknown", []string{"DOCS", "nosuch"}, resp.Map{}},
+		{"getkeys", []string{"GETKEYS", "SET", "key", "value"}, bulks("key")},
+		{"getkeysandflags read", []string{"GETKEYSANDFLAGS", "get", "key"}, resp.Array{Values: []resp.RedisValue{
+			resp.Array{Values: []resp.RedisValue{resp.NewBulkString("key"), keyFlagSet("RO", "ACCESS")}},
+		}}},
+		{"getkeysandflags write", []string{"GETKEYSANDFLAGS", "set", "key", "value"}, resp.Array{Values: []resp.RedisValue{
+			resp.Array{Values: []resp.RedisValue{resp.NewBulkString("key"), keyFlagSet("RW", "UPDATE")}},
+		}}},
+		{"getkeys unknown command", []string{"GETKEYS", "nosuch", "key"}, resp.Error{Value: "ERR Invalid command specified"}},
+		{"getkeys wrong arity", []string{"GETKEYS", "get", "a", "b"}, resp.Error{Value: "ERR Invalid number of arguments specified for command"}},
+		{"getkeys wrong subcommand arity", []string{"GETKEYS", "config", "get"}, resp.Error{Value: "ERR Invalid number of arguments specified for command"}},
+		{"getkeys without keys", []string{"GETKEYS", "ping", "hello"}, resp.Error{Value: "ERR The command has no key arguments"}},
+		{"getkeys subcommand without keys", []string{"GETKEYS", "config", "get", "port"}, resp.Error{Value: "ERR The command has no key arguments"}},
+		{"unknown subcommand", []string{"NOSUCH"}, resp.Error{Value: "ERR unknown subcommand 'nosuch'. Try COMMAND HELP."}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertReply(t, command.Execute(bytesArgs(tt.args...)), tt.want)
+		})
+	}
+}
+
+// COMMAND INFO follows the Redis reply shape: name, arity, flags, first,
+// last and step key, ACL categories, tips, key specs and subcommands
+func TestCommandInfo(t *testing.T) {
+	_, command := newCommandRegistry(t)
+
+	get := resp.Array{Values: []resp.RedisValue{
+		resp.NewBulkString("get"),
+		resp.Integer{Value: 2},
+		resp.Set{Values: []resp.RedisValue{resp.SimpleString{Value: "readonly"}, resp.SimpleString{Value: "fast"}}},
+		resp.Integer{Value: 1},
+		resp.Integer{Value: 1},
+		resp.Integer{Value: 1},
+		resp.Set{Values: []resp.RedisValue{
+			resp.SimpleString{Value: "@read"}, resp.SimpleString{Value: "@string"}, resp.SimpleString{Value: "@fast"},
+		}},
+		resp.Array{Values: []resp.RedisValue{}},
+		resp.Array{Values: []resp.RedisValue{resp.Map{Entries: []resp.MapEntry{
+			{Key: resp.NewBulkString("flags"), Value: keyFlagSet("RO", "ACCESS")},
+			{Key: resp.NewBulkString("begin_search"), Value: resp.Map{Entries: []resp.MapEntry{
+				{Key: resp.NewBulkString("type"), Value: resp.NewBulkString("index")},
+				{Key: resp.NewBulkString("spec"), Value: resp.Map{Entries: []resp.MapEntry{
+					{Key: resp.NewBulkString("index"), Value: resp.Integer{Value: 1}},
+				}}},
+			}}},
+			{Key: resp.NewBulkString("find_keys"), Value: resp.Map{Entries: []resp.MapEntry{
+				{Key: resp.NewBulkString("type"), Value: resp.NewBulkString("range")},
+				{Key: resp.NewBulkString("spec"), Value: resp.Map{Entries: []resp.MapEntry{
+					{Key: resp.NewBulkString("lastkey"), Value: resp.Integer{Value: 0}},
+					{Key: resp.NewBulkString("keystep"), Value: resp.Integer{Value: 1}},
+					{Key: resp.NewBulkString("limit"), Value: resp.Integer{Value: 0}},
+				}}},
+			}}},
+		}}}},
+		resp.Array{},
+	}}
+	assertReply(t, command.Execute(bytesArgs("INFO", "GET")), resp.Array{Values: []resp.RedisValue{get}})
+
+	reply, ok := command.Execute(bytesArgs("INFO", "config")).(resp.Array)
+	if !ok || len(reply.Values) != 1 {
+		t.Fatalf("COMMAND INFO config = %#v", reply)
+	}
+	info := reply.Values[0].(resp.Array)
+	assertReply(t, info.Values[0], resp.NewBulkString("config"))
+	assertReply(t, info.Values[1], resp.Integer{Value: -2})
+
+	subcommands := info.Values[9].(resp.Array)
+	wantSubcommands := []struct {
+		name  string
+		arity int64
+	}{
+		{"config|get", -3},
+		{"config|set", -4},
+	}
+	if len(subcommands.Values) != len(wantSubcommands) {
+		t.Fatalf("config subcommands = %d, want %d", len(subcommands.Values), len(wantSubcommands))
+	}
+	for i, want := range wantSubcommands {
+		sub := subcommands.Values[i].(resp.Array)
+		assertReply(t, sub.Values[0], resp.NewBulkString(want.name))
+		assertReply(t, sub.Values[1], resp.Integer{Value: want.arity})
+	}
+
+	// Without arguments every command is described, in name order
+	all := command.Execute(nil).(resp.Array)
+	names := make([]resp.RedisValue, len(all.Values))
+	for i, value := range all.Values {
+		names[i] = value.(resp.Array).Values[0]
+	}
+	assertReply(t, resp.Array{Values: names}, bulks("command", "config", "get", "ping", "set"))
+}
+
+func TestCommandDocs(t *testing.T) {
+	r, command := newCommandRegistry(t)
+	meta, _ := r.Metadata("config")
+
+	doc := func(summary, since string) []resp.MapEntry {
+		return []resp.MapEntry{
+			{Key: resp.NewBulkString("summary"), Value: resp.NewBulkString(summary)},
+			{Key: resp.NewBulkString("since"), Value: resp.NewBulkString(since)},
+			{Key: resp.NewBulkString("group"), Value: resp.NewBulkString("server")},
+		}
+	}
+	get, set := meta.Subcommands["get"], meta.Subcommands["set"]
+	config := append(doc(meta.Summary, meta.Since), resp.MapEntry{
+		Key: resp.NewBulkString("subcommands"),
+		Value: resp.Map{Entries: []resp.MapEntry{
+			{Key: resp.NewBulkString("config|get"), Value: resp.Map{Entries: doc(get.Summary, get.Since)}},
+			{Key: resp.NewBulkString("config|set"), Value: resp.Map{Entries: doc(set.Summary, set.Since)}},
+		}},
+	})
+
+	// Unknown commands are left out rather than reported as null
+	assertReply(t, command.Execute(bytesArgs("DOCS", "CONFIG", "nosuch")), resp.Map{Entries: []resp.MapEntry{
+		{Key: resp.NewBulkString("config"), Value: resp.Map{Entries: config}},
+	}})
+}
+
+// Renamed commands are described by their new name, as GETKEYS takes
+// them, and disabled commands aren't described at all
+func TestCommandRenamed(t *testing.T) {
+	r, command := newCommandRegistry(t)
+	if err := r.Rename("GET", "FETCH"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Rename("CONFIG", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want resp.RedisValue
+	}{
+		{"count", []string{"COUNT"}, resp.Integer{Value: 4}},
+		{"list by category", []string{"LIST", "FILTERBY", "ACLCAT", "string"}, bulks("fetch", "set")},
+		{"list disabled", []string{"LIST", "FILTERBY", "PATTERN", "config*"}, resp.Array{}},
+		{"info by old name", []string{"INFO", "GET"}, resp.Array{Values: []resp.RedisValue{resp.Null{}}}},
+		{"info disabled", []string{"INFO", "CONFIG"}, resp.Array{Values: []resp.RedisValue{resp.Null{}}}},
+		{"docs by old name", []string{"DOCS", "GET"}, resp.Map{}},
+		{"docs disabled", []string{"DOCS", "CONFIG"}, resp.Map{}},
+		{"getkeys", []string{"GETKEYS", "FETCH", "key"}, bulks("key")},
+		{"getkeys by old name", []string{"GETKEYS", "GET", "key"}, resp.Error{Value: "ERR Invalid command specified"}},
+		{"getkeys disabled", []string{"GETKEYS", "CONFIG", "GET", "port"}, resp.Error{Value: "ERR Invalid command specified"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertReply(t, command.Execute(bytesArgs(tt.args...)), tt.want)
+		})
+	}
+
+	reply := command.Execute(bytesArgs("INFO", "fetch")).(resp.Array)
+	assertReply(t, reply.Values[0].(resp.Array).Values[0], resp.NewBulkString("fetch"))
+	docs := command.Execute(bytesArgs("DOCS", "FETCH")).(resp.Map)
+	if len(docs.Entries) != 1 {
+		t.Fatalf("COMMAND DOCS FETCH = %#v", docs)
+	}
+	assertReply(t, docs.Entries[0].Key, resp.NewBulkString("fetch"))
+
+	var names []resp.RedisValue
+	for _, info := range command.Execute(nil).(resp.Array).Values {
+		names = append(names, info.(resp.Array).Values[0])
+	}
+	assertReply(t, resp.Array{Values: names}, bulks("command", "fetch", "ping", "set"))
+}
@@ -4,12 +4,16 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/pattern"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 )
 
 // ConfigProvider defines an interface for accessing configuration values
 type ConfigProvider interface {
+	Keys() []string
 	GetString(key string) (string, bool)
+	CheckString(key, value string) error
+	SetString(key, value string) error
 }
 
 // ConfigCommand implements the CONFIG command
@@ -17,6 +21,12 @@ type ConfigCommand struct {
 	config ConfigProvider
 }
 
+// Ensure ConfigCommand implements Handler and Describer
+var (
+	_ Handler   = (*ConfigCommand)(nil)
+	_ Describer = (*ConfigCommand)(nil)
+)
+
 // NewConfigCommand creates a new CONFIG command handler
 func NewConfigCommand(config ConfigProvider) *ConfigCommand {
 	return &ConfigCommand{config: config}
@@ -26,49 +36,90 @@ func (c *ConfigCommand) Name() string {
 	return "CONFIG"
 }
 
-func (c *ConfigCommand) Execute(args []string) resp.RedisValue {
-	if len(args) < 1 {
-		return resp.Error{Value: "ERR wrong number of arguments for 'config' command"}
+func (c *ConfigCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -2, Categories: adminCategories,
+		Summary: "A container for server configuration commands.", Since: "2.0.0", Group: "server",
+		Subcommands: map[string]Metadata{
+			"get": {Arity: -3, Flags: adminFlags, Categories: adminCategories, Summary: "Returns the effective values of configuration parameters.", Since: "2.0.0"},
+			"set": {Arity: -4, Flags: adminFlags, Categories: adminCategories, Summary: "Sets configuration parameters in-flight.", Since: "2.0.0"},
+		},
 	}
+}
 
-	subcommand := strings.ToUpper(args[0])
+func (c *ConfigCommand) Execute(args [][]byte) resp.RedisValue {
+	subcommand := strings.ToUpper(string(args[0]))
 	switch subcommand {
 	case "GET":
-		if len(args) != 2 {
-			return resp.Error{Value: "ERR wrong number of arguments for 'config get' command"}
+		return c.handleConfigGet(args[1:])
+	case "SET":
+		if len(args)%2 != 1 {
+			return wrongArgs("config|set")
 		}
-		return c.handleConfigGet(args[1])
+		return c.handleConfigSet(args[1:])
 	default:
 		return resp.Error{Value: fmt.Sprintf("ERR Unknown CONFIG subcommand: %s", args[0])}
 	}
 }
 
-func (c *ConfigCommand) handleConfigGet(pattern string) resp.RedisValue {
-	// If pattern is "*", return all config values
-	if pattern == "*" {
-		// For this simple implementation, we'll just check a few predefined keys
-		keys := []string{"dir", "dbfilename", "port"}
-		values := make([]resp.RedisValue, 0, len(keys)*2)
-
-		for _, key := range keys {
-			value, found := c.config.GetString(key)
-			if found {
-				values = append(values, resp.BulkString{Value: key})
-				values = append(values, resp.BulkString{Value: value})
+// handleConfigGet returns the parameters matching any of the patterns,
+// each once
+func (c *ConfigCommand) handleConfigGet(patterns [][]byte) resp.RedisValue {
+	entries := make([]resp.MapEntry, 0)
+	for _, key := range c.config.Keys() {
+		matched := false
+		for _, pat := range patterns {
+			if pattern.MatchFold(string(pat), key) {
+				matched = true
+				break
 			}
 		}
+		if !matched {
+			continue
+		}
+
+		value, found := c.config.GetString(key)
+		if found {
+			entries = append(entries, resp.MapEntry{
+				Key:   resp.NewBulkString(key),
+				Value: resp.NewBulkString(value),
+			})
+		}
+	}
+
+	return resp.Map{Entries: entries}
+}
+
+// handleConfigSet sets every parameter in pairs, or none of them: all the
+// values are checked before the first is set
+func (c *ConfigCommand) handleConfigSet(pairs [][]byte) resp.RedisValue {
+	seen := make(map[string]bool, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		key := strings.ToLower(string(pairs[i]))
+		if _, found := c.config.GetString(key); !found {
+			return resp.Error{Value: fmt.Sprintf("ERR Unknown option or number of arguments for CONFIG SET - '%s'", pairs[i])}
+		}
+		if seen[key] {
+			return resp.Error{Value: fmt.Sprintf("ERR CONFIG SET failed (possibly related to argument '%s') - duplicate parameter", pairs[i])}
+		}
+		seen[key] = true
 
-		return resp.Array{Values: values}
+		if err := c.config.CheckString(key, string(pairs[i+1])); err != nil {
+			return configSetFailed(pairs[i], err)
+		}
 	}
 
-	// Otherwise, return just the requested value
-	value, found := c.config.GetString(strings.ToLower(pattern))
-	if !found {
-		return resp.Array{Values: []resp.RedisValue{}}
+	for i := 0; i < len(pairs); i += 2 {
+		if err := c.config.SetString(strings.ToLower(string(pairs[i])), string(pairs[i+1])); err != nil {
+			return configSetFailed(pairs[i], err)
+		}
 	}
 
-	return resp.Array{Values: []resp.RedisValue{
-		resp.BulkString{Value: pattern},
-		resp.BulkString{Value: value},
-	}}
+	return resp.SimpleString{Value: "OK"}
+}
+
+// configSetFailed is the error CONFIG SET replies with when the value of
+// key is rejected
+func configSetFailed(key []byte, err error) resp.RedisValue {
+	return resp.Error{Value: fmt.Sprintf("ERR CONFIG SET failed (possibly related to argument '%s') - %v", key, err)}
 }
@@ -0,0 +1,75 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/config"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// configEntries returns the reply of CONFIG GET for alternating keys and
+// values
+func configEntries(pairs ...string) resp.Map {
+	entries := make([]resp.MapEntry, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		entries = append(entries, resp.MapEntry{Key: resp.NewBulkString(pairs[i]), Value: resp.NewBulkString(pairs[i+1])})
+	}
+	return resp.Map{Entries: entries}
+}
+
+func TestConfigGet(t *testing.T) {
+	command := NewConfigCommand(config.NewConfig())
+
+	tests := []struct {
+		name string
+		args []string
+		want resp.RedisValue
+	}{
+		{"single", []string{"GET", "maxclients"}, configEntries("maxclients", "10000")},
+		{"several", []string{"GET", "timeout", "dbfilename"}, configEntries("dbfilename", "dump.rdb", "timeout", "0")},
+		{"overlapping patterns", []string{"GET", "slowlog-*", "slowlog-max-len"}, configEntries("slowlog-log-slower-than", "10000", "slowlog-max-len", "128")},
+		{"no match", []string{"GET", "nosuch"}, resp.Map{Entries: []resp.MapEntry{}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertReply(t, command.Execute(bytesArgs(tt.args...)), tt.want)
+		})
+	}
+}
+
+// CONFIG SET changes either every parameter or none
+func TestConfigSet(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want resp.RedisValue
+		get  resp.RedisValue
+	}{
+		{"all valid", []string{"SET", "timeout", "10", "MAXCLIENTS", "5"}, resp.SimpleString{Value: "OK"}, configEntries("timeout", "10", "maxclients", "5")},
+		{"last invalid", []string{"SET", "timeout", "10", "maxclients", "none"},
+			resp.Error{Value: "ERR CONFIG SET failed (possibly related to argument 'maxclients') - argument must be between 1 and 2147483647"},
+			configEntries("timeout", "0", "maxclients", "10000")},
+		{"last unknown", []string{"SET", "timeout", "10", "nosuch", "1"},
+			resp.Error{Value: "ERR Unknown option or number of arguments for CONFIG SET - 'nosuch'"},
+			configEntries("timeout", "0", "maxclients", "10000")},
+		{"duplicate", []string{"SET", "timeout", "10", "TIMEOUT", "20"},
+			resp.Error{Value: "ERR CONFIG SET failed (possibly related to argument 'TIMEOUT') - duplicate parameter"},
+			configEntries("timeout", "0", "maxclients", "10000")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := config.NewConfig()
+			notified := 0
+			cfg.OnChange("timeout", func(string) { notified++ })
+			command := NewConfigCommand(cfg)
+
+			assertReply(t, command.Execute(bytesArgs(tt.args...)), tt.want)
+			assertReply(t, command.Execute(bytesArgs("GET", "timeout", "maxclients")), tt.get)
+			if _, failed := tt.want.(resp.Error); failed && notified > 0 {
+				t.Error("listeners notified of a failed CONFIG SET")
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage"
+)
+
+// DelCommand implements the DEL command
+type DelCommand struct {
+	store storage.Storage
+}
+
+// Ensure DelCommand implements Handler and Describer
+var (
+	_ Handler   = (*DelCommand)(nil)
+	_ Describer = (*DelCommand)(nil)
+)
+
+func NewDelCommand(store storage.Storage) *DelCommand {
+	return &DelCommand{store: store}
+}
+
+func (c *DelCommand) Name() string {
+	return "DEL"
+}
+
+func (c *DelCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -2, Flags: FlagWrite, Categories: acl.CategoryKeyspace | acl.CategoryWrite | acl.CategorySlow, Keys: allArgs,
+		Summary: "Deletes one or more keys.", Since: "1.0.0", Group: "generic",
+	}
+}
+
+func (c *DelCommand) Execute(args [][]byte) resp.RedisValue {
+	deleted := 0
+	for _, key := range args {
+		if c.store.Delete(string(key)) {
+			deleted++
+		}
+	}
+
+	return resp.Integer{Value: int64(deleted)}
+}
@@ -5,17 +5,23 @@ import "github.com/codecrafters-io/redis-starter-go/internal/resp"
 // EchoCommand implements the ECHO command
 type EchoCommand struct{}
 
-// Ensure EchoCommand implements Handler interface
-var _ Handler = (*EchoCommand)(nil)
+// Ensure EchoCommand implements Handler and Describer
+var (
+	_ Handler   = (*EchoCommand)(nil)
+	_ Describer = (*EchoCommand)(nil)
+)
 
 func (c *EchoCommand) Name() string {
 	return "ECHO"
 }
 
-func (c *EchoCommand) Execute(args []string) resp.RedisValue {
-	if len(args) != 1 {
-		return resp.Error{Value: "ERR wrong number of arguments for 'echo' command"}
+func (c *EchoCommand) Describe() Metadata {
+	return Metadata{
+		Arity: 2, Categories: connCategories,
+		Summary: "Returns the given string.", Since: "1.0.0", Group: "connection",
 	}
+}
 
+func (c *EchoCommand) Execute(args [][]byte) resp.RedisValue {
 	return resp.BulkString{Value: args[0]}
 }
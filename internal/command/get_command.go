@@ -1,6 +1,7 @@
 package command
 
 import (
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 	"github.com/codecrafters-io/redis-starter-go/internal/storage"
 )
@@ -18,18 +19,18 @@ func (c *GetCommand) Name() string {
 	return "GET"
 }
 
-func (c *GetCommand) Execute(args []string) resp.RedisValue {
-	if len(args) != 1 {
-		return resp.Error{Value: "ERR wrong number of arguments for 'get' command"}
+func (c *GetCommand) Describe() Metadata {
+	return Metadata{
+		Arity: 2, Flags: FlagReadOnly, Categories: acl.CategoryRead | acl.CategoryString | acl.CategoryFast, Keys: firstArg,
+		Summary: "Returns the string value of a key.", Since: "1.0.0", Group: "string",
 	}
+}
 
-	key := args[0]
+func (c *GetCommand) Execute(args [][]byte) resp.RedisValue {
+	key := string(args[0])
 	value, exists := c.store.Get(key)
 	if !exists {
-		// The original code returns "$-1\r\n" directly
-		// For compatibility with the CodeCrafters test, we need to use a custom implementation
-		// This creates a custom response that matches exactly what the original code returns
-		return &resp.CustomResponse{Data: []byte("$-1\r\n")}
+		return resp.Null{}
 	}
 
 	return resp.BulkString{Value: value}
@@ -0,0 +1,32 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage/memory"
+)
+
+// Missing values are null, while empty values are empty bulk strings
+func TestGetNullAndEmpty(t *testing.T) {
+	store := memory.NewStore()
+	set, get, echo := NewSetCommand(store), NewGetCommand(store), &EchoCommand{}
+	assertReply(t, set.Execute(bytesArgs("empty", "")), resp.SimpleString{Value: "OK"})
+
+	tests := []struct {
+		name    string
+		handler Handler
+		args    []string
+		want    resp.RedisValue
+	}{
+		{"missing key", get, []string{"missing"}, resp.Null{}},
+		{"empty value", get, []string{"empty"}, resp.NewBulkString("")},
+		{"empty echo", echo, []string{""}, resp.NewBulkString("")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertReply(t, tt.handler.Execute(bytesArgs(tt.args...)), tt.want)
+		})
+	}
+}
@@ -0,0 +1,127 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/auth"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/replication"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// ServerVersion is the Redis version reported to clients
+const ServerVersion = "7.4.0"
+
+// HelloCommand implements the HELLO command, which negotiates the RESP
+// protocol version and optionally authenticates and names the connection
+type HelloCommand struct {
+	replConfig *replication.Config
+	auth       auth.Authenticator
+}
+
+// Ensure HelloCommand implements ClientHandler and Describer
+var (
+	_ ClientHandler = (*HelloCommand)(nil)
+	_ Describer     = (*HelloCommand)(nil)
+)
+
+func NewHelloCommand(replConfig *replication.Config, authenticator auth.Authenticator) *HelloCommand {
+	return &HelloCommand{replConfig: replConfig, auth: authenticator}
+}
+
+func (c *HelloCommand) Name() string {
+	return "HELLO"
+}
+
+func (c *HelloCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -1, Flags: authFlags, Categories: connCategories,
+		Summary: "Handshakes with the Redis server.", Since: "6.0.0", Group: "connection",
+	}
+}
+
+func (c *HelloCommand) Execute(args [][]byte) resp.RedisValue {
+	return resp.Error{Value: "ERR 'hello' requires a client connection"}
+}
+
+func (c *HelloCommand) ExecuteClient(cl *client.Client, args [][]byte) resp.RedisValue {
+	proto := cl.Protocol()
+	if len(args) > 0 {
+		version, err := strconv.ParseInt(string(args[0]), 10, 64)
+		if err != nil {
+			return resp.Error{Value: "ERR Protocol version is not an integer or out of range"}
+		}
+		if version != int64(resp.RESP2) && version != int64(resp.RESP3) {
+			return resp.Error{Value: "NOPROTO unsupported protocol version"}
+		}
+		proto = resp.Protocol(version)
+	}
+
+	// Parse options before applying any of them, so a bad request leaves the
+	// connection untouched
+	var username, password, name string
+	var authenticate, setName bool
+	for i := 1; i < len(args); i++ {
+		remaining := len(args) - i - 1
+		switch option := strings.ToUpper(string(args[i])); {
+		case option == "AUTH" && remaining >= 2:
+			username, password = string(args[i+1]), string(args[i+2])
+			authenticate = true
+			i += 2
+		case option == "SETNAME" && remaining >= 1:
+			name = string(args[i+1])
+			setName = true
+			i++
+		default:
+			return resp.Error{Value: fmt.Sprintf("ERR Syntax error in HELLO option '%s'", args[i])}
+		}
+	}
+
+	if authenticate {
+		if err := c.auth.Authenticate(username, password); err != nil {
+			return resp.Error{Value: err.Error()}
+		}
+	} else if !cl.Authenticated() {
+		return resp.Error{Value: "NOAUTH HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time"}
+	}
+	if setName && !validClientName(name) {
+		return resp.Error{Value: "ERR Client names cannot contain spaces, newlines or special characters."}
+	}
+
+	if authenticate {
+		cl.SetUser(username)
+		cl.SetAuthenticated(true)
+	}
+	if setName {
+		cl.SetName(name)
+	}
+	cl.SetProtocol(proto)
+
+	role := "master"
+	if c.replConfig.Role == "slave" {
+		role = "replica"
+	}
+
+	return resp.Map{Entries: []resp.MapEntry{
+		{Key: resp.NewBulkString("server"), Value: resp.NewBulkString("redis")},
+		{Key: resp.NewBulkString("version"), Value: resp.NewBulkString(ServerVersion)},
+		{Key: resp.NewBulkString("proto"), Value: resp.Integer{Value: int64(proto)}},
+		{Key: resp.NewBulkString("id"), Value: resp.Integer{Value: cl.ID()}},
+		{Key: resp.NewBulkString("mode"), Value: resp.NewBulkString("standalone")},
+		{Key: resp.NewBulkString("role"), Value: resp.NewBulkString(role)},
+		{Key: resp.NewBulkString("modules"), Value: resp.Array{Values: []resp.RedisValue{}}},
+	}}
+}
+
+// validClientName reports whether name only contains printable characters
+// other than space, as Redis requires
+func validClientName(name string) bool {
+	for i := 0; i < len(name); i++ {
+		if name[i] < '!' || name[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
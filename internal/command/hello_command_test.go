@@ -0,0 +1,71 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/replication"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// helloReply is the reply to HELLO for a client speaking proto
+func helloReply(id int64, proto resp.Protocol) resp.Map {
+	return resp.Map{Entries: []resp.MapEntry{
+		{Key: resp.NewBulkString("server"), Value: resp.NewBulkString("redis")},
+		{Key: resp.NewBulkString("version"), Value: resp.NewBulkString(ServerVersion)},
+		{Key: resp.NewBulkString("proto"), Value: resp.Integer{Value: int64(proto)}},
+		{Key: resp.NewBulkString("id"), Value: resp.Integer{Value: id}},
+		{Key: resp.NewBulkString("mode"), Value: resp.NewBulkString("standalone")},
+		{Key: resp.NewBulkString("role"), Value: resp.NewBulkString("master")},
+		{Key: resp.NewBulkString("modules"), Value: resp.Array{Values: []resp.RedisValue{}}},
+	}}
+}
+
+func TestHello(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		authed    bool
+		wantErr   string
+		wantProto resp.Protocol
+		wantUser  string
+		wantName  string
+	}{
+		{"no arguments", nil, true, "", resp.RESP2, "default", ""},
+		{"RESP3", []string{"3"}, true, "", resp.RESP3, "default", ""},
+		{"RESP2", []string{"2"}, true, "", resp.RESP2, "default", ""},
+		{"unsupported version", []string{"4"}, true, "NOPROTO unsupported protocol version", resp.RESP2, "default", ""},
+		{"version not a number", []string{"three"}, true, "ERR Protocol version is not an integer or out of range", resp.RESP2, "default", ""},
+		{"SETNAME", []string{"3", "SETNAME", "app"}, true, "", resp.RESP3, "default", "app"},
+		{"invalid name", []string{"3", "setname", "my app"}, true, "ERR Client names cannot contain spaces, newlines or special characters.", resp.RESP2, "default", ""},
+		{"AUTH", []string{"3", "AUTH", "alice", "secret"}, false, "", resp.RESP3, "alice", ""},
+		{"wrong password", []string{"3", "AUTH", "alice", "nope"}, false, "WRONGPASS invalid username-password pair or user is disabled.", resp.RESP2, "default", ""},
+		{"unauthenticated", []string{"3"}, false, "NOAUTH HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time", resp.RESP2, "default", ""},
+		{"AUTH missing password", []string{"3", "AUTH", "alice"}, true, "ERR Syntax error in HELLO option 'AUTH'", resp.RESP2, "default", ""},
+		{"bad option applies nothing", []string{"3", "SETNAME", "app", "BOGUS"}, true, "ERR Syntax error in HELLO option 'BOGUS'", resp.RESP2, "default", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hello := NewHelloCommand(replication.NewConfig(), fakeAuthenticator{username: "alice", password: "secret"})
+			c := newTestClient(t)
+			c.SetAuthenticated(tt.authed)
+
+			reply := hello.ExecuteClient(c, bytesArgs(tt.args...))
+			if tt.wantErr != "" {
+				assertReply(t, reply, resp.Error{Value: tt.wantErr})
+			} else {
+				assertReply(t, reply, helloReply(c.ID(), tt.wantProto))
+			}
+
+			if c.Protocol() != tt.wantProto {
+				t.Errorf("protocol %d, want %d", c.Protocol(), tt.wantProto)
+			}
+			if c.User() != tt.wantUser {
+				t.Errorf("user %q, want %q", c.User(), tt.wantUser)
+			}
+			if c.Name() != tt.wantName {
+				t.Errorf("name %q, want %q", c.Name(), tt.wantName)
+			}
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package command
+
+import (
+	"net"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/auth"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// bytesArgs converts command arguments to the form handlers take
+func bytesArgs(args ...string) [][]byte {
+	out := make([][]byte, len(args))
+	for i, arg := range args {
+		out[i] = []byte(arg)
+	}
+	return out
+}
+
+// assertReply compares replies by their RESP3 encoding, which tells every
+// type apart
+func assertReply(t *testing.T, got, want resp.RedisValue) {
+	t.Helper()
+	if g, w := string(got.Serialize(resp.RESP3)), string(want.Serialize(resp.RESP3)); g != w {
+		t.Errorf("reply = %q, want %q", g, w)
+	}
+}
+
+// newTestClient returns a client on one end of a pipe nobody reads from
+func newTestClient(t *testing.T) *client.Client {
+	t.Helper()
+	local, remote := net.Pipe()
+	c := client.New(remote, nil)
+	t.Cleanup(func() {
+		c.Close()
+		local.Close()
+	})
+	return c
+}
+
+// fakeAuthenticator accepts a single username and password
+type fakeAuthenticator struct {
+	username, password string
+	required           bool
+}
+
+func (a fakeAuthenticator) Authenticate(username, password string) error {
+	if username != a.username || password != a.password {
+		return auth.ErrWrongPass
+	}
+	return nil
+}
+
+func (a fakeAuthenticator) Required() bool {
+	return a.required
+}
@@ -0,0 +1,95 @@
+package command
+
+import (
+	"math"
+	"strconv"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage"
+)
+
+// IncrCommand implements the INCR, DECR, INCRBY and DECRBY commands
+type IncrCommand struct {
+	store storage.Storage
+	name  string
+	// sign is applied to the increment: 1 for INCR(BY), -1 for DECR(BY)
+	sign int64
+	// hasDelta is set for the BY variants, which take the increment as argument
+	hasDelta bool
+}
+
+// Ensure IncrCommand implements Handler and Describer
+var (
+	_ Handler   = (*IncrCommand)(nil)
+	_ Describer = (*IncrCommand)(nil)
+)
+
+// incrMetadata describes the commands IncrCommand implements, by name
+var incrMetadata = map[string]Metadata{
+	"INCR": {
+		Arity: 2, Flags: FlagWrite | FlagDenyOOM, Categories: acl.CategoryWrite | acl.CategoryString | acl.CategoryFast, Keys: firstArg,
+		Summary: "Increments the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.", Since: "1.0.0", Group: "string",
+	},
+	"DECR": {
+		Arity: 2, Flags: FlagWrite | FlagDenyOOM, Categories: acl.CategoryWrite | acl.CategoryString | acl.CategoryFast, Keys: firstArg,
+		Summary: "Decrements the integer value of a key by one. Uses 0 as initial value if the key doesn't exist.", Since: "1.0.0", Group: "string",
+	},
+	"INCRBY": {
+		Arity: 3, Flags: FlagWrite | FlagDenyOOM, Categories: acl.CategoryWrite | acl.CategoryString | acl.CategoryFast, Keys: firstArg,
+		Summary: "Increments the integer value of a key by a number. Uses 0 as initial value if the key doesn't exist.", Since: "1.0.0", Group: "string",
+	},
+	"DECRBY": {
+		Arity: 3, Flags: FlagWrite | FlagDenyOOM, Categories: acl.CategoryWrite | acl.CategoryString | acl.CategoryFast, Keys: firstArg,
+		Summary: "Decrements a number from the integer value of a key. Uses 0 as initial value if the key doesn't exist.", Since: "1.0.0", Group: "string",
+	},
+}
+
+// NewIncrCommand creates an INCR command handler
+func NewIncrCommand(store storage.Storage) *IncrCommand {
+	return &IncrCommand{store: store, name: "INCR", sign: 1}
+}
+
+// NewDecrCommand creates a DECR command handler
+func NewDecrCommand(store storage.Storage) *IncrCommand {
+	return &IncrCommand{store: store, name: "DECR", sign: -1}
+}
+
+// NewIncrByCommand creates an INCRBY command handler
+func NewIncrByCommand(store storage.Storage) *IncrCommand {
+	return &IncrCommand{store: store, name: "INCRBY", sign: 1, hasDelta: true}
+}
+
+// NewDecrByCommand creates a DECRBY command handler
+func NewDecrByCommand(store storage.Storage) *IncrCommand {
+	return &IncrCommand{store: store, name: "DECRBY", sign: -1, hasDelta: true}
+}
+
+func (c *IncrCommand) Name() string {
+	return c.name
+}
+
+func (c *IncrCommand) Describe() Metadata {
+	return incrMetadata[c.name]
+}
+
+func (c *IncrCommand) Execute(args [][]byte) resp.RedisValue {
+	delta := int64(1)
+	if c.hasDelta {
+		n, err := strconv.ParseInt(string(args[1]), 10, 64)
+		if err != nil {
+			return resp.Error{Value: "ERR value is not an integer or out of range"}
+		}
+		if c.sign < 0 && n == math.MinInt64 {
+			return resp.Error{Value: "ERR decrement would overflow"}
+		}
+		delta = n
+	}
+
+	value, err := c.store.IncrBy(string(args[0]), c.sign*delta)
+	if err != nil {
+		return resp.Error{Value: "ERR " + err.Error()}
+	}
+
+	return resp.Integer{Value: value}
+}
@@ -0,0 +1,73 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage/memory"
+)
+
+// Each step runs against the store left by the previous ones
+func TestIntegerReplies(t *testing.T) {
+	store := memory.NewStore()
+	var (
+		set    = NewSetCommand(store)
+		get    = NewGetCommand(store)
+		incr   = NewIncrCommand(store)
+		decr   = NewDecrCommand(store)
+		incrBy = NewIncrByCommand(store)
+		decrBy = NewDecrByCommand(store)
+		del    = NewDelCommand(store)
+		ttl    = NewTTLCommand(store)
+		pttl   = NewPTTLCommand(store)
+	)
+
+	steps := []struct {
+		name    string
+		handler Handler
+		args    []string
+		want    resp.RedisValue
+	}{
+		{"INCR creates the counter", incr, []string{"n"}, resp.Integer{Value: 1}},
+		{"INCRBY", incrBy, []string{"n", "41"}, resp.Integer{Value: 42}},
+		{"DECR", decr, []string{"n"}, resp.Integer{Value: 41}},
+		{"DECRBY negative", decrBy, []string{"n", "-9"}, resp.Integer{Value: 50}},
+		{"counter read as string", get, []string{"n"}, resp.NewBulkString("50")},
+		{"INCRBY not a number", incrBy, []string{"n", "x"}, resp.Error{Value: "ERR value is not an integer or out of range"}},
+		{"DECRBY min int64", decrBy, []string{"n", "-9223372036854775808"}, resp.Error{Value: "ERR decrement would overflow"}},
+		{"SET max int64", set, []string{"max", "9223372036854775807"}, resp.SimpleString{Value: "OK"}},
+		{"INCR overflow", incr, []string{"max"}, resp.Error{Value: "ERR increment or decrement would overflow"}},
+		{"SET not a counter", set, []string{"s", "abc"}, resp.SimpleString{Value: "OK"}},
+		{"INCR not a counter", incr, []string{"s"}, resp.Error{Value: "ERR value is not an integer or out of range"}},
+		{"TTL without expiry", ttl, []string{"s"}, resp.Integer{Value: -1}},
+		{"TTL missing key", ttl, []string{"missing"}, resp.Integer{Value: -2}},
+		{"SET PX", set, []string{"px", "v", "PX", "100000"}, resp.SimpleString{Value: "OK"}},
+		{"TTL rounds to seconds", ttl, []string{"px"}, resp.Integer{Value: 100}},
+		{"DEL counts deleted keys", del, []string{"n", "s", "missing", "n"}, resp.Integer{Value: 2}},
+		{"PTTL deleted key", pttl, []string{"n"}, resp.Integer{Value: -2}},
+	}
+
+	for _, step := range steps {
+		assertReply(t, step.handler.Execute(bytesArgs(step.args...)), step.want)
+		if t.Failed() {
+			t.Fatalf("step %q failed", step.name)
+		}
+	}
+}
+
+// Values are stored as given and never alias the arguments they came from
+func TestBinarySafeValues(t *testing.T) {
+	store := memory.NewStore()
+	set, get := NewSetCommand(store), NewGetCommand(store)
+
+	key := "bin\x00key"
+	value := []byte("\x08\x96\x01\x00\xff\r\n$-1\r\n")
+	args := [][]byte{[]byte(key), append([]byte(nil), value...)}
+	assertReply(t, set.Execute(args), resp.SimpleString{Value: "OK"})
+
+	// The parser reuses its buffer for the next command
+	for i := range args[1] {
+		args[1][i] = 'x'
+	}
+	assertReply(t, get.Execute([][]byte{[]byte(key)}), resp.BulkString{Value: value})
+}
@@ -1,46 +1,74 @@
 package command
 
 import (
-	"fmt"
 	"strings"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 )
 
-// InfoCommand implements the INFO command
-type InfoCommand struct {
-	config ReplicationInfo
+// InfoSection is a section of the INFO reply, such as "stats" or
+// "replication". Info returns the section text including its "# Title" line.
+type InfoSection struct {
+	Name string
+	Info func() string
 }
 
-// ReplicationInfo provides replication information for INFO command
-type ReplicationInfo interface {
-	GetReplicationInfo() string
+// InfoCommand implements the INFO command
+type InfoCommand struct {
+	sections []InfoSection
 }
 
-// Ensure InfoCommand implements Handler
-var _ Handler = (*InfoCommand)(nil)
+// Ensure InfoCommand implements Handler and Describer
+var (
+	_ Handler   = (*InfoCommand)(nil)
+	_ Describer = (*InfoCommand)(nil)
+)
 
-func NewInfoCommand(config ReplicationInfo) *InfoCommand {
-	return &InfoCommand{config: config}
+// NewInfoCommand creates an INFO command handler reporting sections in order
+func NewInfoCommand(sections ...InfoSection) *InfoCommand {
+	return &InfoCommand{sections: sections}
 }
 
 func (c *InfoCommand) Name() string {
 	return "INFO"
 }
 
-func (c *InfoCommand) Execute(args []string) resp.RedisValue {
-	// For now, only handle replication info
-	section := ""
-	if len(args) > 0 {
-		section = strings.ToLower(args[0])
+func (c *InfoCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -1, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow | acl.CategoryDangerous,
+		Summary: "Returns information and statistics about the server.", Since: "1.0.0", Group: "server",
+	}
+}
+
+// nonDefaultSections are only reported when asked for by name, or with all
+// or everything
+var nonDefaultSections = map[string]bool{"commandstats": true}
+
+func (c *InfoCommand) Execute(args [][]byte) resp.RedisValue {
+	// Without arguments, or with default, report the default sections; with
+	// all or everything, every section; otherwise only the requested ones,
+	// ignoring unknown names
+	wanted := make(map[string]bool, len(args))
+	defaults, all := len(args) == 0, false
+	for _, arg := range args {
+		name := strings.ToLower(string(arg))
+		switch name {
+		case "default":
+			defaults = true
+		case "all", "everything":
+			all = true
+		default:
+			wanted[name] = true
+		}
 	}
 
-	var info string
-	if section == "" || section == "replication" {
-		info = c.config.GetReplicationInfo()
-	} else {
-		info = fmt.Sprintf("# %s\r\n", strings.Title(section))
+	var parts []string
+	for _, section := range c.sections {
+		if all || wanted[section.Name] || (defaults && !nonDefaultSections[section.Name]) {
+			parts = append(parts, section.Info())
+		}
 	}
 
-	return resp.BulkString{Value: info}
+	return resp.VerbatimString{Format: "txt", Value: []byte(strings.Join(parts, "\n"))}
 }
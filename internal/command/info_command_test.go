@@ -0,0 +1,39 @@
+package command
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+func TestInfo(t *testing.T) {
+	section := func(name string) InfoSection {
+		return InfoSection{Name: name, Info: func() string { return "# " + name }}
+	}
+	command := NewInfoCommand(section("server"), section("stats"), section("commandstats"), section("replication"))
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"no arguments", nil, []string{"server", "stats", "replication"}},
+		{"default", []string{"default"}, []string{"server", "stats", "replication"}},
+		{"all", []string{"all"}, []string{"server", "stats", "commandstats", "replication"}},
+		{"everything", []string{"EVERYTHING"}, []string{"server", "stats", "commandstats", "replication"}},
+		{"by name", []string{"commandstats"}, []string{"commandstats"}},
+		{"default and by name", []string{"default", "commandstats"}, []string{"server", "stats", "commandstats", "replication"}},
+		{"several in section order", []string{"replication", "Server", "nosuch"}, []string{"server", "replication"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := make([]string, len(tt.want))
+			for i, name := range tt.want {
+				want[i] = "# " + name
+			}
+			assertReply(t, command.Execute(bytesArgs(tt.args...)), resp.VerbatimString{Format: "txt", Value: []byte(strings.Join(want, "\n"))})
+		})
+	}
+}
@@ -1,6 +1,7 @@
 package command
 
 import (
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 	"github.com/codecrafters-io/redis-starter-go/internal/storage"
 )
@@ -10,8 +11,11 @@ type KeysCommand struct {
 	store storage.Storage
 }
 
-// Ensure KeysCommands implements Handler
-var _ (Handler) = (*KeysCommand)(nil)
+// Ensure KeysCommand implements Handler and Describer
+var (
+	_ Handler   = (*KeysCommand)(nil)
+	_ Describer = (*KeysCommand)(nil)
+)
 
 func NewKeysCommand(store storage.Storage) *KeysCommand {
 	return &KeysCommand{store: store}
@@ -21,12 +25,15 @@ func (c *KeysCommand) Name() string {
 	return "KEYS"
 }
 
-func (c *KeysCommand) Execute(args []string) resp.RedisValue {
-	if len(args) != 1 {
-		return resp.Error{Value: "ERR wrong number of arguments for 'keys' command"}
+func (c *KeysCommand) Describe() Metadata {
+	return Metadata{
+		Arity: 2, Flags: FlagReadOnly, Categories: acl.CategoryKeyspace | acl.CategoryRead | acl.CategorySlow | acl.CategoryDangerous,
+		Summary: "Returns all key names that match a pattern.", Since: "1.0.0", Group: "generic",
 	}
+}
 
-	pattern := args[0]
+func (c *KeysCommand) Execute(args [][]byte) resp.RedisValue {
+	pattern := string(args[0])
 	if pattern != "*" {
 		// For simplicity, we only support the "*" pattern for now
 		return resp.Error{Value: "ERR pattern not supported"}
@@ -35,7 +42,7 @@ func (c *KeysCommand) Execute(args []string) resp.RedisValue {
 	keys := c.store.GetKeys()
 	values := make([]resp.RedisValue, len(keys))
 	for i, key := range keys {
-		values[i] = resp.BulkString{Value: key}
+		values[i] = resp.NewBulkString(key)
 	}
 
 	return resp.Array{Values: values}
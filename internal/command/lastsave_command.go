@@ -0,0 +1,36 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// LastSaveCommand implements the LASTSAVE command
+type LastSaveCommand struct {
+	saver Saver
+}
+
+// Ensure LastSaveCommand implements Handler and Describer
+var (
+	_ Handler   = (*LastSaveCommand)(nil)
+	_ Describer = (*LastSaveCommand)(nil)
+)
+
+func NewLastSaveCommand(saver Saver) *LastSaveCommand {
+	return &LastSaveCommand{saver: saver}
+}
+
+func (c *LastSaveCommand) Name() string {
+	return "LASTSAVE"
+}
+
+func (c *LastSaveCommand) Describe() Metadata {
+	return Metadata{
+		Arity: 1, Flags: FlagLoading | FlagStale, Categories: acl.CategoryAdmin | acl.CategoryFast | acl.CategoryDangerous,
+		Summary: "Returns the Unix timestamp of the last successful save to disk.", Since: "1.0.0", Group: "server",
+	}
+}
+
+func (c *LastSaveCommand) Execute(args [][]byte) resp.RedisValue {
+	return resp.Integer{Value: c.saver.LastSave().Unix()}
+}
@@ -0,0 +1,199 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// Flag describes how a command behaves
+type Flag uint32
+
+const (
+	// FlagWrite marks commands that may modify the dataset
+	FlagWrite Flag = 1 << iota
+	// FlagReadOnly marks commands that only read the dataset
+	FlagReadOnly
+	// FlagDenyOOM marks commands that may grow memory usage, refused when
+	// the server is out of memory
+	FlagDenyOOM
+	// FlagAdmin marks administrative commands
+	FlagAdmin
+	// FlagPubSub marks pub/sub commands
+	FlagPubSub
+	// FlagNoScript marks commands that scripts may not call
+	FlagNoScript
+	// FlagLoading marks commands allowed while the dataset is loading
+	FlagLoading
+	// FlagStale marks commands allowed on a replica with stale data
+	FlagStale
+	// FlagNoAuth marks commands that unauthenticated clients may run
+	FlagNoAuth
+)
+
+// flagNames are the names COMMAND INFO reports flags by, in order
+var flagNames = []struct {
+	flag Flag
+	name string
+}{
+	{FlagWrite, "write"},
+	{FlagReadOnly, "readonly"},
+	{FlagDenyOOM, "denyoom"},
+	{FlagAdmin, "admin"},
+	{FlagPubSub, "pubsub"},
+	{FlagNoScript, "noscript"},
+	{FlagLoading, "loading"},
+	{FlagStale, "stale"},
+	{FlagNoAuth, "no_auth"},
+}
+
+// Names returns the names of the flags in the set
+func (f Flag) Names() []string {
+	var names []string
+	for _, entry := range flagNames {
+		if f&entry.flag != 0 {
+			names = append(names, entry.name)
+		}
+	}
+	return names
+}
+
+// ArgRange locates arguments by position, counting the command name as
+// position 0. A negative Last counts from the end, -1 being the last
+// argument. The zero value locates nothing.
+type ArgRange struct {
+	First int
+	Last  int
+	Step  int
+}
+
+// Args returns the arguments in the range
+func (r ArgRange) Args(args [][]byte) [][]byte {
+	if r.First <= 0 || r.First >= len(args) {
+		return nil
+	}
+
+	last := r.Last
+	if last < 0 {
+		last += len(args)
+	}
+	last = min(last, len(args)-1)
+
+	var out [][]byte
+	for i := r.First; i <= last; i += max(r.Step, 1) {
+		out = append(out, args[i])
+	}
+	return out
+}
+
+// Metadata describes a command declaratively: how many arguments it takes,
+// how it behaves and which of its arguments are keys and channels
+type Metadata struct {
+	// Arity is the number of arguments, including the command name. A
+	// negative arity is a minimum: -2 means at least two.
+	Arity      int
+	Flags      Flag
+	Categories acl.Category
+
+	// Keys, Channels and Patterns locate the keys, pub/sub channels and
+	// channel patterns among the arguments
+	Keys     ArgRange
+	Channels ArgRange
+	Patterns ArgRange
+
+	// Subcommands describes the subcommands of container commands such as
+	// CONFIG, keyed by lowercase name
+	Subcommands map[string]Metadata
+
+	// Summary, Since and Group document the command for COMMAND DOCS.
+	// Subcommands share the group of their container.
+	Summary string
+	Since   string
+	Group   string
+}
+
+// Subcommand returns the metadata of the subcommand named in args, which
+// include the command name, if m describes a container command
+func (m Metadata) Subcommand(args [][]byte) (Metadata, bool) {
+	if m.Subcommands == nil || len(args) < 2 {
+		return Metadata{}, false
+	}
+
+	sub, ok := m.Subcommands[strings.ToLower(string(args[1]))]
+	return sub, ok
+}
+
+// CheckArity returns the error reply for running the command with args,
+// which include the command name, if they don't match the arity of the
+// command or its subcommand. It returns nil otherwise.
+func (m Metadata) CheckArity(args [][]byte) resp.RedisValue {
+	name := strings.ToLower(string(args[0]))
+	if !m.validArity(len(args)) {
+		return wrongArgs(name)
+	}
+	if sub, ok := m.Subcommand(args); ok && !sub.validArity(len(args)) {
+		return wrongArgs(name + "|" + strings.ToLower(string(args[1])))
+	}
+	return nil
+}
+
+// validArity reports whether n arguments match the arity. Commands without
+// a declared arity accept any number.
+func (m Metadata) validArity(n int) bool {
+	if m.Arity < 0 {
+		return n >= -m.Arity
+	}
+	return m.Arity == 0 || n == m.Arity
+}
+
+// wrongArgs is the reply for a command called with the wrong number of
+// arguments
+func wrongArgs(name string) resp.Error {
+	return resp.Error{Value: fmt.Sprintf("ERR wrong number of arguments for '%s' command", name)}
+}
+
+// Request builds the ACL request for running the command with args, which
+// include the command name
+func (m Metadata) Request(args [][]byte) acl.Request {
+	req := acl.Request{Command: strings.ToLower(string(args[0]))}
+	if m.Subcommands != nil && len(args) > 1 {
+		req.Subcommand = strings.ToLower(string(args[1]))
+	}
+	if sub, ok := m.Subcommand(args); ok {
+		m = sub
+	}
+	req.Categories = m.Categories
+
+	for _, key := range m.Keys.Args(args) {
+		req.Keys = append(req.Keys, acl.Key{
+			Name:  string(key),
+			Read:  m.Flags&FlagWrite == 0,
+			Write: m.Flags&FlagWrite != 0,
+		})
+	}
+	for _, channel := range m.Channels.Args(args) {
+		req.Channels = append(req.Channels, string(channel))
+	}
+	for _, pat := range m.Patterns.Args(args) {
+		req.Patterns = append(req.Patterns, string(pat))
+	}
+	return req
+}
+
+// firstArg locates the first argument, e.g. the key of GET
+var firstArg = ArgRange{First: 1, Last: 1, Step: 1}
+
+// allArgs locates every argument after the command name
+var allArgs = ArgRange{First: 1, Last: -1, Step: 1}
+
+const (
+	adminCategories  = acl.CategoryAdmin | acl.CategorySlow | acl.CategoryDangerous
+	connCategories   = acl.CategoryFast | acl.CategoryConnection
+	pubsubCategories = acl.CategoryPubSub | acl.CategorySlow
+
+	adminFlags  = FlagAdmin | FlagNoScript | FlagLoading | FlagStale
+	authFlags   = FlagNoScript | FlagLoading | FlagStale | FlagNoAuth
+	pubsubFlags = FlagPubSub | FlagNoScript | FlagLoading | FlagStale
+)
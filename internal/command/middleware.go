@@ -0,0 +1,174 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/slowlog"
+	"github.com/codecrafters-io/redis-starter-go/internal/stats"
+)
+
+// Context describes a command passing through the middleware chain
+type Context struct {
+	// Client is the client running the command
+	Client *client.Client
+
+	// Args are the arguments, including the command name
+	Args     [][]byte
+	Metadata Metadata
+	Handler  Handler
+
+	// Executed is set once the handler has run. A reply returned while it
+	// is unset was produced by a middleware short-circuiting the command.
+	Executed bool
+}
+
+// FullName returns the lowercase command name, followed by the subcommand
+// for container commands, e.g. "config|get"
+func (ctx *Context) FullName() string {
+	name := strings.ToLower(string(ctx.Args[0]))
+	if _, ok := ctx.Metadata.Subcommand(ctx.Args); ok {
+		name += "|" + strings.ToLower(string(ctx.Args[1]))
+	}
+	return name
+}
+
+// Middleware intercepts commands. It calls next to continue down the chain
+// and may wrap the reply it returns, or it can short-circuit the command by
+// returning a reply of its own without calling next.
+type Middleware func(ctx *Context, next func() resp.RedisValue) resp.RedisValue
+
+// execute runs the handler of ctx, at the end of the middleware chain
+func execute(ctx *Context) resp.RedisValue {
+	ctx.Executed = true
+	args := ctx.Args[1:]
+	if ch, ok := ctx.Handler.(ClientHandler); ok {
+		return ch.ExecuteClient(ctx.Client, args)
+	}
+
+	return ctx.Handler.Execute(args)
+}
+
+// NewStatsMiddleware counts the calls, execution time, rejections and
+// failures of each command
+func NewStatsMiddleware(commandStats *stats.CommandStats) Middleware {
+	return func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+		start := time.Now()
+		reply := next()
+
+		_, failed := reply.(resp.Error)
+		commandStats.Record(ctx.FullName(), time.Since(start), !ctx.Executed, failed)
+		return reply
+	}
+}
+
+// NewSlowLogMiddleware records commands taking longer than threshold to
+// execute. A negative threshold disables the log. Passwords are replaced by
+// "(redacted)" in the recorded arguments.
+func NewSlowLogMiddleware(log *slowlog.Log, threshold func() time.Duration) Middleware {
+	return func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+		start := time.Now()
+		reply := next()
+		elapsed := time.Since(start)
+
+		if limit := threshold(); limit >= 0 && elapsed >= limit {
+			log.Add(redactArgs(ctx), elapsed, ctx.Client.RemoteAddr(), ctx.Client.Name())
+		}
+		return reply
+	}
+}
+
+// redacted replaces the arguments holding passwords in logged commands
+var redacted = []byte("(redacted)")
+
+// redactArgs returns the arguments of ctx with the credentials given to
+// AUTH, HELLO ... AUTH and the password rules of ACL SETUSER redacted. The
+// handler name is used so that renamed commands are redacted too.
+func redactArgs(ctx *Context) [][]byte {
+	args := slices.Clone(ctx.Args)
+	switch ctx.Handler.Name() {
+	case "AUTH":
+		for i := 1; i < len(args); i++ {
+			args[i] = redacted
+		}
+	case "HELLO":
+		for i := 2; i < len(args); i++ {
+			switch strings.ToUpper(string(args[i])) {
+			case "AUTH":
+				for j := i + 1; j < len(args) && j <= i+2; j++ {
+					args[j] = redacted
+				}
+				i += 2
+			case "SETNAME":
+				i++
+			}
+		}
+	case "ACL":
+		if len(args) < 3 || !strings.EqualFold(string(args[1]), "SETUSER") {
+			break
+		}
+		for i := 3; i < len(args); i++ {
+			if len(args[i]) > 0 && strings.ContainsRune("><#!", rune(args[i][0])) {
+				args[i] = redacted
+			}
+		}
+	}
+	return args
+}
+
+// diskErrorReply is the reply to writes refused after a failed save
+const diskErrorReply = "MISCONF Redis is configured to save RDB snapshots, but it's currently unable to persist to disk. " +
+	"Commands that may modify the data set are disabled, because this instance is configured to report errors during writes " +
+	"if RDB snapshotting fails (stop-writes-on-bgsave-error option). Please check the Redis logs for details about the RDB error."
+
+// NewDiskErrorMiddleware refuses writes, and PING so that monitoring notices,
+// while failing reports that the dataset can't be saved
+func NewDiskErrorMiddleware(failing func() bool) Middleware {
+	return func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+		if (ctx.Metadata.Flags&FlagWrite != 0 || ctx.Handler.Name() == "PING") && failing() {
+			return resp.Error{Value: diskErrorReply}
+		}
+		return next()
+	}
+}
+
+// NewACLMiddleware refuses commands the client's user may not run, recording
+// them in the ACL log. Commands that authenticate are always allowed.
+func NewACLMiddleware(a *acl.ACL) Middleware {
+	return func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+		if ctx.Metadata.Flags&FlagNoAuth != 0 {
+			return next()
+		}
+
+		user := ctx.Client.User()
+		err := a.Check(user, ctx.Metadata.Request(ctx.Args))
+		if err == nil {
+			return next()
+		}
+
+		var denied *acl.Denied
+		if !errors.As(err, &denied) {
+			// The user was deleted since the client authenticated
+			ctx.Client.SetAuthenticated(false)
+			return resp.Error{Value: "NOAUTH Authentication required."}
+		}
+
+		a.Log().Add(acl.Entry{
+			Reason:     denied.Reason,
+			Context:    "toplevel",
+			Object:     denied.Object,
+			Username:   user,
+			ClientInfo: ctx.Client.Info(),
+		})
+		if denied.Reason == "command" {
+			return resp.Error{Value: fmt.Sprintf("NOPERM User %s has no permissions to run the '%s' command", user, denied.Object)}
+		}
+		return resp.Error{Value: "NOPERM " + denied.Error()}
+	}
+}
@@ -0,0 +1,287 @@
+package command
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/config"
+	"github.com/codecrafters-io/redis-starter-go/internal/replication"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/slowlog"
+	"github.com/codecrafters-io/redis-starter-go/internal/stats"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage/memory"
+)
+
+// newMiddlewareRegistry returns a registry with commands that reply with
+// and without errors, and one that authenticates
+func newMiddlewareRegistry(t *testing.T) *DefaultRegistry {
+	t.Helper()
+	store := memory.NewStore()
+	r := NewRegistry()
+	authenticator := fakeAuthenticator{username: "default", password: "secret", required: true}
+	for _, handler := range []Handler{&PingCommand{}, NewGetCommand(store), NewSetCommand(store), NewIncrCommand(store), NewConfigCommand(config.NewConfig()), NewAuthCommand(authenticator)} {
+		if err := r.Register(handler); err != nil {
+			t.Fatalf("Register(%s) = %v", handler.Name(), err)
+		}
+	}
+	return r
+}
+
+// Middlewares run in the order they were added, around the handler, and
+// may short-circuit the rest of the chain
+func TestMiddlewareChain(t *testing.T) {
+	refused := resp.Error{Value: "ERR refused"}
+	tests := []struct {
+		name         string
+		refuse       string
+		args         []string
+		want         resp.RedisValue
+		wantCalls    string
+		wantExecuted bool
+	}{
+		{"runs handler", "", []string{"PING"}, resp.SimpleString{Value: "PONG"}, "outer inner", true},
+		{"short-circuits", "ping", []string{"PING"}, refused, "outer", false},
+		{"short-circuits by full name", "config|get", []string{"CONFIG", "GET", "port"}, refused, "outer", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMiddlewareRegistry(t)
+			var calls []string
+			var executed bool
+			r.Use(func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+				calls = append(calls, "outer")
+				reply := next()
+				executed = ctx.Executed
+				return reply
+			})
+			r.Use(func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+				if ctx.FullName() == tt.refuse {
+					return refused
+				}
+				calls = append(calls, "inner")
+				return next()
+			})
+
+			assertReply(t, run(r, newTestClient(t), tt.args...), tt.want)
+			if got := strings.Join(calls, " "); got != tt.wantCalls {
+				t.Errorf("calls = %q, want %q", got, tt.wantCalls)
+			}
+			if executed != tt.wantExecuted {
+				t.Errorf("executed = %v, want %v", executed, tt.wantExecuted)
+			}
+		})
+	}
+}
+
+// A middleware can wrap the reply of the handler
+func TestMiddlewareWrapsReply(t *testing.T) {
+	r := newMiddlewareRegistry(t)
+	r.Use(func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+		if reply, ok := next().(resp.SimpleString); ok {
+			return resp.SimpleString{Value: strings.ToLower(reply.Value)}
+		}
+		return resp.Error{Value: "ERR unexpected reply"}
+	})
+
+	assertReply(t, run(r, newTestClient(t), "PING"), resp.SimpleString{Value: "pong"})
+}
+
+func TestStatsMiddleware(t *testing.T) {
+	r := newMiddlewareRegistry(t)
+	commandStats := stats.NewCommandStats()
+	r.Use(NewStatsMiddleware(commandStats))
+	r.Use(func(ctx *Context, next func() resp.RedisValue) resp.RedisValue {
+		if ctx.FullName() == "config|set" {
+			return resp.Error{Value: "NOPERM refused"}
+		}
+		return next()
+	})
+
+	c := newTestClient(t)
+	for _, args := range [][]string{
+		{"SET", "key", "value"},
+		{"GET", "key"},
+		{"GET", "key"},
+		{"INCR", "key"},
+		{"CONFIG", "SET", "port", "0"},
+	} {
+		run(r, c, args...)
+	}
+
+	// Rejected commands weren't called, while failed ones were
+	want := map[string]string{
+		"get":        "calls=2,",
+		"set":        "calls=1,",
+		"incr":       "calls=1,",
+		"config|set": "calls=0,",
+	}
+	wantCounts := map[string]string{
+		"get":        "rejected_calls=0,failed_calls=0",
+		"set":        "rejected_calls=0,failed_calls=0",
+		"incr":       "rejected_calls=0,failed_calls=1",
+		"config|set": "rejected_calls=1,failed_calls=0",
+	}
+
+	lines := strings.Split(strings.TrimSpace(commandStats.Info()), "\n")
+	if len(lines) != len(want)+1 {
+		t.Fatalf("commandstats = %q", lines)
+	}
+	for _, line := range lines[1:] {
+		name, fields, _ := strings.Cut(strings.TrimPrefix(line, "cmdstat_"), ":")
+		if !strings.HasPrefix(fields, want[name]) || !strings.HasSuffix(fields, wantCounts[name]) {
+			t.Errorf("cmdstat_%s = %q, want %q...%q", name, fields, want[name], wantCounts[name])
+		}
+	}
+}
+
+func TestSlowLogMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold time.Duration
+		args      []string
+		wantLen   int
+	}{
+		{"logs every command", 0, []string{"PING"}, 1},
+		{"logs subcommands", 0, []string{"CONFIG", "GET", "port"}, 1},
+		{"disabled", -1, []string{"PING"}, 0},
+		{"below threshold", time.Hour, []string{"PING"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMiddlewareRegistry(t)
+			log := slowlog.NewLog(128)
+			r.Use(NewSlowLogMiddleware(log, func() time.Duration { return tt.threshold }))
+
+			c := newTestClient(t)
+			c.SetName("worker")
+			run(r, c, tt.args...)
+
+			entries := log.Entries(-1)
+			if len(entries) != tt.wantLen {
+				t.Fatalf("entries = %d, want %d", len(entries), tt.wantLen)
+			}
+			if tt.wantLen == 0 {
+				return
+			}
+			if e := entries[0]; strings.Join(e.Args, " ") != strings.Join(tt.args, " ") || e.ClientName != "worker" || e.ClientAddr != c.RemoteAddr() {
+				t.Errorf("entry = %+v", e)
+			}
+		})
+	}
+}
+
+// Passwords never reach the slow log
+func TestSlowLogRedaction(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"auth", []string{"AUTH", "secret"}, []string{"AUTH", "(redacted)"}},
+		{"auth with username", []string{"AUTH", "alice", "secret"}, []string{"AUTH", "(redacted)", "(redacted)"}},
+		{"hello", []string{"HELLO", "3", "SETNAME", "auth", "AUTH", "alice", "secret"}, []string{"HELLO", "3", "SETNAME", "auth", "AUTH", "(redacted)", "(redacted)"}},
+		{"acl setuser", []string{"ACL", "SETUSER", "alice", "on", ">secret", "~*", "+@all"}, []string{"ACL", "SETUSER", "alice", "on", "(redacted)", "~*", "+@all"}},
+		{"acl setuser hash", []string{"ACL", "SETUSER", "alice", "#" + strings.Repeat("a", 64)}, []string{"ACL", "SETUSER", "alice", "(redacted)"}},
+		{"other commands", []string{"SET", ">secret", "AUTH"}, []string{"SET", ">secret", "AUTH"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, acls := newACLRegistry(t, "")
+			if err := r.Register(NewHelloCommand(replication.NewConfig(), acls)); err != nil {
+				t.Fatal(err)
+			}
+			log := slowlog.NewLog(128)
+			r.Use(NewSlowLogMiddleware(log, func() time.Duration { return 0 }))
+
+			run(r, newTestClient(t), tt.args...)
+			entries := log.Entries(-1)
+			if len(entries) != 1 {
+				t.Fatalf("entries = %d, want 1", len(entries))
+			}
+			if got := strings.Join(entries[0].Args, " "); got != strings.Join(tt.want, " ") {
+				t.Errorf("args = %q, want %q", got, strings.Join(tt.want, " "))
+			}
+		})
+	}
+}
+
+func TestSlowLogCommand(t *testing.T) {
+	log := slowlog.NewLog(128)
+	for _, arg := range []string{"first", "second", "third"} {
+		log.Add(bytesArgs("ECHO", arg), time.Millisecond, "127.0.0.1:6000", "")
+	}
+	slowLog := NewSlowLogCommand(log)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantArgs []string
+		wantErr  string
+	}{
+		{"newest ten", []string{"GET"}, []string{"third", "second", "first"}, ""},
+		{"count", []string{"GET", "2"}, []string{"third", "second"}, ""},
+		{"all", []string{"GET", "-1"}, []string{"third", "second", "first"}, ""},
+		{"negative count", []string{"GET", "-2"}, nil, "ERR count should be greater than or equal to -1"},
+		{"invalid count", []string{"GET", "many"}, nil, "ERR value is not an integer or out of range"},
+		{"unknown subcommand", []string{"NOSUCH"}, nil, "ERR unknown subcommand 'nosuch'. Try SLOWLOG HELP."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reply := slowLog.Execute(bytesArgs(tt.args...))
+			if tt.wantErr != "" {
+				assertReply(t, reply, resp.Error{Value: tt.wantErr})
+				return
+			}
+
+			entries := reply.(resp.Array).Values
+			if len(entries) != len(tt.wantArgs) {
+				t.Fatalf("entries = %d, want %d", len(entries), len(tt.wantArgs))
+			}
+			for i, entry := range entries {
+				fields := entry.(resp.Array).Values
+				assertReply(t, fields[3], bulks("ECHO", tt.wantArgs[i]))
+				assertReply(t, fields[2], resp.Integer{Value: 1000})
+				assertReply(t, fields[4], resp.NewBulkString("127.0.0.1:6000"))
+			}
+		})
+	}
+
+	assertReply(t, slowLog.Execute(bytesArgs("LEN")), resp.Integer{Value: 3})
+	assertReply(t, slowLog.Execute(bytesArgs("RESET")), resp.SimpleString{Value: "OK"})
+	assertReply(t, slowLog.Execute(bytesArgs("LEN")), resp.Integer{Value: 0})
+}
+
+// While saving fails, writes and PING are refused so that clients and
+// monitoring notice, and everything else goes on
+func TestDiskErrorMiddleware(t *testing.T) {
+	misconf := resp.Error{Value: diskErrorReply}
+	tests := []struct {
+		name    string
+		failing bool
+		args    []string
+		want    resp.RedisValue
+	}{
+		{"write", true, []string{"SET", "key", "value"}, misconf},
+		{"counter", true, []string{"INCR", "n"}, misconf},
+		{"ping", true, []string{"PING"}, misconf},
+		{"read", true, []string{"GET", "key"}, resp.Null{}},
+		{"admin", true, []string{"CONFIG", "GET", "save"}, resp.Map{Entries: []resp.MapEntry{
+			{Key: resp.NewBulkString("save"), Value: resp.NewBulkString("3600 1 300 100 60 10000")},
+		}}},
+		{"write after a successful save", false, []string{"SET", "key", "value"}, resp.SimpleString{Value: "OK"}},
+		{"ping after a successful save", false, []string{"PING"}, resp.SimpleString{Value: "PONG"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newMiddlewareRegistry(t)
+			r.Use(NewDiskErrorMiddleware(func() bool { return tt.failing }))
+			assertReply(t, run(r, newTestClient(t), tt.args...), tt.want)
+		})
+	}
+}
@@ -5,16 +5,26 @@ import "github.com/codecrafters-io/redis-starter-go/internal/resp"
 // PingCommand implements the PING command
 type PingCommand struct{}
 
-// Ensure PingCommand implements Handler interface
-var _ Handler = (*PingCommand)(nil)
+// Ensure PingCommand implements Handler and Describer
+var (
+	_ Handler   = (*PingCommand)(nil)
+	_ Describer = (*PingCommand)(nil)
+)
 
 func (c *PingCommand) Name() string {
 	return "PING"
 }
 
-func (c *PingCommand) Execute(args []string) resp.RedisValue {
+func (c *PingCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -1, Categories: connCategories,
+		Summary: "Returns the server's liveliness response.", Since: "1.0.0", Group: "connection",
+	}
+}
+
+func (c *PingCommand) Execute(args [][]byte) resp.RedisValue {
 	if len(args) > 1 {
-		return resp.Error{Value: "ERR wrong number of arguments for 'ping' command"}
+		return wrongArgs("ping")
 	}
 
 	if len(args) == 1 {
@@ -3,6 +3,7 @@ package command
 import (
 	"fmt"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
 	"github.com/codecrafters-io/redis-starter-go/internal/replication"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 )
@@ -12,8 +13,11 @@ type PSyncCommand struct {
 	replConfig replication.Config
 }
 
-// Ensure PSyncCommand implements Handler
-var _ Handler = (*PSyncCommand)(nil)
+// Ensure PSyncCommand implements ClientHandler and Describer
+var (
+	_ ClientHandler = (*PSyncCommand)(nil)
+	_ Describer     = (*PSyncCommand)(nil)
+)
 
 func NewPSyncCommand(replConfig *replication.Config) *PSyncCommand {
 	return &PSyncCommand{replConfig: *replConfig}
@@ -23,11 +27,14 @@ func (c *PSyncCommand) Name() string {
 	return "PSYNC"
 }
 
-func (c *PSyncCommand) Execute(args []string) resp.RedisValue {
-	if len(args) < 2 {
-		return resp.Error{Value: "ERR wrong number of arguments for 'psync' command"}
+func (c *PSyncCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -3, Flags: FlagAdmin | FlagNoScript, Categories: adminCategories,
+		Summary: "An internal command used in replication.", Since: "2.8.0", Group: "server",
 	}
+}
 
+func (c *PSyncCommand) Execute(args [][]byte) resp.RedisValue {
 	// For initial replication, reply with FULLRESYNC
 	response := fmt.Sprintf("FULLRESYNC %s %d",
 		c.replConfig.MasterReplID,
@@ -35,3 +42,14 @@ func (c *PSyncCommand) Execute(args []string) resp.RedisValue {
 
 	return resp.SimpleString{Value: response}
 }
+
+// ExecuteClient runs PSYNC and marks the client as a replica, so the replica
+// output buffer limits apply to it from now on
+func (c *PSyncCommand) ExecuteClient(cl *client.Client, args [][]byte) resp.RedisValue {
+	reply := c.Execute(args)
+	if _, failed := reply.(resp.Error); !failed {
+		cl.SetFlag(client.FlagReplica, true)
+	}
+
+	return reply
+}
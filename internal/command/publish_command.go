@@ -0,0 +1,64 @@
+package command
+
+import (
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// PublishCommand implements the PUBLISH and SPUBLISH commands
+type PublishCommand struct {
+	hub     *pubsub.Hub
+	sharded bool
+}
+
+// Ensure PublishCommand implements Handler and Describer
+var (
+	_ Handler   = (*PublishCommand)(nil)
+	_ Describer = (*PublishCommand)(nil)
+)
+
+// publishMetadata describes the commands PublishCommand implements, by name
+var publishMetadata = map[string]Metadata{
+	"PUBLISH": {
+		Arity: 3, Flags: FlagPubSub | FlagLoading | FlagStale, Categories: acl.CategoryPubSub | acl.CategoryFast, Channels: firstArg,
+		Summary: "Posts a message to a channel.", Since: "2.0.0", Group: "pubsub",
+	},
+	"SPUBLISH": {
+		Arity: 3, Flags: FlagPubSub | FlagLoading | FlagStale, Categories: acl.CategoryPubSub | acl.CategoryFast, Channels: firstArg,
+		Summary: "Post a message to a shard channel", Since: "7.0.0", Group: "pubsub",
+	},
+}
+
+// NewPublishCommand creates a PUBLISH command handler
+func NewPublishCommand(hub *pubsub.Hub) *PublishCommand {
+	return &PublishCommand{hub: hub}
+}
+
+// NewSPublishCommand creates an SPUBLISH command handler. In standalone mode
+// this server owns every slot, so shard messages are delivered locally.
+func NewSPublishCommand(hub *pubsub.Hub) *PublishCommand {
+	return &PublishCommand{hub: hub, sharded: true}
+}
+
+func (c *PublishCommand) Name() string {
+	if c.sharded {
+		return "SPUBLISH"
+	}
+	return "PUBLISH"
+}
+
+func (c *PublishCommand) Describe() Metadata {
+	return publishMetadata[c.Name()]
+}
+
+func (c *PublishCommand) Execute(args [][]byte) resp.RedisValue {
+	var received int
+	if c.sharded {
+		received = c.hub.SPublish(string(args[0]), args[1])
+	} else {
+		received = c.hub.Publish(string(args[0]), args[1])
+	}
+
+	return resp.Integer{Value: int64(received)}
+}
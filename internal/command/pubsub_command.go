@@ -0,0 +1,89 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// PubSubCommand implements the PUBSUB introspection command
+type PubSubCommand struct {
+	hub *pubsub.Hub
+}
+
+// Ensure PubSubCommand implements Handler and Describer
+var (
+	_ Handler   = (*PubSubCommand)(nil)
+	_ Describer = (*PubSubCommand)(nil)
+)
+
+func NewPubSubCommand(hub *pubsub.Hub) *PubSubCommand {
+	return &PubSubCommand{hub: hub}
+}
+
+func (c *PubSubCommand) Name() string {
+	return "PUBSUB"
+}
+
+func (c *PubSubCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -2, Categories: pubsubCategories,
+		Summary: "A container for Pub/Sub commands.", Since: "2.8.0", Group: "pubsub",
+		Subcommands: map[string]Metadata{
+			"channels":      {Arity: -2, Flags: FlagPubSub | FlagLoading | FlagStale, Categories: pubsubCategories, Summary: "Returns the active channels.", Since: "2.8.0"},
+			"numpat":        {Arity: 2, Flags: FlagPubSub | FlagLoading | FlagStale, Categories: pubsubCategories, Summary: "Returns a count of unique pattern subscriptions.", Since: "2.8.0"},
+			"numsub":        {Arity: -2, Flags: FlagPubSub | FlagLoading | FlagStale, Categories: pubsubCategories, Summary: "Returns a count of subscribers to channels.", Since: "2.8.0"},
+			"shardchannels": {Arity: -2, Flags: FlagPubSub | FlagLoading | FlagStale, Categories: pubsubCategories, Summary: "Returns the active shard channels.", Since: "7.0.0"},
+			"shardnumsub":   {Arity: -2, Flags: FlagPubSub | FlagLoading | FlagStale, Categories: pubsubCategories, Summary: "Returns the count of subscribers of shard channels.", Since: "7.0.0"},
+		},
+	}
+}
+
+func (c *PubSubCommand) Execute(args [][]byte) resp.RedisValue {
+	subcommand := strings.ToUpper(string(args[0]))
+	switch subcommand {
+	case "CHANNELS":
+		return c.handleChannels(pubsub.Channel, subcommand, args[1:])
+	case "SHARDCHANNELS":
+		return c.handleChannels(pubsub.ShardChannel, subcommand, args[1:])
+	case "NUMSUB":
+		return c.handleNumSub(pubsub.Channel, args[1:])
+	case "SHARDNUMSUB":
+		return c.handleNumSub(pubsub.ShardChannel, args[1:])
+	case "NUMPAT":
+		return resp.Integer{Value: int64(c.hub.NumPatterns())}
+	default:
+		return resp.Error{Value: fmt.Sprintf("ERR Unknown PUBSUB subcommand: %s", args[0])}
+	}
+}
+
+func (c *PubSubCommand) handleChannels(kind pubsub.Kind, subcommand string, args [][]byte) resp.RedisValue {
+	if len(args) > 1 {
+		return wrongArgs("pubsub|" + strings.ToLower(subcommand))
+	}
+
+	filter := ""
+	if len(args) == 1 {
+		filter = string(args[0])
+	}
+
+	names := c.hub.ActiveNames(kind, filter)
+	values := make([]resp.RedisValue, len(names))
+	for i, name := range names {
+		values[i] = resp.NewBulkString(name)
+	}
+
+	return resp.Array{Values: values}
+}
+
+func (c *PubSubCommand) handleNumSub(kind pubsub.Kind, channels [][]byte) resp.RedisValue {
+	values := make([]resp.RedisValue, 0, len(channels)*2)
+	for _, channel := range channels {
+		values = append(values, resp.BulkString{Value: channel})
+		values = append(values, resp.Integer{Value: int64(c.hub.NumSubscribers(kind, string(channel)))})
+	}
+
+	return resp.Array{Values: values}
+}
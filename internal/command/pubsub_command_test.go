@@ -0,0 +1,49 @@
+package command
+
+import (
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// nopSubscriber discards the messages pushed to it. The id keeps
+// subscribers distinct, as pointers to empty structs may compare equal.
+type nopSubscriber struct{ id int }
+
+func (nopSubscriber) Push(resp.RedisValue) error { return nil }
+
+func TestShardedPubSubCommands(t *testing.T) {
+	hub := pubsub.NewHub()
+	a, b := &nopSubscriber{id: 1}, &nopSubscriber{id: 2}
+	hub.Subscribe(pubsub.ShardChannel, a, "orders")
+	hub.Subscribe(pubsub.ShardChannel, b, "orders")
+	hub.Subscribe(pubsub.ShardChannel, b, "users")
+	hub.Subscribe(pubsub.Channel, a, "orders")
+
+	pubsubCmd := NewPubSubCommand(hub)
+	tests := []struct {
+		name    string
+		handler Handler
+		args    []string
+		want    resp.RedisValue
+	}{
+		{"SPUBLISH", NewSPublishCommand(hub), []string{"orders", "hi"}, resp.Integer{Value: 2}},
+		{"SPUBLISH without subscribers", NewSPublishCommand(hub), []string{"none", "hi"}, resp.Integer{Value: 0}},
+		{"PUBLISH ignores shard subscribers", NewPublishCommand(hub), []string{"users", "hi"}, resp.Integer{Value: 0}},
+		{"SHARDCHANNELS", pubsubCmd, []string{"SHARDCHANNELS"}, resp.Array{Values: []resp.RedisValue{resp.NewBulkString("orders"), resp.NewBulkString("users")}}},
+		{"SHARDCHANNELS pattern", pubsubCmd, []string{"shardchannels", "u*"}, resp.Array{Values: []resp.RedisValue{resp.NewBulkString("users")}}},
+		{"SHARDNUMSUB", pubsubCmd, []string{"SHARDNUMSUB", "orders", "none"}, resp.Array{Values: []resp.RedisValue{
+			resp.NewBulkString("orders"), resp.Integer{Value: 2}, resp.NewBulkString("none"), resp.Integer{Value: 0},
+		}}},
+		{"NUMSUB counts classic subscribers", pubsubCmd, []string{"NUMSUB", "orders"}, resp.Array{Values: []resp.RedisValue{
+			resp.NewBulkString("orders"), resp.Integer{Value: 1},
+		}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assertReply(t, tt.handler.Execute(bytesArgs(tt.args...)), tt.want)
+		})
+	}
+}
@@ -1,10 +1,21 @@
 package command
 
-import "strings"
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
 
 // DefaultRegistry is the default implementation of the command registry
 type DefaultRegistry struct {
-	handlers map[string]Handler
+	// handlers are keyed by the name clients call them by, and originals
+	// and metadata by the name they were registered with
+	handlers    map[string]Handler
+	originals   map[string]Handler
+	metadata    map[string]Metadata
+	middlewares []Middleware
 }
 
 // Ensure DefaultRegistry implements the Registry interface
@@ -13,22 +24,40 @@ var _ Registry = (*DefaultRegistry)(nil)
 // NewRegistry creates a new command registry
 func NewRegistry() *DefaultRegistry {
 	return &DefaultRegistry{
-		handlers: make(map[string]Handler),
+		handlers:  make(map[string]Handler),
+		originals: make(map[string]Handler),
+		metadata:  make(map[string]Metadata),
 	}
 }
 
-// Register adds a command handler to the registry
-func (r *DefaultRegistry) Register(handler Handler) {
-	r.handlers[strings.ToUpper(handler.Name())] = handler
+// Register adds a command handler to the registry, along with the metadata
+// it describes its command with
+func (r *DefaultRegistry) Register(handler Handler) error {
+	name := strings.ToUpper(handler.Name())
+	describer, ok := handler.(Describer)
+	if !ok {
+		return fmt.Errorf("command '%s' has no metadata", strings.ToLower(name))
+	}
+
+	r.handlers[name] = handler
+	r.originals[name] = handler
+	r.metadata[name] = describer.Describe()
+	return nil
 }
 
-// Get retrieves a command handler by name
+// Get retrieves a command handler by the name clients call it by
 func (r *DefaultRegistry) Get(name string) (Handler, bool) {
 	handler, ok := r.handlers[strings.ToUpper(name)]
 	return handler, ok
 }
 
-// GetAll returns all registered handlers
+// Original retrieves a command handler by its original name
+func (r *DefaultRegistry) Original(name string) (Handler, bool) {
+	handler, ok := r.originals[strings.ToUpper(name)]
+	return handler, ok
+}
+
+// GetAll returns all handlers clients can call
 func (r *DefaultRegistry) GetAll() []Handler {
 	handlers := make([]Handler, 0, len(r.handlers))
 	for _, h := range r.handlers {
@@ -37,3 +66,71 @@ func (r *DefaultRegistry) GetAll() []Handler {
 
 	return handlers
 }
+
+// Names returns the lowercase names clients call commands by, sorted
+func (r *DefaultRegistry) Names() []string {
+	names := make([]string, 0, len(r.handlers))
+	for name := range r.handlers {
+		names = append(names, strings.ToLower(name))
+	}
+	slices.Sort(names)
+	return names
+}
+
+// Metadata describes a command by the name clients call it by
+func (r *DefaultRegistry) Metadata(name string) (Metadata, bool) {
+	handler, ok := r.handlers[strings.ToUpper(name)]
+	if !ok {
+		return Metadata{}, false
+	}
+
+	return r.metadata[strings.ToUpper(handler.Name())], true
+}
+
+// OriginalMetadata describes a command by its original name
+func (r *DefaultRegistry) OriginalMetadata(name string) (Metadata, bool) {
+	meta, ok := r.metadata[strings.ToUpper(name)]
+	return meta, ok
+}
+
+// Rename changes the name clients call a command by, or disables it if
+// newName is empty. Handlers keep their original name, so internal callers
+// are unaffected.
+func (r *DefaultRegistry) Rename(name, newName string) error {
+	name, newName = strings.ToUpper(name), strings.ToUpper(newName)
+	handler, ok := r.handlers[name]
+	if !ok {
+		return fmt.Errorf("no such command '%s'", strings.ToLower(name))
+	}
+	if _, exists := r.handlers[newName]; exists && newName != name {
+		return fmt.Errorf("command '%s' already exists", strings.ToLower(newName))
+	}
+
+	delete(r.handlers, name)
+	if newName != "" {
+		r.handlers[newName] = handler
+	}
+	return nil
+}
+
+// Use appends a middleware to the chain every command passes through
+func (r *DefaultRegistry) Use(middleware Middleware) {
+	r.middlewares = append(r.middlewares, middleware)
+}
+
+// Run passes a command through the middleware chain to its handler
+func (r *DefaultRegistry) Run(ctx *Context) resp.RedisValue {
+	return r.run(ctx, 0)
+}
+
+// run passes a command to the i-th middleware, or to its handler once
+// every middleware has let it through
+func (r *DefaultRegistry) run(ctx *Context, i int) resp.RedisValue {
+	if i == len(r.middlewares) {
+		return execute(ctx)
+	}
+
+	return r.middlewares[i](ctx, func() resp.RedisValue {
+		return r.run(ctx, i+1)
+	})
+}
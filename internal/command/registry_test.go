@@ -0,0 +1,193 @@
+package command
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/replication"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// allHandlers returns a handler for every command the server implements,
+// without the dependencies needed to run them
+func allHandlers() []Handler {
+	return []Handler{
+		&PingCommand{},
+		NewHelloCommand(nil, nil),
+		NewAuthCommand(nil),
+		NewACLCommand(nil, nil, ""),
+		NewCommandCommand(nil),
+		&EchoCommand{},
+		NewGetCommand(nil),
+		NewSetCommand(nil),
+		NewKeysCommand(nil),
+		NewDelCommand(nil),
+		NewIncrCommand(nil),
+		NewDecrCommand(nil),
+		NewIncrByCommand(nil),
+		NewDecrByCommand(nil),
+		NewTTLCommand(nil),
+		NewPTTLCommand(nil),
+		NewInfoCommand(),
+		NewConfigCommand(nil),
+		NewSlowLogCommand(nil),
+		NewSaveCommand(nil),
+		NewBgSaveCommand(nil),
+		NewLastSaveCommand(nil),
+		NewReplConfCommand(),
+		NewPSyncCommand(replication.NewConfig()),
+		NewSubscribeCommand(nil),
+		NewUnsubscribeCommand(nil),
+		NewPSubscribeCommand(nil),
+		NewPUnsubscribeCommand(nil),
+		NewPublishCommand(nil),
+		NewSSubscribeCommand(nil),
+		NewSUnsubscribeCommand(nil),
+		NewSPublishCommand(nil),
+		NewPubSubCommand(nil),
+		NewShutdownCommand(nil),
+	}
+}
+
+func TestHandlersDescribeCommands(t *testing.T) {
+	r := NewRegistry()
+	for _, handler := range allHandlers() {
+		name := handler.Name()
+		if err := r.Register(handler); err != nil {
+			t.Errorf("Register(%s) = %v", name, err)
+			continue
+		}
+
+		meta, ok := r.Metadata(name)
+		switch {
+		case !ok:
+			t.Errorf("%s: no metadata", name)
+		case meta.Arity == 0:
+			t.Errorf("%s: no arity", name)
+		case meta.Categories == 0:
+			t.Errorf("%s: no ACL categories", name)
+		case meta.Summary == "" || meta.Since == "" || meta.Group == "":
+			t.Errorf("%s: undocumented", name)
+		}
+		for sub, subMeta := range meta.Subcommands {
+			if subMeta.Arity == 0 || subMeta.Categories == 0 {
+				t.Errorf("%s|%s: no arity or ACL categories", name, sub)
+			}
+		}
+	}
+}
+
+// undescribedCommand is a handler without metadata
+type undescribedCommand struct{}
+
+func (undescribedCommand) Name() string { return "UNDESCRIBED" }
+func (undescribedCommand) Execute(args [][]byte) resp.RedisValue {
+	return resp.SimpleString{Value: "OK"}
+}
+
+func TestRegisterRequiresMetadata(t *testing.T) {
+	r := NewRegistry()
+	err := r.Register(undescribedCommand{})
+	if err == nil || !strings.Contains(err.Error(), "undescribed") {
+		t.Errorf("Register() = %v, want an error naming the command", err)
+	}
+	if _, ok := r.Get("UNDESCRIBED"); ok {
+		t.Error("command registered despite the error")
+	}
+}
+
+func TestRegistryMetadata(t *testing.T) {
+	r := NewRegistry()
+	for _, handler := range []Handler{NewIncrCommand(nil), NewIncrByCommand(nil), NewTTLCommand(nil), NewPTTLCommand(nil)} {
+		if err := r.Register(handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := r.Rename("INCRBY", "ADD"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Rename("PTTL", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		original  bool
+		wantArity int
+		wantOK    bool
+	}{
+		{"incr", false, 2, true},
+		{"ttl", false, 2, true},
+		{"add", false, 3, true},
+		{"INCRBY", false, 0, false},
+		{"INCRBY", true, 3, true},
+		{"pttl", false, 0, false},
+		{"pttl", true, 2, true},
+		{"missing", true, 0, false},
+	}
+
+	for _, tt := range tests {
+		lookup := r.Metadata
+		if tt.original {
+			lookup = r.OriginalMetadata
+		}
+		meta, ok := lookup(tt.name)
+		if ok != tt.wantOK || meta.Arity != tt.wantArity {
+			t.Errorf("metadata of %q (original %v) = arity %d, %v, want %d, %v", tt.name, tt.original, meta.Arity, ok, tt.wantArity, tt.wantOK)
+		}
+	}
+
+	if got, want := r.Names(), []string{"add", "incr", "ttl"}; !slices.Equal(got, want) {
+		t.Errorf("Names() = %v, want %v", got, want)
+	}
+}
+
+func TestRegistryRename(t *testing.T) {
+	tests := []struct {
+		name, newName string
+		wantErr       string
+		wantGet       string
+	}{
+		{"incr", "add", "", "ADD"},
+		{"INCR", "incr", "", "INCR"},
+		{"incr", "", "", ""},
+		{"missing", "other", "no such command 'missing'", "INCR"},
+		{"incr", "ttl", "command 'ttl' already exists", "INCR"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name+" "+tt.newName, func(t *testing.T) {
+			r := NewRegistry()
+			for _, handler := range []Handler{NewIncrCommand(nil), NewTTLCommand(nil)} {
+				if err := r.Register(handler); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			err := r.Rename(tt.name, tt.newName)
+			if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+				t.Fatalf("Rename() = %v, want %q", err, tt.wantErr)
+			}
+
+			// Handlers keep their original name wherever they are called from
+			var callable []string
+			for _, name := range []string{"INCR", "ADD", "TTL"} {
+				if handler, ok := r.Get(name); ok {
+					if handler.Name() != "INCR" && handler.Name() != "TTL" {
+						t.Errorf("%s runs %s", name, handler.Name())
+					}
+					if handler.Name() == "INCR" {
+						callable = append(callable, name)
+					}
+				}
+			}
+			if got := strings.Join(callable, " "); got != tt.wantGet {
+				t.Errorf("INCR callable as %q, want %q", got, tt.wantGet)
+			}
+			if handler, ok := r.Original("incr"); !ok || handler.Name() != "INCR" {
+				t.Error("INCR not found by its original name")
+			}
+		})
+	}
+}
@@ -5,8 +5,11 @@ import "github.com/codecrafters-io/redis-starter-go/internal/resp"
 // ReplConfCommand implements the REPLCONF command for replication
 type ReplConfCommand struct{}
 
-// Ensure ReplConfCommand implements Handler
-var _ Handler = (*ReplConfCommand)(nil)
+// Ensure ReplConfCommand implements Handler and Describer
+var (
+	_ Handler   = (*ReplConfCommand)(nil)
+	_ Describer = (*ReplConfCommand)(nil)
+)
 
 func NewReplConfCommand() *ReplConfCommand {
 	return &ReplConfCommand{}
@@ -16,7 +19,14 @@ func (c *ReplConfCommand) Name() string {
 	return "REPLCONF"
 }
 
-func (c *ReplConfCommand) Execute(args []string) resp.RedisValue {
+func (c *ReplConfCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -1, Flags: adminFlags, Categories: adminCategories,
+		Summary: "An internal command for configuring the replication stream.", Since: "3.0.0", Group: "server",
+	}
+}
+
+func (c *ReplConfCommand) Execute(args [][]byte) resp.RedisValue {
 	// For now, simply acknowledge all REPLCONF commands
 	return resp.SimpleString{Value: "OK"}
 }
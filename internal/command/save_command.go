@@ -0,0 +1,55 @@
+package command
+
+import (
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// Saver saves the dataset to disk
+type Saver interface {
+	// Save saves the dataset, blocking until it is on disk
+	Save() error
+
+	// BackgroundSave starts saving the dataset in the background. With
+	// schedule, a save requested while another runs is scheduled to start
+	// after it instead of failing.
+	BackgroundSave(schedule bool) (scheduled bool, err error)
+
+	// LastSave returns when the dataset was last saved successfully
+	LastSave() time.Time
+}
+
+// SaveCommand implements the SAVE command
+type SaveCommand struct {
+	saver Saver
+}
+
+// Ensure SaveCommand implements Handler and Describer
+var (
+	_ Handler   = (*SaveCommand)(nil)
+	_ Describer = (*SaveCommand)(nil)
+)
+
+func NewSaveCommand(saver Saver) *SaveCommand {
+	return &SaveCommand{saver: saver}
+}
+
+func (c *SaveCommand) Name() string {
+	return "SAVE"
+}
+
+func (c *SaveCommand) Describe() Metadata {
+	return Metadata{
+		Arity: 1, Flags: FlagAdmin | FlagNoScript, Categories: adminCategories,
+		Summary: "Synchronously saves the database(s) to disk.", Since: "1.0.0", Group: "server",
+	}
+}
+
+func (c *SaveCommand) Execute(args [][]byte) resp.RedisValue {
+	if err := c.saver.Save(); err != nil {
+		return resp.Error{Value: "ERR " + err.Error()}
+	}
+
+	return resp.SimpleString{Value: "OK"}
+}
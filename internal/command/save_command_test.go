@@ -0,0 +1,87 @@
+package command
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// fakeSaver records save requests and fails them with err
+type fakeSaver struct {
+	err        error
+	scheduled  bool
+	saves      int
+	background []bool
+	lastSave   time.Time
+}
+
+func (s *fakeSaver) Save() error {
+	s.saves++
+	return s.err
+}
+
+func (s *fakeSaver) BackgroundSave(schedule bool) (bool, error) {
+	s.background = append(s.background, schedule)
+	return s.scheduled, s.err
+}
+
+func (s *fakeSaver) LastSave() time.Time {
+	return s.lastSave
+}
+
+func TestSaveCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want resp.RedisValue
+	}{
+		{"ok", nil, resp.SimpleString{Value: "OK"}},
+		{"in progress", errors.New("Background save already in progress"), resp.Error{Value: "ERR Background save already in progress"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saver := &fakeSaver{err: tt.err}
+			assertReply(t, NewSaveCommand(saver).Execute(nil), tt.want)
+			if saver.saves != 1 {
+				t.Errorf("saved %d times, want once", saver.saves)
+			}
+		})
+	}
+}
+
+func TestBgSaveCommand(t *testing.T) {
+	tests := []struct {
+		name      string
+		args      []string
+		scheduled bool
+		err       error
+		want      resp.RedisValue
+		schedule  []bool
+	}{
+		{"started", nil, false, nil, resp.SimpleString{Value: "Background saving started"}, []bool{false}},
+		{"schedule", []string{"schedule"}, true, nil, resp.SimpleString{Value: "Background saving scheduled"}, []bool{true}},
+		{"schedule started", []string{"SCHEDULE"}, false, nil, resp.SimpleString{Value: "Background saving started"}, []bool{true}},
+		{"in progress", nil, false, errors.New("Background save already in progress"),
+			resp.Error{Value: "ERR Background save already in progress"}, []bool{false}},
+		{"unknown option", []string{"now"}, false, nil, resp.Error{Value: "ERR syntax error"}, nil},
+		{"extra argument", []string{"schedule", "x"}, false, nil, resp.Error{Value: "ERR syntax error"}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saver := &fakeSaver{scheduled: tt.scheduled, err: tt.err}
+			assertReply(t, NewBgSaveCommand(saver).Execute(bytesArgs(tt.args...)), tt.want)
+			if len(saver.background) != len(tt.schedule) || (len(tt.schedule) > 0 && saver.background[0] != tt.schedule[0]) {
+				t.Errorf("background saves %v, want %v", saver.background, tt.schedule)
+			}
+		})
+	}
+}
+
+func TestLastSaveCommand(t *testing.T) {
+	saver := &fakeSaver{lastSave: time.Unix(1700000000, 500)}
+	assertReply(t, NewLastSaveCommand(saver).Execute(nil), resp.Integer{Value: 1700000000})
+}
@@ -1,9 +1,11 @@
 package command
 
 import (
+	"bytes"
 	"strconv"
 	"strings"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 	"github.com/codecrafters-io/redis-starter-go/internal/storage"
 )
@@ -13,8 +15,11 @@ type SetCommand struct {
 	store storage.Storage
 }
 
-// Ensure SetCommand implement Handler
-var _ Handler = (*SetCommand)(nil)
+// Ensure SetCommand implements Handler and Describer
+var (
+	_ Handler   = (*SetCommand)(nil)
+	_ Describer = (*SetCommand)(nil)
+)
 
 func NewSetCommand(store storage.Storage) *SetCommand {
 	return &SetCommand{store: store}
@@ -24,19 +29,23 @@ func (c *SetCommand) Name() string {
 	return "SET"
 }
 
-func (c *SetCommand) Execute(args []string) resp.RedisValue {
-	if len(args) < 2 {
-		return resp.Error{Value: "ERR wrong number of arguments for 'set' command"}
+func (c *SetCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -3, Flags: FlagWrite | FlagDenyOOM, Categories: acl.CategoryWrite | acl.CategoryString | acl.CategorySlow, Keys: firstArg,
+		Summary: "Sets the string value of a key, ignoring its type. The key is created if it doesn't exist.", Since: "1.0.0", Group: "string",
 	}
+}
 
-	key := args[0]
-	value := args[1]
+func (c *SetCommand) Execute(args [][]byte) resp.RedisValue {
+	// The value outlives the command, so it must not alias the read buffer
+	key := string(args[0])
+	value := bytes.Clone(args[1])
 
 	// Check for additional options
 	if len(args) > 2 {
 		// Handle PX option (expiry in milliseconds)
-		if len(args) >= 4 && strings.ToUpper(args[2]) == "PX" {
-			ms, err := strconv.Atoi(args[3])
+		if len(args) >= 4 && strings.ToUpper(string(args[2])) == "PX" {
+			ms, err := strconv.Atoi(string(args[3]))
 			if err != nil {
 				return resp.Error{Value: "ERR invalid expire time in 'set' command"}
 			}
@@ -0,0 +1,91 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// ShutdownOptions are the modifiers of the SHUTDOWN command
+type ShutdownOptions struct {
+	// Save forces an RDB save even if persistence is not configured, while
+	// NoSave skips it even if it is
+	Save   bool
+	NoSave bool
+	// Now skips waiting for replicas to catch up
+	Now bool
+	// Force ignores errors that would otherwise prevent the shutdown, such
+	// as a failed save
+	Force bool
+	// Abort cancels a shutdown that is waiting for replicas
+	Abort bool
+}
+
+// Shutdowner stops the server. The requesting client, if any, is the one
+// running SHUTDOWN; on success its connection is closed along with all
+// others, so no reply is sent.
+type Shutdowner interface {
+	Shutdown(requester *client.Client, opts ShutdownOptions) error
+}
+
+// ShutdownCommand implements the SHUTDOWN command
+type ShutdownCommand struct {
+	server Shutdowner
+}
+
+// Ensure ShutdownCommand implements ClientHandler and Describer
+var (
+	_ ClientHandler = (*ShutdownCommand)(nil)
+	_ Describer     = (*ShutdownCommand)(nil)
+)
+
+func NewShutdownCommand(server Shutdowner) *ShutdownCommand {
+	return &ShutdownCommand{server: server}
+}
+
+func (c *ShutdownCommand) Name() string {
+	return "SHUTDOWN"
+}
+
+func (c *ShutdownCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -1, Flags: adminFlags, Categories: adminCategories,
+		Summary: "Synchronously saves the database(s) to disk and shuts down the Redis server.", Since: "1.0.0", Group: "server",
+	}
+}
+
+func (c *ShutdownCommand) Execute(args [][]byte) resp.RedisValue {
+	return c.ExecuteClient(nil, args)
+}
+
+func (c *ShutdownCommand) ExecuteClient(cl *client.Client, args [][]byte) resp.RedisValue {
+	var opts ShutdownOptions
+	for _, arg := range args {
+		switch strings.ToUpper(string(arg)) {
+		case "SAVE":
+			opts.Save = true
+		case "NOSAVE":
+			opts.NoSave = true
+		case "NOW":
+			opts.Now = true
+		case "FORCE":
+			opts.Force = true
+		case "ABORT":
+			opts.Abort = true
+		default:
+			return resp.Error{Value: "ERR syntax error"}
+		}
+	}
+
+	if (opts.Save && opts.NoSave) || (opts.Abort && len(args) > 1) {
+		return resp.Error{Value: "ERR syntax error"}
+	}
+
+	if err := c.server.Shutdown(cl, opts); err != nil {
+		return resp.Error{Value: fmt.Sprintf("ERR %v", err)}
+	}
+
+	return resp.SimpleString{Value: "OK"}
+}
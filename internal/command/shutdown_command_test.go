@@ -0,0 +1,53 @@
+package command
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// fakeShutdowner records the options it is called with, failing with err
+type fakeShutdowner struct {
+	err   error
+	calls []ShutdownOptions
+}
+
+func (s *fakeShutdowner) Shutdown(requester *client.Client, opts ShutdownOptions) error {
+	s.calls = append(s.calls, opts)
+	return s.err
+}
+
+func TestShutdownCommand(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		err      error
+		want     resp.RedisValue
+		wantOpts *ShutdownOptions
+	}{
+		{"no options", nil, nil, resp.SimpleString{Value: "OK"}, &ShutdownOptions{}},
+		{"NOSAVE", []string{"nosave"}, nil, resp.SimpleString{Value: "OK"}, &ShutdownOptions{NoSave: true}},
+		{"SAVE NOW FORCE", []string{"SAVE", "now", "Force"}, nil, resp.SimpleString{Value: "OK"}, &ShutdownOptions{Save: true, Now: true, Force: true}},
+		{"ABORT", []string{"ABORT"}, nil, resp.SimpleString{Value: "OK"}, &ShutdownOptions{Abort: true}},
+		{"SAVE and NOSAVE", []string{"SAVE", "NOSAVE"}, nil, resp.Error{Value: "ERR syntax error"}, nil},
+		{"ABORT with other options", []string{"ABORT", "NOW"}, nil, resp.Error{Value: "ERR syntax error"}, nil},
+		{"unknown option", []string{"LATER"}, nil, resp.Error{Value: "ERR syntax error"}, nil},
+		{"failed", nil, errors.New("Errors trying to SHUTDOWN. Check logs."), resp.Error{Value: "ERR Errors trying to SHUTDOWN. Check logs."}, &ShutdownOptions{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := &fakeShutdowner{err: tt.err}
+			assertReply(t, NewShutdownCommand(server).Execute(bytesArgs(tt.args...)), tt.want)
+
+			switch {
+			case tt.wantOpts == nil && len(server.calls) > 0:
+				t.Errorf("Shutdown called with %+v", server.calls[0])
+			case tt.wantOpts != nil && (len(server.calls) != 1 || server.calls[0] != *tt.wantOpts):
+				t.Errorf("Shutdown calls %+v, want %+v", server.calls, *tt.wantOpts)
+			}
+		})
+	}
+}
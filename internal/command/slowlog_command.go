@@ -0,0 +1,111 @@
+package command
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/slowlog"
+)
+
+// SlowLogCommand implements the SLOWLOG command
+type SlowLogCommand struct {
+	log *slowlog.Log
+}
+
+// Ensure SlowLogCommand implements Handler and Describer
+var (
+	_ Handler   = (*SlowLogCommand)(nil)
+	_ Describer = (*SlowLogCommand)(nil)
+)
+
+func NewSlowLogCommand(log *slowlog.Log) *SlowLogCommand {
+	return &SlowLogCommand{log: log}
+}
+
+func (c *SlowLogCommand) Name() string {
+	return "SLOWLOG"
+}
+
+func (c *SlowLogCommand) Describe() Metadata {
+	return Metadata{
+		Arity: -2, Categories: adminCategories,
+		Summary: "A container for slow log commands.", Since: "2.2.12", Group: "server",
+		Subcommands: map[string]Metadata{
+			"get":   {Arity: -2, Flags: FlagAdmin | FlagLoading | FlagStale, Categories: adminCategories, Summary: "Returns the slow log's entries.", Since: "2.2.12"},
+			"help":  {Arity: 2, Flags: FlagLoading | FlagStale, Categories: acl.CategorySlow, Summary: "Show helpful text about the different subcommands", Since: "6.2.0"},
+			"len":   {Arity: 2, Flags: FlagAdmin | FlagLoading | FlagStale, Categories: adminCategories, Summary: "Returns the number of entries in the slow log.", Since: "2.2.12"},
+			"reset": {Arity: 2, Flags: FlagAdmin | FlagLoading | FlagStale, Categories: adminCategories, Summary: "Clears all entries from the slow log.", Since: "2.2.12"},
+		},
+	}
+}
+
+func (c *SlowLogCommand) Execute(args [][]byte) resp.RedisValue {
+	subcommand := strings.ToLower(string(args[0]))
+	switch subcommand {
+	case "get":
+		if len(args) > 2 {
+			return wrongArgs("slowlog|get")
+		}
+		return c.handleGet(args[1:])
+	case "len":
+		return resp.Integer{Value: int64(c.log.Len())}
+	case "reset":
+		c.log.Reset()
+		return resp.SimpleString{Value: "OK"}
+	case "help":
+		lines := []string{
+			"SLOWLOG <subcommand> [<arg> [value] [opt] ...]. Subcommands are:",
+			"GET [<count>]",
+			"    Return top <count> entries from the slowlog (default: 10, -1 mean all).",
+			"LEN",
+			"    Return the length of the slowlog.",
+			"RESET",
+			"    Reset the slowlog.",
+			"HELP",
+			"    Print this help.",
+		}
+		values := make([]resp.RedisValue, len(lines))
+		for i, line := range lines {
+			values[i] = resp.SimpleString{Value: line}
+		}
+		return resp.Array{Values: values}
+	default:
+		return resp.Error{Value: fmt.Sprintf("ERR unknown subcommand '%s'. Try SLOWLOG HELP.", subcommand)}
+	}
+}
+
+// handleGet returns the newest entries, 10 by default or all for -1
+func (c *SlowLogCommand) handleGet(args [][]byte) resp.RedisValue {
+	count := 10
+	if len(args) == 1 {
+		n, err := strconv.Atoi(string(args[0]))
+		if err != nil {
+			return resp.Error{Value: "ERR value is not an integer or out of range"}
+		}
+		if n < -1 {
+			return resp.Error{Value: "ERR count should be greater than or equal to -1"}
+		}
+		count = n
+	}
+
+	entries := c.log.Entries(count)
+	values := make([]resp.RedisValue, len(entries))
+	for i, e := range entries {
+		args := make([]resp.RedisValue, len(e.Args))
+		for j, arg := range e.Args {
+			args[j] = resp.NewBulkString(arg)
+		}
+		values[i] = resp.Array{Values: []resp.RedisValue{
+			resp.Integer{Value: e.ID},
+			resp.Integer{Value: e.Time.Unix()},
+			resp.Integer{Value: e.Duration.Microseconds()},
+			resp.Array{Values: args},
+			resp.NewBulkString(e.ClientAddr),
+			resp.NewBulkString(e.ClientName),
+		}}
+	}
+	return resp.Array{Values: values}
+}
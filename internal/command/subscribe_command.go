@@ -0,0 +1,135 @@
+package command
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// SubscribeCommand implements the family of (un)subscribe commands:
+// SUBSCRIBE, UNSUBSCRIBE, PSUBSCRIBE, PUNSUBSCRIBE, SSUBSCRIBE and SUNSUBSCRIBE
+type SubscribeCommand struct {
+	hub       *pubsub.Hub
+	name      string
+	kind      pubsub.Kind
+	subscribe bool
+}
+
+// Ensure SubscribeCommand implements ClientHandler and Describer
+var (
+	_ ClientHandler = (*SubscribeCommand)(nil)
+	_ Describer     = (*SubscribeCommand)(nil)
+)
+
+// subscribeMetadata describes the commands SubscribeCommand implements, by name
+var subscribeMetadata = map[string]Metadata{
+	"SUBSCRIBE": {
+		Arity: -2, Flags: pubsubFlags, Categories: pubsubCategories, Channels: allArgs,
+		Summary: "Listens for messages published to channels.", Since: "2.0.0", Group: "pubsub",
+	},
+	"UNSUBSCRIBE": {
+		Arity: -1, Flags: pubsubFlags, Categories: pubsubCategories,
+		Summary: "Stops listening to messages posted to channels.", Since: "2.0.0", Group: "pubsub",
+	},
+	"PSUBSCRIBE": {
+		Arity: -2, Flags: pubsubFlags, Categories: pubsubCategories, Patterns: allArgs,
+		Summary: "Listens for messages published to channels that match one or more patterns.", Since: "2.0.0", Group: "pubsub",
+	},
+	"PUNSUBSCRIBE": {
+		Arity: -1, Flags: pubsubFlags, Categories: pubsubCategories,
+		Summary: "Stops listening to messages published to channels that match one or more patterns.", Since: "2.0.0", Group: "pubsub",
+	},
+	"SSUBSCRIBE": {
+		Arity: -2, Flags: pubsubFlags, Categories: pubsubCategories, Channels: allArgs,
+		Summary: "Listens for messages published to shard channels.", Since: "7.0.0", Group: "pubsub",
+	},
+	"SUNSUBSCRIBE": {
+		Arity: -1, Flags: pubsubFlags, Categories: pubsubCategories,
+		Summary: "Stops listening to messages posted to shard channels.", Since: "7.0.0", Group: "pubsub",
+	},
+}
+
+// NewSubscribeCommand creates a SUBSCRIBE command handler
+func NewSubscribeCommand(hub *pubsub.Hub) *SubscribeCommand {
+	return &SubscribeCommand{hub: hub, name: "SUBSCRIBE", kind: pubsub.Channel, subscribe: true}
+}
+
+// NewUnsubscribeCommand creates an UNSUBSCRIBE command handler
+func NewUnsubscribeCommand(hub *pubsub.Hub) *SubscribeCommand {
+	return &SubscribeCommand{hub: hub, name: "UNSUBSCRIBE", kind: pubsub.Channel}
+}
+
+// NewPSubscribeCommand creates a PSUBSCRIBE command handler
+func NewPSubscribeCommand(hub *pubsub.Hub) *SubscribeCommand {
+	return &SubscribeCommand{hub: hub, name: "PSUBSCRIBE", kind: pubsub.Pattern, subscribe: true}
+}
+
+// NewPUnsubscribeCommand creates a PUNSUBSCRIBE command handler
+func NewPUnsubscribeCommand(hub *pubsub.Hub) *SubscribeCommand {
+	return &SubscribeCommand{hub: hub, name: "PUNSUBSCRIBE", kind: pubsub.Pattern}
+}
+
+// NewSSubscribeCommand creates an SSUBSCRIBE command handler
+func NewSSubscribeCommand(hub *pubsub.Hub) *SubscribeCommand {
+	return &SubscribeCommand{hub: hub, name: "SSUBSCRIBE", kind: pubsub.ShardChannel, subscribe: true}
+}
+
+// NewSUnsubscribeCommand creates an SUNSUBSCRIBE command handler
+func NewSUnsubscribeCommand(hub *pubsub.Hub) *SubscribeCommand {
+	return &SubscribeCommand{hub: hub, name: "SUNSUBSCRIBE", kind: pubsub.ShardChannel}
+}
+
+func (c *SubscribeCommand) Name() string {
+	return c.name
+}
+
+func (c *SubscribeCommand) Describe() Metadata {
+	return subscribeMetadata[c.name]
+}
+
+func (c *SubscribeCommand) Execute(args [][]byte) resp.RedisValue {
+	return resp.Error{Value: fmt.Sprintf("ERR '%s' requires a client connection", strings.ToLower(c.name))}
+}
+
+func (c *SubscribeCommand) ExecuteClient(cl *client.Client, args [][]byte) resp.RedisValue {
+	names := make([]string, len(args))
+	for i, arg := range args {
+		names[i] = string(arg)
+	}
+	if !c.subscribe && len(names) == 0 {
+		// Without arguments, unsubscribe from everything in the namespace
+		names = c.hub.Subscriptions(c.kind, cl)
+		if len(names) == 0 {
+			return c.reply(resp.Null{}, 0)
+		}
+	}
+
+	replies := make([]resp.RedisValue, 0, len(names))
+	for _, name := range names {
+		var count int
+		if c.subscribe {
+			count = c.hub.Subscribe(c.kind, cl, name)
+		} else {
+			count = c.hub.Unsubscribe(c.kind, cl, name)
+		}
+		replies = append(replies, c.reply(resp.NewBulkString(name), count))
+	}
+
+	// Subscribers are subject to the pubsub output buffer limits
+	cl.SetFlag(client.FlagPubSub, c.hub.IsSubscribed(cl))
+
+	return resp.Replies{Values: replies}
+}
+
+// reply builds the confirmation sent for each (un)subscribed name, which
+// RESP3 clients receive as a push message
+func (c *SubscribeCommand) reply(name resp.RedisValue, count int) resp.RedisValue {
+	return resp.Push{Values: []resp.RedisValue{
+		resp.NewBulkString(strings.ToLower(c.name)),
+		name,
+		resp.Integer{Value: int64(count)},
+	}}
+}
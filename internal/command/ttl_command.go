@@ -0,0 +1,67 @@
+package command
+
+import (
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage"
+)
+
+// TTLCommand implements the TTL and PTTL commands
+type TTLCommand struct {
+	store storage.Storage
+	unit  time.Duration
+}
+
+// Ensure TTLCommand implements Handler and Describer
+var (
+	_ Handler   = (*TTLCommand)(nil)
+	_ Describer = (*TTLCommand)(nil)
+)
+
+// ttlMetadata describes the commands TTLCommand implements, by name
+var ttlMetadata = map[string]Metadata{
+	"TTL": {
+		Arity: 2, Flags: FlagReadOnly, Categories: acl.CategoryKeyspace | acl.CategoryRead | acl.CategoryFast, Keys: firstArg,
+		Summary: "Returns the expiration time in seconds of a key.", Since: "1.0.0", Group: "generic",
+	},
+	"PTTL": {
+		Arity: 2, Flags: FlagReadOnly, Categories: acl.CategoryKeyspace | acl.CategoryRead | acl.CategoryFast, Keys: firstArg,
+		Summary: "Returns the expiration time in milliseconds of a key.", Since: "2.6.0", Group: "generic",
+	},
+}
+
+// NewTTLCommand creates a TTL command handler, replying in seconds
+func NewTTLCommand(store storage.Storage) *TTLCommand {
+	return &TTLCommand{store: store, unit: time.Second}
+}
+
+// NewPTTLCommand creates a PTTL command handler, replying in milliseconds
+func NewPTTLCommand(store storage.Storage) *TTLCommand {
+	return &TTLCommand{store: store, unit: time.Millisecond}
+}
+
+func (c *TTLCommand) Name() string {
+	if c.unit == time.Millisecond {
+		return "PTTL"
+	}
+	return "TTL"
+}
+
+func (c *TTLCommand) Describe() Metadata {
+	return ttlMetadata[c.Name()]
+}
+
+func (c *TTLCommand) Execute(args [][]byte) resp.RedisValue {
+	ttl, exists := c.store.TTL(string(args[0]))
+	switch {
+	case !exists:
+		return resp.Integer{Value: -2}
+	case ttl < 0:
+		return resp.Integer{Value: -1}
+	default:
+		// Round to the nearest unit, like Redis does
+		return resp.Integer{Value: int64((ttl + c.unit/2) / c.unit)}
+	}
+}
@@ -2,19 +2,116 @@ package config
 
 import (
 	"flag"
+	"fmt"
+	"math"
+	"os"
 	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/notify"
+	"github.com/codecrafters-io/redis-starter-go/internal/rdb"
 	"github.com/codecrafters-io/redis-starter-go/internal/replication"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
 )
 
+// keys lists the parameters visible to CONFIG GET, in reply order
+var keys = []string{
+	"dir",
+	"dbfilename",
+	"save",
+	"stop-writes-on-bgsave-error",
+	"bind",
+	"protected-mode",
+	"port",
+	"unixsocket",
+	"unixsocketperm",
+	"tls-port",
+	"tls-cert-file",
+	"tls-key-file",
+	"tls-ca-cert-file",
+	"tls-auth-clients",
+	"notify-keyspace-events",
+	"proto-max-bulk-len",
+	"proto-max-multibulk-len",
+	"client-query-buffer-limit",
+	"client-output-buffer-limit",
+	"shutdown-timeout",
+	"timeout",
+	"tcp-keepalive",
+	"maxclients",
+	"requirepass",
+	"aclfile",
+	"acllog-max-len",
+	"slowlog-log-slower-than",
+	"slowlog-max-len",
+}
+
+// defaultBind listens on every IPv4 address and, where available, every
+// IPv6 address
+var defaultBind = []string{"*", "-::*"}
+
 // Config represents the application configuration
 type Config struct {
-	Dir               string
-	DbFileName        string
-	Port              int
-	ReplicationConfig *replication.Config
+	Dir                  string
+	DbFileName           string
+	Port                 int
+	Bind                 []string
+	ProtectedMode        bool
+	UnixSocket           string
+	UnixSocketPerm       os.FileMode
+	TLSPort              int
+	TLS                  TLSSettings
+	NotifyKeyspaceEvents string
+	ReplicationConfig    *replication.Config
+
+	// SavePoints trigger background saves once enough changes were made,
+	// and StopWritesOnBgsaveError refuses writes while saving fails
+	SavePoints              []rdb.SavePoint
+	StopWritesOnBgsaveError bool
+
+	// Protocol limits protecting the server from oversized client input
+	ProtoMaxBulkLen        int64
+	ProtoMaxMultibulkLen   int64
+	ClientQueryBufferLimit int64
+
+	// ClientOutputBufferLimit bounds the pending output of each client class
+	ClientOutputBufferLimit [client.NumClasses]client.OutputLimit
+
+	// ShutdownTimeout bounds how long a shutdown waits for replicas
+	ShutdownTimeout time.Duration
+
+	// Timeout disconnects clients idle for longer, if not zero, and
+	// TCPKeepalive is the keepalive period of client connections
+	Timeout      time.Duration
+	TCPKeepalive time.Duration
+
+	// RequirePass is the password of the default user, if not empty
+	RequirePass string
+
+	// RenameCommands renames or disables commands at startup
+	RenameCommands []Rename
+
+	// ACLFile is where ACL SAVE and LOAD store users, and ACLLogMaxLen
+	// bounds the ACL LOG
+	ACLFile      string
+	ACLLogMaxLen int
+
+	// SlowLogSlowerThan is the execution time from which commands are
+	// recorded in the slow log; negative disables it. SlowLogMaxLen bounds
+	// the number of entries.
+	SlowLogSlowerThan time.Duration
+	SlowLogMaxLen     int
+
+	// MaxClients bounds the number of connected clients
+	MaxClients int
+
+	// mu guards parameters that can be changed at runtime with CONFIG SET
+	mu        sync.RWMutex
+	listeners map[string][]func(value string)
 }
 
 // NewConfig creates a new configuration with default values
@@ -23,7 +120,25 @@ func NewConfig() *Config {
 		Dir:               "/var/lib/redis",
 		DbFileName:        "dump.rdb",
 		Port:              6379,
+		Bind:              defaultBind,
+		ProtectedMode:     true,
 		ReplicationConfig: replication.NewConfig(),
+
+		SavePoints:              defaultSavePoints,
+		StopWritesOnBgsaveError: true,
+
+		ProtoMaxBulkLen:        512 * 1024 * 1024,
+		ProtoMaxMultibulkLen:   1024 * 1024,
+		ClientQueryBufferLimit: 1024 * 1024 * 1024,
+
+		ClientOutputBufferLimit: client.DefaultOutputLimits,
+		ShutdownTimeout:         10 * time.Second,
+		TCPKeepalive:            300 * time.Second,
+		MaxClients:              10000,
+		ACLLogMaxLen:            128,
+		SlowLogSlowerThan:       10 * time.Millisecond,
+		SlowLogMaxLen:           128,
+		TLS:                     TLSSettings{AuthClients: "yes"},
 	}
 }
 
@@ -37,8 +152,37 @@ func (c *Config) LoadFromArgs() {
 	// Define flags with default values
 	dir := flag.String("dir", c.Dir, "Directory to store database files")
 	dbFilename := flag.String("dbfilename", c.DbFileName, "Database filename")
-	port := flag.Int("port", c.Port, "Server port number")
+	port := flag.Int("port", c.Port, "Server port number (0 to disable TCP)")
+	bind := flag.String("bind", strings.Join(c.Bind, " "), "Addresses to listen on; a '-' prefix makes an address optional")
+	unixSocket := flag.String("unixsocket", c.UnixSocket, "Path of a Unix socket to listen on")
+	unixSocketPerm := flag.String("unixsocketperm", "0", "Octal permissions of the Unix socket (e.g., '700')")
+	aclFile := flag.String("aclfile", c.ACLFile, "File users are loaded from at startup and saved to with ACL SAVE")
+	tlsPort := flag.Int("tls-port", c.TLSPort, "TLS port number (0 to disable TLS)")
+	flag.Var((*renameFlag)(&c.RenameCommands), "rename-command", "Command to rename and its new name, or \"\" to disable it (e.g., 'KEYS MYKEYS'); repeatable")
 	replicaOf := flag.String("replicaof", "", "Master host and port for replication (e.g., '127.0.0.1 6379')")
+	settable := map[string]*string{
+		"notify-keyspace-events":    flag.String("notify-keyspace-events", c.NotifyKeyspaceEvents, "Keyspace event classes to publish (e.g., 'Ex')"),
+		"proto-max-bulk-len":        flag.String("proto-max-bulk-len", "512mb", "Maximum size of a single bulk string argument"),
+		"proto-max-multibulk-len":   flag.String("proto-max-multibulk-len", strconv.FormatInt(c.ProtoMaxMultibulkLen, 10), "Maximum number of arguments in a command"),
+		"client-query-buffer-limit": flag.String("client-query-buffer-limit", "1gb", "Maximum total size of a single client command"),
+		"client-output-buffer-limit": flag.String("client-output-buffer-limit", formatOutputLimits(c.ClientOutputBufferLimit),
+			"Output buffer limits per client class (e.g., 'pubsub 32mb 8mb 60')"),
+		"shutdown-timeout":            flag.String("shutdown-timeout", "10", "Seconds to wait for replicas to catch up on shutdown"),
+		"timeout":                     flag.String("timeout", "0", "Seconds after which idle clients are disconnected (0 to disable)"),
+		"tcp-keepalive":               flag.String("tcp-keepalive", "300", "TCP keepalive period of client connections in seconds (0 to disable)"),
+		"save":                        flag.String("save", formatSavePoints(c.SavePoints), "Save points as pairs of seconds and changes (e.g., '3600 1 300 100'), or \"\" to disable them"),
+		"stop-writes-on-bgsave-error": flag.String("stop-writes-on-bgsave-error", "yes", "Refuse writes while saving to disk fails"),
+		"protected-mode":              flag.String("protected-mode", "yes", "Only accept loopback connections while no password is set"),
+		"maxclients":                  flag.String("maxclients", strconv.Itoa(c.MaxClients), "Maximum number of connected clients"),
+		"requirepass":                 flag.String("requirepass", "", "Password clients must authenticate with"),
+		"acllog-max-len":              flag.String("acllog-max-len", strconv.Itoa(c.ACLLogMaxLen), "Maximum number of ACL LOG entries"),
+		"slowlog-log-slower-than":     flag.String("slowlog-log-slower-than", "10000", "Microseconds from which commands are logged as slow (negative to disable)"),
+		"slowlog-max-len":             flag.String("slowlog-max-len", strconv.Itoa(c.SlowLogMaxLen), "Maximum number of SLOWLOG entries"),
+		"tls-cert-file":               flag.String("tls-cert-file", "", "Server certificate file for TLS"),
+		"tls-key-file":                flag.String("tls-key-file", "", "Private key file of the TLS certificate"),
+		"tls-ca-cert-file":            flag.String("tls-ca-cert-file", "", "CA certificate file used to verify TLS clients"),
+		"tls-auth-clients":            flag.String("tls-auth-clients", c.TLS.AuthClients, "Whether TLS clients must present a certificate: yes, no or optional"),
+	}
 
 	// Parse the command-line arguments
 	flag.Parse()
@@ -47,6 +191,22 @@ func (c *Config) LoadFromArgs() {
 	c.Dir = *dir
 	c.DbFileName = *dbFilename
 	c.Port = *port
+	c.Bind = strings.Fields(*bind)
+	c.UnixSocket = *unixSocket
+	c.TLSPort = *tlsPort
+	c.ACLFile = *aclFile
+	if perm, err := strconv.ParseUint(*unixSocketPerm, 8, 32); err == nil && perm <= 0777 {
+		c.UnixSocketPerm = os.FileMode(perm)
+	} else {
+		fmt.Printf("Warning: ignoring invalid unixsocketperm %q\n", *unixSocketPerm)
+	}
+
+	// Runtime-settable parameters share CONFIG SET's validation
+	for key, value := range settable {
+		if err := c.SetString(key, *value); err != nil {
+			fmt.Printf("Warning: ignoring %s: %v\n", key, err)
+		}
+	}
 
 	// Handle replication configuration
 	if *replicaOf != "" {
@@ -61,8 +221,16 @@ func (c *Config) LoadFromArgs() {
 	}
 }
 
+// Keys returns the names of all parameters known to CONFIG GET
+func (c *Config) Keys() []string {
+	return keys
+}
+
 // GetString returns a configuration value as a string
 func (c *Config) GetString(key string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
 	switch key {
 	case "dir":
 		return c.Dir, true
@@ -70,11 +238,305 @@ func (c *Config) GetString(key string) (string, bool) {
 		return c.DbFileName, true
 	case "port":
 		return strconv.Itoa(c.Port), true
+	case "save":
+		return formatSavePoints(c.SavePoints), true
+	case "stop-writes-on-bgsave-error":
+		return formatBool(c.StopWritesOnBgsaveError), true
+	case "bind":
+		return strings.Join(c.Bind, " "), true
+	case "protected-mode":
+		return formatBool(c.ProtectedMode), true
+	case "unixsocket":
+		return c.UnixSocket, true
+	case "unixsocketperm":
+		return strconv.FormatUint(uint64(c.UnixSocketPerm), 8), true
+	case "tls-port":
+		return strconv.Itoa(c.TLSPort), true
+	case "tls-cert-file":
+		return c.TLS.CertFile, true
+	case "tls-key-file":
+		return c.TLS.KeyFile, true
+	case "tls-ca-cert-file":
+		return c.TLS.CACertFile, true
+	case "tls-auth-clients":
+		return c.TLS.AuthClients, true
+	case "notify-keyspace-events":
+		return c.NotifyKeyspaceEvents, true
+	case "proto-max-bulk-len":
+		return strconv.FormatInt(c.ProtoMaxBulkLen, 10), true
+	case "proto-max-multibulk-len":
+		return strconv.FormatInt(c.ProtoMaxMultibulkLen, 10), true
+	case "client-query-buffer-limit":
+		return strconv.FormatInt(c.ClientQueryBufferLimit, 10), true
+	case "client-output-buffer-limit":
+		return formatOutputLimits(c.ClientOutputBufferLimit), true
+	case "shutdown-timeout":
+		return strconv.FormatInt(int64(c.ShutdownTimeout/time.Second), 10), true
+	case "timeout":
+		return strconv.FormatInt(int64(c.Timeout/time.Second), 10), true
+	case "tcp-keepalive":
+		return strconv.FormatInt(int64(c.TCPKeepalive/time.Second), 10), true
+	case "maxclients":
+		return strconv.Itoa(c.MaxClients), true
+	case "requirepass":
+		return c.RequirePass, true
+	case "aclfile":
+		return c.ACLFile, true
+	case "acllog-max-len":
+		return strconv.Itoa(c.ACLLogMaxLen), true
+	case "slowlog-log-slower-than":
+		return strconv.FormatInt(c.SlowLogSlowerThan.Microseconds(), 10), true
+	case "slowlog-max-len":
+		return strconv.Itoa(c.SlowLogMaxLen), true
 	default:
 		return "", false
 	}
 }
 
+// CheckString returns the error SetString would return for setting key to
+// value, without changing anything
+func (c *Config) CheckString(key, value string) error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	_, _, err := c.parse(key, value)
+	return err
+}
+
+// SetString changes a runtime-configurable value and notifies the
+// listeners registered for it
+func (c *Config) SetString(key, value string) error {
+	c.mu.Lock()
+	apply, value, err := c.parse(key, value)
+	if err != nil {
+		c.mu.Unlock()
+		return err
+	}
+	apply()
+	listeners := c.listeners[key]
+	c.mu.Unlock()
+
+	for _, fn := range listeners {
+		fn(value)
+	}
+
+	return nil
+}
+
+// parse checks value for the runtime-configurable key, returning a function
+// setting it and the value as listeners are given it. Callers must hold
+// c.mu.
+func (c *Config) parse(key, value string) (func(), string, error) {
+	switch key {
+	case "notify-keyspace-events":
+		classes, err := notify.ParseClasses(value)
+		if err != nil {
+			return nil, "", err
+		}
+		value = classes.String()
+		return func() { c.NotifyKeyspaceEvents = value }, value, nil
+	case "proto-max-bulk-len", "client-query-buffer-limit":
+		n, err := ParseMemory(value)
+		if err == nil && n < 1024*1024 {
+			err = fmt.Errorf("argument must be at least 1mb")
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		field := &c.ProtoMaxBulkLen
+		if key == "client-query-buffer-limit" {
+			field = &c.ClientQueryBufferLimit
+		}
+		return func() { *field = n }, strconv.FormatInt(n, 10), nil
+	case "proto-max-multibulk-len":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 || n > math.MaxInt32 {
+			return nil, "", fmt.Errorf("argument must be between 1 and %d", math.MaxInt32)
+		}
+		return func() { c.ProtoMaxMultibulkLen = n }, value, nil
+	case "maxclients":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 1 || n > math.MaxInt32 {
+			return nil, "", fmt.Errorf("argument must be between 1 and %d", math.MaxInt32)
+		}
+		return func() { c.MaxClients = int(n) }, value, nil
+	case "acllog-max-len", "slowlog-max-len":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 || n > math.MaxInt32 {
+			return nil, "", fmt.Errorf("argument must be between 0 and %d", math.MaxInt32)
+		}
+		field := &c.ACLLogMaxLen
+		if key == "slowlog-max-len" {
+			field = &c.SlowLogMaxLen
+		}
+		return func() { *field = int(n) }, value, nil
+	case "slowlog-log-slower-than":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < -1 || n > math.MaxInt64/int64(time.Microsecond) {
+			return nil, "", fmt.Errorf("argument must be between -1 and %d", math.MaxInt64/int64(time.Microsecond))
+		}
+		return func() { c.SlowLogSlowerThan = time.Duration(n) * time.Microsecond }, value, nil
+	case "save":
+		points, err := parseSavePoints(value)
+		if err != nil {
+			return nil, "", err
+		}
+		return func() { c.SavePoints = points }, formatSavePoints(points), nil
+	case "protected-mode", "stop-writes-on-bgsave-error":
+		enabled, err := parseBool(value)
+		if err != nil {
+			return nil, "", err
+		}
+		field := &c.ProtectedMode
+		if key == "stop-writes-on-bgsave-error" {
+			field = &c.StopWritesOnBgsaveError
+		}
+		return func() { *field = enabled }, formatBool(enabled), nil
+	case "requirepass":
+		return func() { c.RequirePass = value }, value, nil
+	case "tls-cert-file":
+		return func() { c.TLS.CertFile = value }, value, nil
+	case "tls-key-file":
+		return func() { c.TLS.KeyFile = value }, value, nil
+	case "tls-ca-cert-file":
+		return func() { c.TLS.CACertFile = value }, value, nil
+	case "tls-auth-clients":
+		value = strings.ToLower(value)
+		if value != "yes" && value != "no" && value != "optional" {
+			return nil, "", fmt.Errorf("argument must be one of yes, no or optional")
+		}
+		return func() { c.TLS.AuthClients = value }, value, nil
+	case "client-output-buffer-limit":
+		limits, err := parseOutputLimits(value, c.ClientOutputBufferLimit)
+		if err != nil {
+			return nil, "", err
+		}
+		return func() { c.ClientOutputBufferLimit = limits }, formatOutputLimits(limits), nil
+	case "shutdown-timeout", "timeout", "tcp-keepalive":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < 0 || n > math.MaxInt32 {
+			return nil, "", fmt.Errorf("argument must be between 0 and %d", math.MaxInt32)
+		}
+		field := &c.ShutdownTimeout
+		switch key {
+		case "timeout":
+			field = &c.Timeout
+		case "tcp-keepalive":
+			field = &c.TCPKeepalive
+		}
+		return func() { *field = time.Duration(n) * time.Second }, value, nil
+	default:
+		return nil, "", fmt.Errorf("unknown or immutable option '%s'", key)
+	}
+}
+
+// OnChange registers fn to be called with the new value whenever key is
+// changed with SetString
+func (c *Config) OnChange(key string, fn func(value string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.listeners == nil {
+		c.listeners = make(map[string][]func(value string))
+	}
+	c.listeners[key] = append(c.listeners[key], fn)
+}
+
+// ProtocolLimits returns the limits applied when parsing client commands
+func (c *Config) ProtocolLimits() resp.Limits {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return resp.Limits{
+		MaxBulkLen:      c.ProtoMaxBulkLen,
+		MaxMultibulkLen: c.ProtoMaxMultibulkLen,
+		MaxQueryBuffer:  c.ClientQueryBufferLimit,
+	}
+}
+
+// OutputLimits returns the output buffer limits of every client class
+func (c *Config) OutputLimits() [client.NumClasses]client.OutputLimit {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.ClientOutputBufferLimit
+}
+
+// GetShutdownTimeout returns how long a shutdown waits for replicas
+func (c *Config) GetShutdownTimeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.ShutdownTimeout
+}
+
+// GetTimeout returns how long clients may stay idle, or zero for no limit
+func (c *Config) GetTimeout() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.Timeout
+}
+
+// GetTCPKeepalive returns the keepalive period of client connections, or
+// zero to disable keepalives
+func (c *Config) GetTCPKeepalive() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.TCPKeepalive
+}
+
+// GetSlowLogSlowerThan returns the execution time from which commands are
+// recorded in the slow log; negative means never
+func (c *Config) GetSlowLogSlowerThan() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.SlowLogSlowerThan
+}
+
+// GetSavePoints returns the save points triggering background saves
+func (c *Config) GetSavePoints() []rdb.SavePoint {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.SavePoints
+}
+
+// GetStopWritesOnBgsaveError reports whether writes are refused while
+// saving to disk fails
+func (c *Config) GetStopWritesOnBgsaveError() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.StopWritesOnBgsaveError
+}
+
+// GetProtectedMode reports whether protected mode is enabled
+func (c *Config) GetProtectedMode() bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.ProtectedMode
+}
+
+// GetMaxClients returns the maximum number of connected clients
+func (c *Config) GetMaxClients() int {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.MaxClients
+}
+
+// TLSSettings returns the current TLS certificate settings
+func (c *Config) TLSSettings() TLSSettings {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.TLS
+}
+
 // GetReplicationInfo returns the replication information
 func (c *Config) GetReplicationInfo() string {
 	return c.ReplicationConfig.GetReplicationInfo()
@@ -0,0 +1,104 @@
+package config
+
+import "testing"
+
+// setTests are configuration changes, with the value read back afterwards
+var setTests = []struct {
+	key, value string
+	want       string
+	wantErr    bool
+}{
+	{"notify-keyspace-events", "Ex", "xE", false},
+	{"notify-keyspace-events", "KEA", "AKE", false},
+	{"notify-keyspace-events", "g$lshzxetKE", "AKE", false},
+	{"notify-keyspace-events", "", "", false},
+	{"notify-keyspace-events", "Ey", "", true},
+	{"client-output-buffer-limit", "pubsub 64mb 16mb 120", "normal 0 0 0 replica 268435456 67108864 60 pubsub 67108864 16777216 120", false},
+	{"client-output-buffer-limit", "slave 1gb 0 0 normal 1kb 1k 5", "normal 1024 1000 5 replica 1073741824 0 0 pubsub 33554432 8388608 60", false},
+	{"client-output-buffer-limit", "NORMAL 1 2 3", "normal 1 2 3 replica 268435456 67108864 60 pubsub 33554432 8388608 60", false},
+	{"client-output-buffer-limit", "", "normal 0 0 0 replica 268435456 67108864 60 pubsub 33554432 8388608 60", false},
+	{"client-output-buffer-limit", "pubsub 64mb 16mb", "", true},
+	{"client-output-buffer-limit", "master 1 1 1", "", true},
+	{"client-output-buffer-limit", "pubsub lots 1 1", "", true},
+	{"client-output-buffer-limit", "pubsub 1 1 -1", "", true},
+	{"client-output-buffer-limit", "normal 1 1 1 pubsub 1 1 x", "", true},
+	{"timeout", "30", "30", false},
+	{"timeout", "0", "0", false},
+	{"timeout", "-1", "", true},
+	{"timeout", "1s", "", true},
+	{"tcp-keepalive", "60", "60", false},
+	{"tcp-keepalive", "0", "0", false},
+	{"tcp-keepalive", "2147483648", "", true},
+	{"shutdown-timeout", "2147483647", "2147483647", false},
+	{"shutdown-timeout", "ten", "", true},
+	{"maxclients", "1", "1", false},
+	{"maxclients", "0", "", true},
+	{"maxclients", "lots", "", true},
+	{"requirepass", "secret", "secret", false},
+	{"requirepass", "", "", false},
+	{"protected-mode", "no", "no", false},
+	{"protected-mode", "YES", "yes", false},
+	{"protected-mode", "maybe", "", true},
+	{"bind", "127.0.0.1", "", true},
+	{"save", "3600 1 300 100", "3600 1 300 100", false},
+	{"save", " 60   5 ", "60 5", false},
+	{"save", "900 0", "900 0", false},
+	{"save", "", "", false},
+	{"save", "60", "", true},
+	{"save", "0 1", "", true},
+	{"save", "60 -1", "", true},
+	{"save", "hour 1", "", true},
+	{"stop-writes-on-bgsave-error", "no", "no", false},
+	{"stop-writes-on-bgsave-error", "Yes", "yes", false},
+	{"stop-writes-on-bgsave-error", "sometimes", "", true},
+}
+
+func TestSetString(t *testing.T) {
+	for _, tt := range setTests {
+		t.Run(tt.key+"="+tt.value, func(t *testing.T) {
+			c := NewConfig()
+			before, _ := c.GetString(tt.key)
+
+			var notified []string
+			c.OnChange(tt.key, func(value string) { notified = append(notified, value) })
+			err := c.SetString(tt.key, tt.value)
+			got, _ := c.GetString(tt.key)
+
+			switch {
+			case tt.wantErr:
+				if err == nil {
+					t.Errorf("SetString accepted %q", tt.value)
+				}
+				if got != before {
+					t.Errorf("value %q after a failed SetString, want %q", got, before)
+				}
+				if len(notified) != 0 {
+					t.Errorf("listeners notified of a failed SetString")
+				}
+			case err != nil:
+				t.Errorf("SetString(%q) = %v", tt.value, err)
+			case got != tt.want:
+				t.Errorf("value %q, want %q", got, tt.want)
+			case len(notified) != 1 || notified[0] != tt.want:
+				t.Errorf("listeners notified with %q, want [%q]", notified, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetUnknown(t *testing.T) {
+	if err := NewConfig().SetString("no-such-option", "1"); err == nil {
+		t.Error("SetString accepted an unknown option")
+	}
+}
+
+// The default save points are those of Redis
+func TestDefaultSavePoints(t *testing.T) {
+	c := NewConfig()
+	if got, _ := c.GetString("save"); got != "3600 1 300 100 60 10000" {
+		t.Errorf("save = %q, want Redis's defaults", got)
+	}
+	if !c.GetStopWritesOnBgsaveError() {
+		t.Error("stop-writes-on-bgsave-error disabled by default")
+	}
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+)
+
+// parseOutputLimits parses a client-output-buffer-limit value, a list of
+// "<class> <hard> <soft> <soft-seconds>" groups, and applies it on top of
+// base. Classes not mentioned keep their current limits.
+func parseOutputLimits(value string, base [client.NumClasses]client.OutputLimit) ([client.NumClasses]client.OutputLimit, error) {
+	fields := strings.Fields(value)
+	if len(fields)%4 != 0 {
+		return base, fmt.Errorf("wrong number of arguments in buffer limit configuration")
+	}
+
+	limits := base
+	for i := 0; i < len(fields); i += 4 {
+		class, ok := client.ParseClass(fields[i])
+		if !ok {
+			return base, fmt.Errorf("invalid client class specified in buffer limit configuration")
+		}
+
+		hard, err := ParseMemory(fields[i+1])
+		if err != nil {
+			return base, fmt.Errorf("error in hard, soft or soft_seconds setting in buffer limit configuration")
+		}
+		soft, err := ParseMemory(fields[i+2])
+		if err != nil {
+			return base, fmt.Errorf("error in hard, soft or soft_seconds setting in buffer limit configuration")
+		}
+		seconds, err := strconv.ParseInt(fields[i+3], 10, 64)
+		if err != nil || seconds < 0 {
+			return base, fmt.Errorf("error in hard, soft or soft_seconds setting in buffer limit configuration")
+		}
+
+		limits[class] = client.OutputLimit{
+			Hard:        hard,
+			Soft:        soft,
+			SoftSeconds: time.Duration(seconds) * time.Second,
+		}
+	}
+
+	return limits, nil
+}
+
+// formatOutputLimits formats limits the way CONFIG GET reports them
+func formatOutputLimits(limits [client.NumClasses]client.OutputLimit) string {
+	groups := make([]string, 0, client.NumClasses)
+	for class, limit := range limits {
+		groups = append(groups, fmt.Sprintf("%s %d %d %d",
+			client.Class(class), limit.Hard, limit.Soft, int64(limit.SoftSeconds/time.Second)))
+	}
+
+	return strings.Join(groups, " ")
+}
@@ -0,0 +1,46 @@
+package config
+
+import (
+	"errors"
+	"strings"
+)
+
+// Rename is a rename-command directive: clients call the command Name by
+// NewName instead. An empty NewName disables the command.
+type Rename struct {
+	Name    string
+	NewName string
+}
+
+// renameFlag collects repeated rename-command flags, each holding a command
+// name and its new name, e.g. 'KEYS MYKEYS'. A missing or "" new name
+// disables the command.
+type renameFlag []Rename
+
+// String returns the directives as given on the command line
+func (f *renameFlag) String() string {
+	directives := make([]string, len(*f))
+	for i, r := range *f {
+		newName := r.NewName
+		if newName == "" {
+			newName = `""`
+		}
+		directives[i] = r.Name + " " + newName
+	}
+	return strings.Join(directives, ", ")
+}
+
+// Set adds a directive
+func (f *renameFlag) Set(value string) error {
+	fields := strings.Fields(value)
+	if len(fields) == 0 || len(fields) > 2 {
+		return errors.New("expected a command name and its new name")
+	}
+
+	r := Rename{Name: fields[0]}
+	if len(fields) == 2 && fields[1] != `""` {
+		r.NewName = fields[1]
+	}
+	*f = append(*f, r)
+	return nil
+}
@@ -0,0 +1,51 @@
+package config
+
+import "testing"
+
+func TestRenameFlag(t *testing.T) {
+	tests := []struct {
+		values     []string
+		want       []Rename
+		wantString string
+		wantErr    bool
+	}{
+		{[]string{"KEYS MYKEYS"}, []Rename{{"KEYS", "MYKEYS"}}, "KEYS MYKEYS", false},
+		{[]string{"FLUSHALL", `CONFIG ""`}, []Rename{{"FLUSHALL", ""}, {"CONFIG", ""}}, `FLUSHALL "", CONFIG ""`, false},
+		{[]string{"  KEYS   MYKEYS "}, []Rename{{"KEYS", "MYKEYS"}}, "KEYS MYKEYS", false},
+		{[]string{""}, nil, "", true},
+		{[]string{"KEYS MY KEYS"}, nil, "", true},
+	}
+
+	for _, tt := range tests {
+		var f renameFlag
+		var err error
+		for _, value := range tt.values {
+			if err = f.Set(value); err != nil {
+				break
+			}
+		}
+
+		if tt.wantErr {
+			if err == nil {
+				t.Errorf("Set(%q) accepted", tt.values)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Set(%q) = %v", tt.values, err)
+			continue
+		}
+		if len(f) != len(tt.want) {
+			t.Errorf("Set(%q) = %v, want %v", tt.values, f, tt.want)
+			continue
+		}
+		for i := range f {
+			if f[i] != tt.want[i] {
+				t.Errorf("Set(%q) = %v, want %v", tt.values, f, tt.want)
+			}
+		}
+		if got := f.String(); got != tt.wantString {
+			t.Errorf("String() = %q, want %q", got, tt.wantString)
+		}
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/rdb"
+)
+
+// defaultSavePoints saves after an hour if anything changed, after 5 minutes
+// if 100 keys changed and after a minute if 10000 did
+var defaultSavePoints = []rdb.SavePoint{
+	{Interval: 3600 * time.Second, Changes: 1},
+	{Interval: 300 * time.Second, Changes: 100},
+	{Interval: 60 * time.Second, Changes: 10000},
+}
+
+// parseSavePoints parses save points given as pairs of seconds and changes,
+// e.g. "3600 1 300 100". An empty value disables them.
+func parseSavePoints(s string) ([]rdb.SavePoint, error) {
+	fields := strings.Fields(s)
+	if len(fields)%2 != 0 {
+		return nil, errors.New("Invalid save parameters")
+	}
+
+	points := make([]rdb.SavePoint, 0, len(fields)/2)
+	for i := 0; i < len(fields); i += 2 {
+		seconds, err := strconv.ParseInt(fields[i], 10, 32)
+		if err != nil || seconds < 1 {
+			return nil, errors.New("Invalid save parameters")
+		}
+		changes, err := strconv.ParseInt(fields[i+1], 10, 64)
+		if err != nil || changes < 0 {
+			return nil, errors.New("Invalid save parameters")
+		}
+		points = append(points, rdb.SavePoint{Interval: time.Duration(seconds) * time.Second, Changes: changes})
+	}
+	return points, nil
+}
+
+// formatSavePoints formats save points as parseSavePoints expects them
+func formatSavePoints(points []rdb.SavePoint) string {
+	fields := make([]string, 0, len(points)*2)
+	for _, p := range points {
+		fields = append(fields, strconv.FormatInt(int64(p.Interval/time.Second), 10), strconv.FormatInt(p.Changes, 10))
+	}
+	return strings.Join(fields, " ")
+}
@@ -0,0 +1,59 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+)
+
+// TLSSettings locate the certificates used for TLS connections
+type TLSSettings struct {
+	CertFile   string
+	KeyFile    string
+	CACertFile string
+	// AuthClients is "yes" to require client certificates signed by the
+	// CA, "optional" to verify them only when presented, or "no"
+	AuthClients string
+}
+
+// Load reads the certificate files and builds a server TLS configuration
+func (s TLSSettings) Load() (*tls.Config, error) {
+	if s.CertFile == "" || s.KeyFile == "" {
+		return nil, fmt.Errorf("tls-cert-file and tls-key-file must be set")
+	}
+
+	cert, err := tls.LoadX509KeyPair(s.CertFile, s.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load certificate: %w", err)
+	}
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+		ClientAuth:   tls.NoClientCert,
+	}
+	if s.AuthClients == "no" {
+		return tlsConfig, nil
+	}
+
+	if s.CACertFile == "" {
+		return nil, fmt.Errorf("tls-ca-cert-file must be set to authenticate clients")
+	}
+	pem, err := os.ReadFile(s.CACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load CA certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pem) {
+		return nil, fmt.Errorf("no certificates found in %s", s.CACertFile)
+	}
+
+	tlsConfig.ClientCAs = pool
+	if s.AuthClients == "optional" {
+		tlsConfig.ClientAuth = tls.VerifyClientCertIfGiven
+	} else {
+		tlsConfig.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return tlsConfig, nil
+}
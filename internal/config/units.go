@@ -0,0 +1,65 @@
+package config
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// memoryUnits maps the unit suffixes accepted in memory sizes to their
+// multipliers. Like Redis, "k" means 1000 bytes while "kb" means 1024.
+var memoryUnits = []struct {
+	suffix     string
+	multiplier int64
+}{
+	{"gb", 1024 * 1024 * 1024},
+	{"mb", 1024 * 1024},
+	{"kb", 1024},
+	{"g", 1000 * 1000 * 1000},
+	{"m", 1000 * 1000},
+	{"k", 1000},
+	{"b", 1},
+}
+
+// ParseMemory parses a memory size such as "512mb" or "1gb" into bytes
+func ParseMemory(s string) (int64, error) {
+	lower := strings.ToLower(strings.TrimSpace(s))
+	multiplier := int64(1)
+	for _, unit := range memoryUnits {
+		if strings.HasSuffix(lower, unit.suffix) {
+			lower = strings.TrimSuffix(lower, unit.suffix)
+			multiplier = unit.multiplier
+			break
+		}
+	}
+
+	// Sizes that don't fit in 64 bits would wrap around, possibly to a
+	// small or negative limit
+	n, err := strconv.ParseInt(lower, 10, 64)
+	if err != nil || n < 0 || n > math.MaxInt64/multiplier {
+		return 0, fmt.Errorf("argument must be a memory value")
+	}
+
+	return n * multiplier, nil
+}
+
+// parseBool parses a yes/no configuration value
+func parseBool(s string) (bool, error) {
+	switch strings.ToLower(s) {
+	case "yes":
+		return true, nil
+	case "no":
+		return false, nil
+	default:
+		return false, fmt.Errorf("argument must be 'yes' or 'no'")
+	}
+}
+
+// formatBool formats a yes/no configuration value
+func formatBool(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
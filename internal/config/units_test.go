@@ -0,0 +1,65 @@
+package config
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseMemory(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    int64
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"1024", 1024, false},
+		{"1b", 1, false},
+		{"1k", 1000, false},
+		{"1kb", 1024, false},
+		{"1KB", 1024, false},
+		{"512mb", 512 * 1024 * 1024, false},
+		{"2m", 2000 * 1000, false},
+		{"1gb", 1024 * 1024 * 1024, false},
+		{"3g", 3 * 1000 * 1000 * 1000, false},
+		{" 1mb ", 1024 * 1024, false},
+		{"9223372036854775807", math.MaxInt64, false},
+		{"8589934591gb", 8589934591 * 1024 * 1024 * 1024, false},
+		{"8589934592gb", 0, true},
+		{"9223372036854775807kb", 0, true},
+		{"9223372036854775808", 0, true},
+		{"-1", 0, true},
+		{"1tb", 0, true},
+		{"mb", 0, true},
+		{"", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseMemory(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseMemory(%q) = %d, %v, want %d, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+func TestProtocolLimitsConfig(t *testing.T) {
+	tests := []struct {
+		key, value string
+		wantErr    bool
+	}{
+		{"proto-max-bulk-len", "1mb", false},
+		{"proto-max-bulk-len", "1048575", true},
+		{"proto-max-bulk-len", "8589934592gb", true},
+		{"client-query-buffer-limit", "2gb", false},
+		{"client-query-buffer-limit", "99999999999gb", true},
+		{"proto-max-multibulk-len", "1", false},
+		{"proto-max-multibulk-len", "0", true},
+		{"proto-max-multibulk-len", "2147483648", true},
+	}
+
+	for _, tt := range tests {
+		c := NewConfig()
+		if err := c.SetString(tt.key, tt.value); (err != nil) != tt.wantErr {
+			t.Errorf("set %s %s = %v, want error %v", tt.key, tt.value, err, tt.wantErr)
+		}
+	}
+}
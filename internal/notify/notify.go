@@ -0,0 +1,158 @@
+package notify
+
+import (
+	"fmt"
+	"strings"
+	"sync/atomic"
+)
+
+// Class is a set of keyspace event classes, as selected by the
+// notify-keyspace-events configuration flags
+type Class uint32
+
+const (
+	// Keyspace publishes events to __keyspace@<db>__:<key> (flag K)
+	Keyspace Class = 1 << iota
+	// Keyevent publishes events to __keyevent@<db>__:<event> (flag E)
+	Keyevent
+	// Generic covers type-independent commands like DEL and EXPIRE (flag g)
+	Generic
+	// String covers string commands (flag $)
+	String
+	// List covers list commands (flag l)
+	List
+	// Set covers set commands (flag s)
+	Set
+	// Hash covers hash commands (flag h)
+	Hash
+	// ZSet covers sorted set commands (flag z)
+	ZSet
+	// Expired covers keys deleted because their TTL elapsed (flag x)
+	Expired
+	// Evicted covers keys evicted for maxmemory (flag e)
+	Evicted
+	// Stream covers stream commands (flag t)
+	Stream
+	// KeyMiss covers accesses to keys that do not exist (flag m)
+	KeyMiss
+	// New covers keys added to the keyspace (flag n)
+	New
+)
+
+// All is the alias selected by the A flag. Like Redis, it excludes the
+// key-miss and new-key classes, which must be enabled explicitly.
+const All = Generic | String | List | Set | Hash | ZSet | Expired | Evicted | Stream
+
+// classFlags maps each class to its configuration character, in the order
+// Redis uses when printing the flags back
+var classFlags = []struct {
+	class Class
+	flag  byte
+}{
+	{Generic, 'g'},
+	{String, '$'},
+	{List, 'l'},
+	{Set, 's'},
+	{Hash, 'h'},
+	{ZSet, 'z'},
+	{Expired, 'x'},
+	{Evicted, 'e'},
+	{Stream, 't'},
+	{Keyspace, 'K'},
+	{Keyevent, 'E'},
+	{KeyMiss, 'm'},
+	{New, 'n'},
+}
+
+// ParseClasses parses a notify-keyspace-events flag string such as "Ex" or "KEA"
+func ParseClasses(flags string) (Class, error) {
+	var classes Class
+	for i := 0; i < len(flags); i++ {
+		if flags[i] == 'A' {
+			classes |= All
+			continue
+		}
+
+		found := false
+		for _, cf := range classFlags {
+			if cf.flag == flags[i] {
+				classes |= cf.class
+				found = true
+				break
+			}
+		}
+		if !found {
+			return 0, fmt.Errorf("invalid event class character '%c'", flags[i])
+		}
+	}
+
+	return classes, nil
+}
+
+// String returns the flag string for the classes, collapsing to 'A' when
+// every class it covers is set
+func (c Class) String() string {
+	var b strings.Builder
+	rest := c
+	if c&All == All {
+		b.WriteByte('A')
+		rest &^= All
+	}
+
+	for _, cf := range classFlags {
+		if rest&cf.class != 0 {
+			b.WriteByte(cf.flag)
+		}
+	}
+
+	return b.String()
+}
+
+// Publisher delivers a message to a pub/sub channel
+type Publisher interface {
+	Publish(channel string, message []byte) int
+}
+
+// Notifier publishes keyspace and keyevent notifications for the event
+// classes currently enabled
+type Notifier struct {
+	publisher Publisher
+	classes   atomic.Uint32
+}
+
+// NewNotifier creates a notifier with all notifications disabled
+func NewNotifier(publisher Publisher) *Notifier {
+	return &Notifier{publisher: publisher}
+}
+
+// Configure enables the event classes given as a notify-keyspace-events
+// flag string
+func (n *Notifier) Configure(flags string) error {
+	classes, err := ParseClasses(flags)
+	if err != nil {
+		return err
+	}
+
+	n.classes.Store(uint32(classes))
+	return nil
+}
+
+// Notify publishes an event of the given class for a key. It is a no-op on
+// a nil Notifier or when the class is not enabled.
+func (n *Notifier) Notify(class Class, event, key string) {
+	if n == nil {
+		return
+	}
+
+	classes := Class(n.classes.Load())
+	if classes&class == 0 {
+		return
+	}
+
+	if classes&Keyspace != 0 {
+		n.publisher.Publish("__keyspace@0__:"+key, []byte(event))
+	}
+	if classes&Keyevent != 0 {
+		n.publisher.Publish("__keyevent@0__:"+event, []byte(key))
+	}
+}
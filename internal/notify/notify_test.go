@@ -0,0 +1,101 @@
+package notify
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseClasses(t *testing.T) {
+	tests := []struct {
+		flags   string
+		want    Class
+		str     string
+		wantErr bool
+	}{
+		{"", 0, "", false},
+		{"Ex", Keyevent | Expired, "xE", false},
+		{"KEA", Keyspace | Keyevent | All, "AKE", false},
+		{"Kg$lshzxet", Keyspace | All, "AK", false},
+		{"Em", Keyevent | KeyMiss, "Em", false},
+		{"KEAmn", Keyspace | Keyevent | All | KeyMiss | New, "AKEmn", false},
+		{"gg", Generic, "g", false},
+		{"Kq", 0, "", true},
+		{"a", 0, "", true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseClasses(tt.flags)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseClasses(%q) = %v, %v, want %v, error %v", tt.flags, got, err, tt.want, tt.wantErr)
+			continue
+		}
+		if s := got.String(); s != tt.str {
+			t.Errorf("ParseClasses(%q).String() = %q, want %q", tt.flags, s, tt.str)
+		}
+	}
+}
+
+// fakePublisher records published messages as "channel message"
+type fakePublisher struct {
+	published []string
+}
+
+func (p *fakePublisher) Publish(channel string, message []byte) int {
+	p.published = append(p.published, channel+" "+string(message))
+	return 0
+}
+
+func TestNotify(t *testing.T) {
+	tests := []struct {
+		name  string
+		flags string
+		class Class
+		event string
+		want  []string
+	}{
+		{"disabled", "", String, "set", nil},
+		{"keyspace", "K$", String, "set", []string{"__keyspace@0__:k set"}},
+		{"keyevent", "E$", String, "set", []string{"__keyevent@0__:set k"}},
+		{"both", "KEA", Generic, "del", []string{"__keyspace@0__:k del", "__keyevent@0__:del k"}},
+		{"class not enabled", "KEg", String, "set", nil},
+		{"no channel type", "A", String, "set", nil},
+		{"expired", "Ex", Expired, "expired", []string{"__keyevent@0__:expired k"}},
+		{"A excludes key misses", "EA", KeyMiss, "keymiss", nil},
+		{"A excludes new keys", "EA", New, "new", nil},
+		{"key miss", "Em", KeyMiss, "keymiss", []string{"__keyevent@0__:keymiss k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := &fakePublisher{}
+			n := NewNotifier(p)
+			if err := n.Configure(tt.flags); err != nil {
+				t.Fatal(err)
+			}
+			n.Notify(tt.class, tt.event, "k")
+			if !slices.Equal(p.published, tt.want) {
+				t.Errorf("published %q, want %q", p.published, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotifyNil(t *testing.T) {
+	var n *Notifier
+	n.Notify(String, "set", "k")
+}
+
+func TestConfigureKeepsClassesOnError(t *testing.T) {
+	p := &fakePublisher{}
+	n := NewNotifier(p)
+	if err := n.Configure("E$"); err != nil {
+		t.Fatal(err)
+	}
+	if err := n.Configure("E$?"); err == nil {
+		t.Fatal("Configure accepted an invalid flag")
+	}
+	n.Notify(String, "set", "k")
+	if len(p.published) != 1 {
+		t.Errorf("published %q after a failed Configure, want the previous classes in effect", p.published)
+	}
+}
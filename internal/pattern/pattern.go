@@ -0,0 +1,120 @@
+package pattern
+
+// Match reports whether str matches the glob-style pattern, following the
+// same rules Redis uses for KEYS and PSUBSCRIBE: '*' matches any sequence,
+// '?' matches a single character, '[...]' matches a character class (with
+// ranges and '^' negation) and a backslash escapes the next character.
+func Match(pattern, str string) bool {
+	return match(pattern, str, false)
+}
+
+// MatchFold is like Match but compares characters case-insensitively
+func MatchFold(pattern, str string) bool {
+	return match(pattern, str, true)
+}
+
+func match(pattern, str string, fold bool) bool {
+	p, s := 0, 0
+	for p < len(pattern) {
+		switch pattern[p] {
+		case '*':
+			// Collapse consecutive stars
+			for p+1 < len(pattern) && pattern[p+1] == '*' {
+				p++
+			}
+			if p+1 == len(pattern) {
+				return true
+			}
+			for i := s; i <= len(str); i++ {
+				if match(pattern[p+1:], str[i:], fold) {
+					return true
+				}
+			}
+			return false
+		case '?':
+			if s >= len(str) {
+				return false
+			}
+			s++
+		case '[':
+			if s >= len(str) {
+				return false
+			}
+			next, ok := matchClass(pattern, p+1, str[s], fold)
+			if !ok {
+				return false
+			}
+			p = next
+			s++
+		case '\\':
+			if p+1 < len(pattern) {
+				p++
+			}
+			fallthrough
+		default:
+			if s >= len(str) || !equal(pattern[p], str[s], fold) {
+				return false
+			}
+			s++
+		}
+		p++
+	}
+
+	return s == len(str)
+}
+
+// matchClass matches c against the character class starting at pattern[p]
+// (just after the opening bracket). It returns the index of the closing
+// bracket and whether c is part of the class.
+func matchClass(pattern string, p int, c byte, fold bool) (int, bool) {
+	negate := p < len(pattern) && pattern[p] == '^'
+	if negate {
+		p++
+	}
+
+	matched := false
+	for ; p < len(pattern); p++ {
+		switch {
+		case pattern[p] == ']':
+			return p, matched != negate
+		case pattern[p] == '\\' && p+1 < len(pattern):
+			p++
+			if equal(pattern[p], c, fold) {
+				matched = true
+			}
+		case p+2 < len(pattern) && pattern[p+1] == '-':
+			start, end := pattern[p], pattern[p+2]
+			if start > end {
+				start, end = end, start
+			}
+			if fold {
+				start, end, c = lower(start), lower(end), lower(c)
+			}
+			if c >= start && c <= end {
+				matched = true
+			}
+			p += 2
+		default:
+			if equal(pattern[p], c, fold) {
+				matched = true
+			}
+		}
+	}
+
+	// Unterminated class: Redis treats the end of the pattern as the end of the class
+	return len(pattern) - 1, matched != negate
+}
+
+func equal(a, b byte, fold bool) bool {
+	if fold {
+		return lower(a) == lower(b)
+	}
+	return a == b
+}
+
+func lower(c byte) byte {
+	if c >= 'A' && c <= 'Z' {
+		return c + ('a' - 'A')
+	}
+	return c
+}
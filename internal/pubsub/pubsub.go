@@ -0,0 +1,251 @@
+package pubsub
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/pattern"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// Kind identifies one of the independent subscription namespaces
+type Kind int
+
+const (
+	// Channel is a classic channel subscription (SUBSCRIBE)
+	Channel Kind = iota
+	// Pattern is a classic glob-style pattern subscription (PSUBSCRIBE)
+	Pattern
+	// ShardChannel is a sharded channel subscription (SSUBSCRIBE). Shard
+	// channels live in their own namespace: a PUBLISH never reaches an
+	// SSUBSCRIBE-r and an SPUBLISH never reaches a SUBSCRIBE-r.
+	ShardChannel
+)
+
+// Subscriber receives messages published to the channels it subscribed to
+type Subscriber interface {
+	// Push delivers an out-of-band message to the subscriber
+	Push(value resp.RedisValue) error
+}
+
+// namespace tracks subscriptions in both directions so that publishing and
+// per-subscriber bookkeeping are both cheap
+type namespace struct {
+	subscribers map[string]map[Subscriber]struct{}
+	names       map[Subscriber]map[string]struct{}
+}
+
+func newNamespace() *namespace {
+	return &namespace{
+		subscribers: make(map[string]map[Subscriber]struct{}),
+		names:       make(map[Subscriber]map[string]struct{}),
+	}
+}
+
+func (n *namespace) add(sub Subscriber, name string) {
+	if n.subscribers[name] == nil {
+		n.subscribers[name] = make(map[Subscriber]struct{})
+	}
+	n.subscribers[name][sub] = struct{}{}
+
+	if n.names[sub] == nil {
+		n.names[sub] = make(map[string]struct{})
+	}
+	n.names[sub][name] = struct{}{}
+}
+
+func (n *namespace) remove(sub Subscriber, name string) {
+	if subs, ok := n.subscribers[name]; ok {
+		delete(subs, sub)
+		if len(subs) == 0 {
+			delete(n.subscribers, name)
+		}
+	}
+
+	if names, ok := n.names[sub]; ok {
+		delete(names, name)
+		if len(names) == 0 {
+			delete(n.names, sub)
+		}
+	}
+}
+
+// Hub routes published messages to subscribers
+type Hub struct {
+	mu         sync.RWMutex
+	namespaces map[Kind]*namespace
+}
+
+// NewHub creates an empty pub/sub hub
+func NewHub() *Hub {
+	return &Hub{
+		namespaces: map[Kind]*namespace{
+			Channel:      newNamespace(),
+			Pattern:      newNamespace(),
+			ShardChannel: newNamespace(),
+		},
+	}
+}
+
+// Subscribe adds a subscription and returns the subscriber's resulting
+// subscription count as reported in the (S|P)SUBSCRIBE reply
+func (h *Hub) Subscribe(kind Kind, sub Subscriber, name string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.namespaces[kind].add(sub, name)
+	return h.replyCount(kind, sub)
+}
+
+// Unsubscribe removes a subscription and returns the subscriber's resulting
+// subscription count as reported in the (S|P)UNSUBSCRIBE reply
+func (h *Hub) Unsubscribe(kind Kind, sub Subscriber, name string) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.namespaces[kind].remove(sub, name)
+	return h.replyCount(kind, sub)
+}
+
+// Subscriptions returns the names a subscriber is subscribed to in a namespace
+func (h *Hub) Subscriptions(kind Kind, sub Subscriber) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	names := make([]string, 0, len(h.namespaces[kind].names[sub]))
+	for name := range h.namespaces[kind].names[sub] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// IsSubscribed reports whether the subscriber has any active subscription
+func (h *Hub) IsSubscribed(sub Subscriber) bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for _, ns := range h.namespaces {
+		if len(ns.names[sub]) > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// RemoveSubscriber drops every subscription held by a subscriber, e.g. when
+// its connection is closed
+func (h *Hub) RemoveSubscriber(sub Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for _, ns := range h.namespaces {
+		for name := range ns.names[sub] {
+			ns.remove(sub, name)
+		}
+	}
+}
+
+// Publish sends a message to classic channel and pattern subscribers and
+// returns the number of subscribers that received it
+func (h *Hub) Publish(channel string, message []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	received := 0
+	msg := resp.Push{Values: []resp.RedisValue{
+		resp.NewBulkString("message"),
+		resp.NewBulkString(channel),
+		resp.BulkString{Value: message},
+	}}
+	for sub := range h.namespaces[Channel].subscribers[channel] {
+		if sub.Push(msg) == nil {
+			received++
+		}
+	}
+
+	for pat, subs := range h.namespaces[Pattern].subscribers {
+		if !pattern.Match(pat, channel) {
+			continue
+		}
+
+		pmsg := resp.Push{Values: []resp.RedisValue{
+			resp.NewBulkString("pmessage"),
+			resp.NewBulkString(pat),
+			resp.NewBulkString(channel),
+			resp.BulkString{Value: message},
+		}}
+		for sub := range subs {
+			if sub.Push(pmsg) == nil {
+				received++
+			}
+		}
+	}
+
+	return received
+}
+
+// SPublish sends a message to shard channel subscribers and returns the
+// number of subscribers that received it
+func (h *Hub) SPublish(channel string, message []byte) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	received := 0
+	msg := resp.Push{Values: []resp.RedisValue{
+		resp.NewBulkString("smessage"),
+		resp.NewBulkString(channel),
+		resp.BulkString{Value: message},
+	}}
+	for sub := range h.namespaces[ShardChannel].subscribers[channel] {
+		if sub.Push(msg) == nil {
+			received++
+		}
+	}
+
+	return received
+}
+
+// ActiveNames returns the channels (or patterns) in a namespace that have at
+// least one subscriber, optionally filtered by a glob-style pattern
+func (h *Hub) ActiveNames(kind Kind, filter string) []string {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	names := make([]string, 0)
+	for name := range h.namespaces[kind].subscribers {
+		if filter == "" || pattern.Match(filter, name) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// NumSubscribers returns the number of subscribers of a channel in a namespace
+func (h *Hub) NumSubscribers(kind Kind, name string) int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.namespaces[kind].subscribers[name])
+}
+
+// NumPatterns returns the number of unique patterns subscribed to
+func (h *Hub) NumPatterns() int {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	return len(h.namespaces[Pattern].subscribers)
+}
+
+// replyCount returns the count reported in subscribe/unsubscribe replies:
+// classic replies count channels and patterns together, sharded replies
+// count only shard channels
+func (h *Hub) replyCount(kind Kind, sub Subscriber) int {
+	if kind == ShardChannel {
+		return len(h.namespaces[ShardChannel].names[sub])
+	}
+	return len(h.namespaces[Channel].names[sub]) + len(h.namespaces[Pattern].names[sub])
+}
@@ -0,0 +1,162 @@
+package pubsub
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+)
+
+// fakeSubscriber records the messages pushed to it, in RESP2
+type fakeSubscriber struct {
+	messages []string
+	err      error
+}
+
+func (s *fakeSubscriber) Push(value resp.RedisValue) error {
+	if s.err != nil {
+		return s.err
+	}
+	s.messages = append(s.messages, string(value.Serialize(resp.RESP2)))
+	return nil
+}
+
+func TestShardChannelsAreSeparate(t *testing.T) {
+	tests := []struct {
+		name    string
+		kind    Kind
+		sub     string
+		sharded bool
+		want    int
+	}{
+		{"channel receives PUBLISH", Channel, "news", false, 1},
+		{"channel ignores SPUBLISH", Channel, "news", true, 0},
+		{"pattern receives PUBLISH", Pattern, "n*", false, 1},
+		{"pattern ignores SPUBLISH", Pattern, "n*", true, 0},
+		{"shard channel receives SPUBLISH", ShardChannel, "news", true, 1},
+		{"shard channel ignores PUBLISH", ShardChannel, "news", false, 0},
+		{"shard channels are not patterns", ShardChannel, "n*", true, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub()
+			sub := &fakeSubscriber{}
+			h.Subscribe(tt.kind, sub, tt.sub)
+
+			publish := h.Publish
+			if tt.sharded {
+				publish = h.SPublish
+			}
+			if got := publish("news", []byte("hi")); got != tt.want {
+				t.Errorf("received by %d subscribers, want %d", got, tt.want)
+			}
+			if len(sub.messages) != tt.want {
+				t.Errorf("got %d messages, want %d", len(sub.messages), tt.want)
+			}
+		})
+	}
+}
+
+func TestMessages(t *testing.T) {
+	h := NewHub()
+	sub := &fakeSubscriber{}
+	h.Subscribe(Channel, sub, "news")
+	h.Subscribe(Pattern, sub, "n*")
+	h.Subscribe(ShardChannel, sub, "news")
+
+	h.Publish("news", []byte("a"))
+	h.SPublish("news", []byte("b"))
+
+	want := []string{
+		"*3\r\n$7\r\nmessage\r\n$4\r\nnews\r\n$1\r\na\r\n",
+		"*4\r\n$8\r\npmessage\r\n$2\r\nn*\r\n$4\r\nnews\r\n$1\r\na\r\n",
+		"*3\r\n$8\r\nsmessage\r\n$4\r\nnews\r\n$1\r\nb\r\n",
+	}
+	if !slices.Equal(sub.messages, want) {
+		t.Errorf("messages %q, want %q", sub.messages, want)
+	}
+}
+
+func TestReplyCounts(t *testing.T) {
+	h := NewHub()
+	sub := &fakeSubscriber{}
+
+	// Classic replies count channels and patterns, sharded ones only shard
+	// channels
+	steps := []struct {
+		subscribe bool
+		kind      Kind
+		name      string
+		want      int
+	}{
+		{true, Channel, "a", 1},
+		{true, Pattern, "p*", 2},
+		{true, ShardChannel, "s1", 1},
+		{true, ShardChannel, "s2", 2},
+		{true, ShardChannel, "s2", 2},
+		{true, Channel, "b", 3},
+		{false, ShardChannel, "s1", 1},
+		{false, Channel, "a", 2},
+		{false, ShardChannel, "missing", 1},
+	}
+	for i, step := range steps {
+		var got int
+		if step.subscribe {
+			got = h.Subscribe(step.kind, sub, step.name)
+		} else {
+			got = h.Unsubscribe(step.kind, sub, step.name)
+		}
+		if got != step.want {
+			t.Errorf("step %d: count %d, want %d", i, got, step.want)
+		}
+	}
+
+	if got := h.Subscriptions(ShardChannel, sub); !slices.Equal(got, []string{"s2"}) {
+		t.Errorf("shard subscriptions %q, want [s2]", got)
+	}
+}
+
+func TestShardChannelIntrospection(t *testing.T) {
+	h := NewHub()
+	a, b := &fakeSubscriber{}, &fakeSubscriber{}
+	h.Subscribe(ShardChannel, a, "orders")
+	h.Subscribe(ShardChannel, b, "orders")
+	h.Subscribe(ShardChannel, b, "users")
+	h.Subscribe(Channel, a, "classic")
+
+	if got := h.ActiveNames(ShardChannel, ""); !slices.Equal(got, []string{"orders", "users"}) {
+		t.Errorf("active shard channels %q", got)
+	}
+	if got := h.ActiveNames(ShardChannel, "u*"); !slices.Equal(got, []string{"users"}) {
+		t.Errorf("active shard channels matching u* %q", got)
+	}
+	if got := h.NumSubscribers(ShardChannel, "orders"); got != 2 {
+		t.Errorf("orders has %d subscribers, want 2", got)
+	}
+	if got := h.NumSubscribers(Channel, "orders"); got != 0 {
+		t.Errorf("classic orders has %d subscribers, want 0", got)
+	}
+
+	h.RemoveSubscriber(b)
+	if got := h.ActiveNames(ShardChannel, ""); !slices.Equal(got, []string{"orders"}) {
+		t.Errorf("active shard channels after removal %q", got)
+	}
+	if h.IsSubscribed(b) {
+		t.Error("removed subscriber still subscribed")
+	}
+	if !h.IsSubscribed(a) {
+		t.Error("remaining subscriber not subscribed")
+	}
+}
+
+func TestSPublishSkipsFailedSubscribers(t *testing.T) {
+	h := NewHub()
+	h.Subscribe(ShardChannel, &fakeSubscriber{}, "news")
+	h.Subscribe(ShardChannel, &fakeSubscriber{err: errors.New("closed")}, "news")
+
+	if got := h.SPublish("news", []byte("hi")); got != 1 {
+		t.Errorf("received by %d subscribers, want 1", got)
+	}
+}
@@ -0,0 +1,13 @@
+package rdb
+
+import "hash/crc64"
+
+// jonesTable is the table of the Jones CRC-64 polynomial Redis checksums
+// RDB files with, in the reversed form hash/crc64 expects
+var jonesTable = crc64.MakeTable(0x95ac9329ac4bc9b5)
+
+// updateCRC adds p to a Redis CRC-64. Unlike hash/crc64, Redis neither
+// inverts the initial value nor the result, so the inversions are undone.
+func updateCRC(crc uint64, p []byte) uint64 {
+	return ^crc64.Update(^crc, jonesTable, p)
+}
@@ -0,0 +1,42 @@
+package rdb
+
+import (
+	"encoding/binary"
+	"os"
+	"testing"
+)
+
+func TestUpdateCRC(t *testing.T) {
+	tests := []struct {
+		data string
+		want uint64
+	}{
+		{"", 0},
+		{"123456789", 0xe9c6d914c4b8d9ca},
+	}
+
+	for _, tt := range tests {
+		if got := updateCRC(0, []byte(tt.data)); got != tt.want {
+			t.Errorf("updateCRC(%q) = %#x, want %#x", tt.data, got, tt.want)
+		}
+	}
+
+	// Checksums can be computed incrementally
+	if got := updateCRC(updateCRC(0, []byte("1234")), []byte("56789")); got != 0xe9c6d914c4b8d9ca {
+		t.Errorf("incremental updateCRC = %#x", got)
+	}
+}
+
+// The fixture was written by redis-server 3.2.6 and ends with the checksum
+// of everything before it
+func TestUpdateCRCMatchesRedis(t *testing.T) {
+	data, err := os.ReadFile("testdata/redis-3.2.6.rdb")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	body, sum := data[:len(data)-8], binary.LittleEndian.Uint64(data[len(data)-8:])
+	if got := updateCRC(0, body); got != sum {
+		t.Errorf("updateCRC = %#x, want %#x", got, sum)
+	}
+}
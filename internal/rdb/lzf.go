@@ -0,0 +1,75 @@
+package rdb
+
+// LZF limits: back references reach at most 8 KiB back and copy at most
+// 264 bytes, literal runs hold at most 32 bytes
+const (
+	lzfHashLog    = 14
+	lzfMaxOffset  = 1 << 13
+	lzfMaxRef     = 1<<8 + 1<<3
+	lzfMaxLiteral = 1 << 5
+)
+
+// lzfCompress compresses data in the LZF format Redis uses for strings. It
+// returns nil unless the output is at least 4 bytes shorter than data, as
+// smaller savings aren't worth the compressed encoding's overhead.
+func lzfCompress(data []byte) []byte {
+	var table [1 << lzfHashLog]int
+	out := make([]byte, 0, len(data))
+	literal := 0
+
+	for i := 0; i+2 < len(data); {
+		h := lzfHash(data[i], data[i+1], data[i+2])
+		ref := table[h] - 1
+		table[h] = i + 1
+
+		if ref < 0 || i-ref > lzfMaxOffset ||
+			data[ref] != data[i] || data[ref+1] != data[i+1] || data[ref+2] != data[i+2] {
+			i++
+			continue
+		}
+
+		out = appendLiterals(out, data[literal:i])
+
+		length := 3
+		for length < lzfMaxRef && i+length < len(data) && data[ref+length] == data[i+length] {
+			length++
+		}
+
+		offset := i - ref - 1
+		if encoded := length - 2; encoded < 7 {
+			out = append(out, byte(encoded<<5|offset>>8))
+		} else {
+			out = append(out, byte(7<<5|offset>>8), byte(encoded-7))
+		}
+		out = append(out, byte(offset))
+
+		i += length
+		literal = i
+		if len(out) >= len(data)-4 {
+			return nil
+		}
+	}
+
+	out = appendLiterals(out, data[literal:])
+	if len(out) >= len(data)-4 {
+		return nil
+	}
+	return out
+}
+
+// appendLiterals appends literal runs holding data
+func appendLiterals(out, data []byte) []byte {
+	for len(data) > 0 {
+		n := min(len(data), lzfMaxLiteral)
+		out = append(out, byte(n-1))
+		out = append(out, data[:n]...)
+		data = data[n:]
+	}
+	return out
+}
+
+// lzfHash hashes the three bytes starting a potential match
+func lzfHash(a, b, c byte) int {
+	v := uint32(a)<<16 | uint32(b)<<8 | uint32(c)
+	return int((v * 2654435761) >> (32 - lzfHashLog))
+}
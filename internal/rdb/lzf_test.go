@@ -0,0 +1,66 @@
+package rdb
+
+import (
+	"bytes"
+	"math/rand"
+	"strings"
+	"testing"
+
+	"github.com/hdt3213/rdb/lzf"
+)
+
+// randomBytes returns n bytes that don't compress
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	rand.New(rand.NewSource(1)).Read(b)
+	return b
+}
+
+func TestLZFCompress(t *testing.T) {
+	// Repeated chunks of random data, far enough apart that some references
+	// exceed the maximum offset
+	var far []byte
+	chunk := randomBytes(4096)
+	for range 8 {
+		far = append(far, chunk...)
+		far = append(far, randomBytes(5000)...)
+	}
+
+	tests := []struct {
+		name       string
+		data       []byte
+		compressed bool
+	}{
+		{"empty", nil, false},
+		{"short", []byte("abc"), false},
+		{"run", bytes.Repeat([]byte("a"), 300), true},
+		{"long run", bytes.Repeat([]byte("a"), 100000), true},
+		{"text", []byte(strings.Repeat("Key that redis should compress easily. ", 20)), true},
+		{"random", randomBytes(1000), false},
+		{"barely compressible", append(randomBytes(40), randomBytes(5)...), false},
+		{"far references", far, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := lzfCompress(tt.data)
+			if (out != nil) != tt.compressed {
+				t.Fatalf("compressed = %v, want %v", out != nil, tt.compressed)
+			}
+			if out == nil {
+				return
+			}
+			if len(out) > len(tt.data)-4 {
+				t.Errorf("compressed to %d bytes from %d, want at least 4 bytes less", len(out), len(tt.data))
+			}
+
+			got, err := lzf.Decompress(out, len(out), len(tt.data))
+			if err != nil {
+				t.Fatalf("Decompress: %v", err)
+			}
+			if !bytes.Equal(got, tt.data) {
+				t.Error("round trip changed the data")
+			}
+		})
+	}
+}
@@ -0,0 +1,321 @@
+package rdb
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ErrSaveInProgress is returned when saving while a background save runs
+var ErrSaveInProgress = errors.New("Background save already in progress")
+
+// retryDelay is how long to wait after a failed background save before
+// save points trigger another one
+const retryDelay = 5 * time.Second
+
+// SavePoint triggers a background save once Changes changes have been made
+// and Interval has passed since the last save
+type SavePoint struct {
+	Interval time.Duration
+	Changes  int64
+}
+
+// Dataset is data that can be saved to an RDB file
+type Dataset interface {
+	// Snapshot returns a point-in-time view of the data. Writes may go on
+	// while the snapshot is being saved.
+	Snapshot() Snapshot
+
+	// Dirty returns the number of changes made to the data so far
+	Dirty() int64
+}
+
+// Snapshot is a point-in-time view of a dataset
+type Snapshot interface {
+	// WriteRDB writes the keys of the snapshot, starting with SelectDB
+	WriteRDB(w *Writer)
+
+	// CopyOnWriteSize returns the memory, in bytes, the dataset has used to
+	// keep the snapshot intact while being written to
+	CopyOnWriteSize() int64
+
+	// Release frees the snapshot once it has been saved
+	Release()
+}
+
+// Saver saves a dataset to an RDB file, either blocking with Save or in the
+// background with BackgroundSave, and keeps the persistence status
+// reported in INFO
+type Saver struct {
+	dataset Dataset
+	path    func() string
+	version string
+
+	// mu guards the fields below. done is signaled whenever a save
+	// finishes. saving is set during a blocking save and inProgress during
+	// a background one; the file is written without holding mu.
+	mu           sync.Mutex
+	done         *sync.Cond
+	saving       bool
+	inProgress   bool
+	scheduled    bool
+	started      time.Time
+	lastSave     time.Time
+	lastTry      time.Time
+	lastDuration time.Duration
+
+	// lastBgErr is the error of the last background save. Like Redis's
+	// lastbgsave_status, a successful SAVE clears it but a failed one, such
+	// as on shutdown, leaves it alone.
+	lastBgErr error
+
+	// savedDirty is the dataset's change count as of the last save
+	savedDirty int64
+
+	// current is the snapshot of the running background save
+	current     Snapshot
+	lastCOWSize int64
+}
+
+// NewSaver creates a saver writing dataset to the file at path(), recording
+// version as the Redis version that wrote it
+func NewSaver(dataset Dataset, path func() string, version string) *Saver {
+	s := &Saver{dataset: dataset, path: path, version: version, lastSave: time.Now(), savedDirty: dataset.Dirty()}
+	s.done = sync.NewCond(&s.mu)
+	return s
+}
+
+// Save saves the dataset, blocking until it is on disk
+func (s *Saver) Save() error {
+	s.mu.Lock()
+	if s.saving || s.inProgress {
+		s.mu.Unlock()
+		return ErrSaveInProgress
+	}
+	return s.saveLocked()
+}
+
+// SaveOnShutdown waits for running saves to finish, then saves the dataset
+func (s *Saver) SaveOnShutdown() error {
+	s.mu.Lock()
+	s.scheduled = false
+	for s.saving || s.inProgress {
+		s.done.Wait()
+	}
+	return s.saveLocked()
+}
+
+// saveLocked saves the dataset. Callers must hold s.mu, which is released
+// while the file is written and on return.
+func (s *Saver) saveLocked() error {
+	// Changes made between reading the count and taking the snapshot are
+	// counted as unsaved, which at worst triggers an extra save
+	dirty := s.dataset.Dirty()
+	snapshot := s.dataset.Snapshot()
+	s.saving = true
+	s.lastTry = time.Now()
+	s.mu.Unlock()
+
+	err := s.write(snapshot, "temp-"+strconv.Itoa(os.Getpid())+".rdb")
+	snapshot.Release()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.saving = false
+	s.done.Broadcast()
+	if err != nil {
+		fmt.Printf("Error saving DB on disk: %v\n", err)
+		return err
+	}
+
+	fmt.Println("DB saved on disk")
+	s.lastSave = time.Now()
+	s.lastBgErr = nil
+	s.savedDirty = dirty
+	return nil
+}
+
+// BackgroundSave starts saving a snapshot of the dataset in the background.
+// If a background save is already running, it fails unless schedule is set,
+// in which case another save starts once the running one finishes and
+// scheduled is true.
+func (s *Saver) BackgroundSave(schedule bool) (scheduled bool, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.saving {
+		return false, ErrSaveInProgress
+	}
+	if s.inProgress {
+		if !schedule {
+			return false, ErrSaveInProgress
+		}
+		s.scheduled = true
+		return true, nil
+	}
+
+	s.startLocked()
+	return false, nil
+}
+
+// startLocked takes a snapshot and starts writing it in the background.
+// Callers must hold s.mu.
+func (s *Saver) startLocked() {
+	dirty := s.dataset.Dirty()
+	snapshot := s.dataset.Snapshot()
+	s.current = snapshot
+	s.inProgress = true
+	s.started = time.Now()
+	s.lastTry = s.started
+	fmt.Println("Background saving started")
+
+	go func() {
+		err := s.write(snapshot, "temp-bg-"+strconv.Itoa(os.Getpid())+".rdb")
+		cowSize := snapshot.CopyOnWriteSize()
+		snapshot.Release()
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+
+		s.current = nil
+		s.inProgress = false
+		s.lastCOWSize = cowSize
+		s.lastBgErr = err
+		s.lastDuration = time.Since(s.started)
+		if err != nil {
+			fmt.Printf("Background saving error: %v\n", err)
+		} else {
+			fmt.Printf("Background saving terminated with success, %d MB of memory used by copy-on-write\n", cowSize>>20)
+			s.lastSave = time.Now()
+			s.savedDirty = dirty
+		}
+		s.done.Broadcast()
+
+		if s.scheduled {
+			s.scheduled = false
+			s.startLocked()
+		}
+	}()
+}
+
+// CheckSavePoints starts a background save if one of points has been
+// reached. After a failed save, it waits a few seconds before trying again.
+func (s *Saver) CheckSavePoints(points []SavePoint) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.saving || s.inProgress {
+		return
+	}
+	if s.lastBgErr != nil && time.Since(s.lastTry) < retryDelay {
+		return
+	}
+
+	changes := s.dataset.Dirty() - s.savedDirty
+	elapsed := time.Since(s.lastSave)
+	for _, point := range points {
+		if changes >= point.Changes && elapsed >= point.Interval {
+			fmt.Printf("%d changes in %d seconds. Saving...\n", point.Changes, int64(point.Interval.Seconds()))
+			s.startLocked()
+			return
+		}
+	}
+}
+
+// LastBgsaveFailed reports whether the last background save failed, unless
+// a SAVE has succeeded since
+func (s *Saver) LastBgsaveFailed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastBgErr != nil
+}
+
+// write writes snapshot to a temporary file named temp, then moves it into
+// place so that the previous file stays intact if saving fails
+func (s *Saver) write(snapshot Snapshot, temp string) error {
+	path := s.path()
+	temp = filepath.Join(filepath.Dir(path), temp)
+
+	file, err := os.Create(temp)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp)
+
+	w := NewWriter(file)
+	w.Aux("redis-ver", s.version)
+	w.Aux("redis-bits", strconv.Itoa(strconv.IntSize))
+	w.Aux("ctime", strconv.FormatInt(time.Now().Unix(), 10))
+	w.Aux("aof-base", "0")
+	snapshot.WriteRDB(w)
+
+	err = w.Close()
+	if err == nil {
+		err = file.Sync()
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return err
+	}
+	return os.Rename(temp, path)
+}
+
+// LastSave returns when the dataset was last saved successfully, or when
+// the saver was created if it never was
+func (s *Saver) LastSave() time.Time {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastSave
+}
+
+// Info returns the persistence section of INFO
+func (s *Saver) Info() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	status := "ok"
+	if s.lastBgErr != nil {
+		status = "err"
+	}
+	lastDuration, current := int64(-1), int64(-1)
+	if !s.started.IsZero() {
+		lastDuration = int64(s.lastDuration.Seconds())
+	}
+	var cowSize int64
+	if s.inProgress {
+		current = int64(time.Since(s.started).Seconds())
+		cowSize = s.current.CopyOnWriteSize()
+	}
+
+	var b strings.Builder
+	b.WriteString("# Persistence\n")
+	b.WriteString("loading:0\n")
+	fmt.Fprintf(&b, "current_cow_size:%d\n", cowSize)
+	fmt.Fprintf(&b, "rdb_changes_since_last_save:%d\n", s.dataset.Dirty()-s.savedDirty)
+	fmt.Fprintf(&b, "rdb_bgsave_in_progress:%d\n", boolToInt(s.inProgress))
+	fmt.Fprintf(&b, "rdb_last_save_time:%d\n", s.lastSave.Unix())
+	fmt.Fprintf(&b, "rdb_last_bgsave_status:%s\n", status)
+	fmt.Fprintf(&b, "rdb_last_bgsave_time_sec:%d\n", lastDuration)
+	fmt.Fprintf(&b, "rdb_current_bgsave_time_sec:%d\n", current)
+	fmt.Fprintf(&b, "rdb_last_cow_size:%d\n", s.lastCOWSize)
+
+	return b.String()
+}
+
+// boolToInt formats a flag as 0 or 1, as INFO does
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,286 @@
+package rdb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeDataset is a dataset of string keys whose snapshots can be made to
+// block while they are written
+type fakeDataset struct {
+	keys  map[string]string
+	dirty atomic.Int64
+
+	// cowSize is reported as the copy-on-write size of its snapshots
+	cowSize atomic.Int64
+
+	// block, if set, is waited on by WriteRDB; writing is signaled first
+	block   chan struct{}
+	writing chan struct{}
+}
+
+type fakeSnapshot struct {
+	d    *fakeDataset
+	keys map[string]string
+}
+
+func (d *fakeDataset) Snapshot() Snapshot {
+	keys := make(map[string]string, len(d.keys))
+	for k, v := range d.keys {
+		keys[k] = v
+	}
+	return &fakeSnapshot{d: d, keys: keys}
+}
+
+func (d *fakeDataset) Dirty() int64 {
+	return d.dirty.Load()
+}
+
+func (s *fakeSnapshot) WriteRDB(w *Writer) {
+	if s.d.block != nil {
+		s.d.writing <- struct{}{}
+		<-s.d.block
+	}
+	w.SelectDB(0, len(s.keys), 0)
+	for k, v := range s.keys {
+		w.String(k, []byte(v), nil)
+	}
+}
+
+func (s *fakeSnapshot) CopyOnWriteSize() int64 { return s.d.cowSize.Load() }
+func (s *fakeSnapshot) Release()               {}
+
+func newTestSaver(t *testing.T, d *fakeDataset) (*Saver, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "dump.rdb")
+	return NewSaver(d, func() string { return path }, "7.4.0"), path
+}
+
+func TestSaveWritesFile(t *testing.T) {
+	d := &fakeDataset{keys: map[string]string{"k": "v"}}
+	saver, path := newTestSaver(t, d)
+	d.dirty.Add(3)
+
+	before := saver.LastSave()
+	time.Sleep(10 * time.Millisecond)
+	if err := saver.Save(); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("dump not written: %v", err)
+	}
+	if !saver.LastSave().After(before) {
+		t.Errorf("LastSave() not updated")
+	}
+	if got := infoField(saver.Info(), "rdb_changes_since_last_save"); got != "0" {
+		t.Errorf("rdb_changes_since_last_save = %q, want 0", got)
+	}
+}
+
+// A blocking save must not hold the saver's lock while writing, so INFO,
+// LASTSAVE and the write gate keep answering
+func TestSaveDoesNotBlockStatus(t *testing.T) {
+	d := &fakeDataset{keys: map[string]string{"k": "v"}, block: make(chan struct{}), writing: make(chan struct{})}
+	saver, _ := newTestSaver(t, d)
+
+	errs := make(chan error, 1)
+	go func() { errs <- saver.Save() }()
+	<-d.writing
+
+	done := make(chan struct{})
+	go func() {
+		saver.Info()
+		saver.LastSave()
+		saver.LastBgsaveFailed()
+		saver.CheckSavePoints([]SavePoint{{Interval: 0, Changes: 0}})
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("status calls blocked during SAVE")
+	}
+
+	if err := saver.Save(); !errors.Is(err, ErrSaveInProgress) {
+		t.Errorf("concurrent Save() = %v, want ErrSaveInProgress", err)
+	}
+	if _, err := saver.BackgroundSave(true); !errors.Is(err, ErrSaveInProgress) {
+		t.Errorf("BackgroundSave() during SAVE = %v, want ErrSaveInProgress", err)
+	}
+
+	close(d.block)
+	if err := <-errs; err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+}
+
+func TestBackgroundSave(t *testing.T) {
+	d := &fakeDataset{keys: map[string]string{"k": "v"}, block: make(chan struct{}), writing: make(chan struct{}, 2)}
+	saver, path := newTestSaver(t, d)
+
+	if scheduled, err := saver.BackgroundSave(false); scheduled || err != nil {
+		t.Fatalf("BackgroundSave() = %v, %v", scheduled, err)
+	}
+	<-d.writing
+	if got := infoField(saver.Info(), "rdb_bgsave_in_progress"); got != "1" {
+		t.Errorf("rdb_bgsave_in_progress = %q, want 1", got)
+	}
+	if _, err := saver.BackgroundSave(false); !errors.Is(err, ErrSaveInProgress) {
+		t.Errorf("second BackgroundSave() = %v, want ErrSaveInProgress", err)
+	}
+	if scheduled, err := saver.BackgroundSave(true); !scheduled || err != nil {
+		t.Errorf("BackgroundSave(schedule) = %v, %v, want scheduled", scheduled, err)
+	}
+
+	// The scheduled save starts once the first one finishes
+	d.block <- struct{}{}
+	<-d.writing
+	close(d.block)
+	if err := saver.SaveOnShutdown(); err != nil {
+		t.Fatalf("SaveOnShutdown() = %v", err)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("dump not written: %v", err)
+	}
+	if got := infoField(saver.Info(), "rdb_last_bgsave_status"); got != "ok" {
+		t.Errorf("rdb_last_bgsave_status = %q, want ok", got)
+	}
+}
+
+// INFO reports the copy-on-write size of the save in progress, and that of
+// the last background save once it is done
+func TestCopyOnWriteSizeInfo(t *testing.T) {
+	d := &fakeDataset{keys: map[string]string{"k": "v"}, block: make(chan struct{}), writing: make(chan struct{})}
+	saver, _ := newTestSaver(t, d)
+
+	steps := []struct {
+		name        string
+		step        func()
+		wantCurrent string
+		wantLast    string
+	}{
+		{"before saving", func() {}, "0", "0"},
+		{"saving", func() {
+			if _, err := saver.BackgroundSave(false); err != nil {
+				t.Fatal(err)
+			}
+			<-d.writing
+			d.cowSize.Store(4096)
+		}, "4096", "0"},
+		{"saved", func() {
+			close(d.block)
+			waitFor(t, func() bool { return infoField(saver.Info(), "rdb_bgsave_in_progress") == "0" })
+		}, "0", "4096"},
+	}
+
+	for _, step := range steps {
+		step.step()
+		info := saver.Info()
+		if got := infoField(info, "current_cow_size"); got != step.wantCurrent {
+			t.Errorf("%s: current_cow_size = %q, want %q", step.name, got, step.wantCurrent)
+		}
+		if got := infoField(info, "rdb_last_cow_size"); got != step.wantLast {
+			t.Errorf("%s: rdb_last_cow_size = %q, want %q", step.name, got, step.wantLast)
+		}
+	}
+}
+
+func TestSaveFailures(t *testing.T) {
+	d := &fakeDataset{keys: map[string]string{"k": "v"}}
+	saver, path := newTestSaver(t, d)
+
+	// A directory in place of the dump makes renaming the file fail
+	if err := os.Mkdir(path, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	// A failed SAVE doesn't count as a failed background save
+	if err := saver.Save(); err == nil {
+		t.Fatal("Save() succeeded")
+	}
+	if saver.LastBgsaveFailed() {
+		t.Error("LastBgsaveFailed() after a failed SAVE")
+	}
+
+	if _, err := saver.BackgroundSave(false); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, func() bool { return saver.LastBgsaveFailed() })
+	if got := infoField(saver.Info(), "rdb_last_bgsave_status"); got != "err" {
+		t.Errorf("rdb_last_bgsave_status = %q, want err", got)
+	}
+
+	// A successful SAVE clears the error
+	if err := os.Remove(path); err != nil {
+		t.Fatal(err)
+	}
+	if err := saver.Save(); err != nil {
+		t.Fatal(err)
+	}
+	if saver.LastBgsaveFailed() {
+		t.Error("LastBgsaveFailed() after a successful SAVE")
+	}
+}
+
+func TestCheckSavePoints(t *testing.T) {
+	tests := []struct {
+		name   string
+		points []SavePoint
+		dirty  int64
+		want   bool
+	}{
+		{"no save points", nil, 10, false},
+		{"not enough changes", []SavePoint{{Interval: 0, Changes: 5}}, 4, false},
+		{"enough changes", []SavePoint{{Interval: 0, Changes: 5}}, 5, true},
+		{"too soon", []SavePoint{{Interval: time.Hour, Changes: 1}}, 100, false},
+		{"any point", []SavePoint{{Interval: time.Hour, Changes: 1}, {Interval: 0, Changes: 100}}, 100, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			d := &fakeDataset{keys: map[string]string{}}
+			saver, path := newTestSaver(t, d)
+			d.dirty.Add(tt.dirty)
+
+			saver.CheckSavePoints(tt.points)
+			if err := saver.SaveOnShutdown(); err != nil {
+				t.Fatal(err)
+			}
+			os.Remove(path)
+
+			// SaveOnShutdown waited for the background save, if any
+			started := infoField(saver.Info(), "rdb_last_bgsave_time_sec") != "-1"
+			if started != tt.want {
+				t.Errorf("background save started = %v, want %v", started, tt.want)
+			}
+		})
+	}
+}
+
+// infoField returns the value of field in an INFO section
+func infoField(info, field string) string {
+	for _, line := range strings.Split(info, "\n") {
+		if value, ok := strings.CutPrefix(line, field+":"); ok {
+			return value
+		}
+	}
+	return ""
+}
+
+// waitFor polls cond until it holds, failing the test after a second
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,155 @@
+package rdb
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"time"
+)
+
+// Version is the RDB format version written, that of Redis 7.2 and later
+const Version = 11
+
+// Opcodes and value types of the RDB format
+const (
+	opAux          = 0xFA
+	opResizeDB     = 0xFB
+	opExpireTimeMS = 0xFC
+	opSelectDB     = 0xFE
+	opEOF          = 0xFF
+
+	typeString = 0
+)
+
+// Special string encodings, flagged by the top two bits of a length
+const (
+	encInt8  = 0xC0
+	encInt16 = 0xC1
+	encInt32 = 0xC2
+	encLZF   = 0xC3
+)
+
+// minCompressLen is the length from which strings are LZF compressed
+const minCompressLen = 21
+
+// Writer encodes an RDB file. Errors are sticky: once a write fails, later
+// calls do nothing and Close returns the error.
+type Writer struct {
+	w   *bufio.Writer
+	crc uint64
+	err error
+}
+
+// NewWriter creates a writer encoding to w, starting with the file header
+func NewWriter(w io.Writer) *Writer {
+	rw := &Writer{w: bufio.NewWriter(w)}
+	rw.write(fmt.Appendf(nil, "REDIS%04d", Version))
+	return rw
+}
+
+// Aux writes an auxiliary field, such as the version of the writer
+func (w *Writer) Aux(key, value string) {
+	w.write([]byte{opAux})
+	w.writeString([]byte(key))
+	w.writeString([]byte(value))
+}
+
+// SelectDB starts the keys of database db, which holds size keys, expires
+// of them with an expiration
+func (w *Writer) SelectDB(db, size, expires int) {
+	w.write([]byte{opSelectDB})
+	w.writeLength(uint64(db))
+	w.write([]byte{opResizeDB})
+	w.writeLength(uint64(size))
+	w.writeLength(uint64(expires))
+}
+
+// String writes a string key, with an expiration unless expiry is nil
+func (w *Writer) String(key string, value []byte, expiry *time.Time) {
+	if expiry != nil {
+		w.write([]byte{opExpireTimeMS})
+		w.write(binary.LittleEndian.AppendUint64(nil, uint64(expiry.UnixMilli())))
+	}
+	w.write([]byte{typeString})
+	w.writeString([]byte(key))
+	w.writeString(value)
+}
+
+// Close ends the file with its checksum and flushes it
+func (w *Writer) Close() error {
+	w.write([]byte{opEOF})
+	if w.err != nil {
+		return w.err
+	}
+
+	// The checksum covers everything before it, so it isn't added to itself
+	_, w.err = w.w.Write(binary.LittleEndian.AppendUint64(nil, w.crc))
+	if w.err != nil {
+		return w.err
+	}
+	return w.w.Flush()
+}
+
+// writeLength writes a length in the smallest of its 6, 14, 32 or 64 bit
+// encodings
+func (w *Writer) writeLength(n uint64) {
+	switch {
+	case n < 1<<6:
+		w.write([]byte{byte(n)})
+	case n < 1<<14:
+		w.write([]byte{byte(0x40 | n>>8), byte(n)})
+	case n <= math.MaxUint32:
+		w.write(binary.BigEndian.AppendUint32([]byte{0x80}, uint32(n)))
+	default:
+		w.write(binary.BigEndian.AppendUint64([]byte{0x81}, n))
+	}
+}
+
+// writeString writes a string as an integer if it is the canonical form of
+// one that fits 32 bits, compressed if that saves space, or as is
+func (w *Writer) writeString(s []byte) {
+	if len(s) <= 11 {
+		if n, err := strconv.ParseInt(string(s), 10, 32); err == nil && strconv.FormatInt(n, 10) == string(s) {
+			w.writeInt(n)
+			return
+		}
+	}
+
+	if len(s) >= minCompressLen {
+		if compressed := lzfCompress(s); compressed != nil {
+			w.write([]byte{encLZF})
+			w.writeLength(uint64(len(compressed)))
+			w.writeLength(uint64(len(s)))
+			w.write(compressed)
+			return
+		}
+	}
+
+	w.writeLength(uint64(len(s)))
+	w.write(s)
+}
+
+// writeInt writes an integer-encoded string
+func (w *Writer) writeInt(n int64) {
+	switch {
+	case n >= math.MinInt8 && n <= math.MaxInt8:
+		w.write([]byte{encInt8, byte(n)})
+	case n >= math.MinInt16 && n <= math.MaxInt16:
+		w.write(binary.LittleEndian.AppendUint16([]byte{encInt16}, uint16(n)))
+	default:
+		w.write(binary.LittleEndian.AppendUint32([]byte{encInt32}, uint32(n)))
+	}
+}
+
+// write writes p and adds it to the checksum
+func (w *Writer) write(p []byte) {
+	if w.err != nil {
+		return
+	}
+
+	w.crc = updateCRC(w.crc, p)
+	_, w.err = w.w.Write(p)
+}
@@ -0,0 +1,228 @@
+package rdb
+
+import (
+	"bytes"
+	"encoding/binary"
+	"flag"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hdt3213/rdb/lzf"
+	"github.com/hdt3213/rdb/model"
+	"github.com/hdt3213/rdb/parser"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+// encodeString returns how the writer encodes s
+func encodeString(s []byte) []byte {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.w.Flush()
+	buf.Reset()
+	w.writeString(s)
+	w.w.Flush()
+	return buf.Bytes()
+}
+
+func TestWriteString(t *testing.T) {
+	tests := []struct {
+		name string
+		s    string
+		want []byte
+	}{
+		{"empty", "", []byte{0x00}},
+		{"short", "a", []byte{0x01, 'a'}},
+		{"zero", "0", []byte{encInt8, 0x00}},
+		{"int8", "-1", []byte{encInt8, 0xff}},
+		{"int8 max", "127", []byte{encInt8, 0x7f}},
+		{"int16", "128", []byte{encInt16, 0x80, 0x00}},
+		{"int16 min", "-32768", []byte{encInt16, 0x00, 0x80}},
+		{"int32", "32768", []byte{encInt32, 0x00, 0x80, 0x00, 0x00}},
+		{"int32 max", "2147483647", []byte{encInt32, 0xff, 0xff, 0xff, 0x7f}},
+		{"int32 min", "-2147483648", []byte{encInt32, 0x00, 0x00, 0x00, 0x80}},
+		{"beyond int32", "2147483648", append([]byte{10}, "2147483648"...)},
+		{"leading zero", "007", append([]byte{3}, "007"...)},
+		{"plus sign", "+1", append([]byte{2}, "+1"...)},
+		{"negative zero", "-0", append([]byte{2}, "-0"...)},
+		{"space", " 1", append([]byte{2}, " 1"...)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := encodeString([]byte(tt.s)); !bytes.Equal(got, tt.want) {
+				t.Errorf("encoded %q as % x, want % x", tt.s, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteStringLength(t *testing.T) {
+	tests := []struct {
+		n      int
+		header []byte
+	}{
+		{63, []byte{0x3f}},
+		{64, []byte{0x40, 0x40}},
+		{16383, []byte{0x7f, 0xff}},
+		{16384, []byte{0x80, 0x00, 0x00, 0x40, 0x00}},
+	}
+
+	for _, tt := range tests {
+		s := randomBytes(tt.n)
+		got := encodeString(s)
+		want := append(tt.header, s...)
+		if !bytes.Equal(got, want) {
+			t.Errorf("length %d encoded with header % x, want % x", tt.n, got[:len(tt.header)], tt.header)
+		}
+	}
+}
+
+func TestWriteStringCompressed(t *testing.T) {
+	s := bytes.Repeat([]byte("ab"), 30)
+	got := encodeString(s)
+	if got[0] != encLZF {
+		t.Fatalf("encoded with % x, want LZF", got[:1])
+	}
+
+	// The compressed and original lengths follow, both below 64
+	compressedLen, originalLen := int(got[1]), int(got[2])
+	if originalLen != len(s) || compressedLen != len(got)-3 {
+		t.Fatalf("lengths %d and %d, want %d and %d", compressedLen, originalLen, len(got)-3, len(s))
+	}
+	data, err := lzf.Decompress(got[3:], compressedLen, originalLen)
+	if err != nil || !bytes.Equal(data, s) {
+		t.Errorf("Decompress = %q, %v", data, err)
+	}
+
+	// Strings too short to be worth it are never compressed
+	short := bytes.Repeat([]byte("a"), minCompressLen-1)
+	if got := encodeString(short); got[0] != byte(len(short)) {
+		t.Errorf("short string encoded with % x, want raw", got[:1])
+	}
+}
+
+// goldenExpiry is the expiration of the key with one in the golden file
+var goldenExpiry = time.UnixMilli(1893456000000)
+
+// goldenKeys are the keys of the golden file, in order, covering every
+// string encoding
+var goldenKeys = []struct {
+	key    string
+	value  string
+	expiry *time.Time
+}{
+	{"empty", "", nil},
+	{"raw", "hello", nil},
+	{"int8", "-12", nil},
+	{"int16", "1234", nil},
+	{"int32", "123456789", nil},
+	{"int64", "12345678901234", nil},
+	{"compressed", strings.Repeat("compressible ", 30), nil},
+	{"binary", "\x00\xff\r\n", nil},
+	{"expiring", "soon", &goldenExpiry},
+}
+
+// writeGolden writes the golden file's contents
+func writeGolden(t *testing.T) []byte {
+	t.Helper()
+
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+	w.Aux("redis-ver", "7.4.0")
+	w.Aux("redis-bits", "64")
+	w.Aux("ctime", "1700000000")
+	w.Aux("aof-base", "0")
+	w.SelectDB(0, len(goldenKeys), 1)
+	for _, k := range goldenKeys {
+		w.String(k.key, []byte(k.value), k.expiry)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestWriterGolden(t *testing.T) {
+	const golden = "testdata/strings.rdb"
+	got := writeGolden(t)
+
+	if *update {
+		if err := os.WriteFile(golden, got, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want, err := os.ReadFile(golden)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("output differs from %s; run with -update if the change is intended", golden)
+	}
+
+	if !bytes.HasPrefix(got, []byte("REDIS0011")) {
+		t.Errorf("header %q, want REDIS0011", got[:9])
+	}
+	body, sum := got[:len(got)-8], binary.LittleEndian.Uint64(got[len(got)-8:])
+	if body[len(body)-1] != opEOF {
+		t.Errorf("last opcode %#x, want EOF", body[len(body)-1])
+	}
+	if updateCRC(0, body) != sum {
+		t.Error("checksum doesn't match the contents")
+	}
+}
+
+// The output must load in other RDB readers, which also verify the checksum
+func TestWriterParses(t *testing.T) {
+	type object struct {
+		value  string
+		expiry *time.Time
+	}
+	objects := make(map[string]object)
+	err := parser.NewDecoder(bytes.NewReader(writeGolden(t))).Parse(func(o model.RedisObject) bool {
+		s, ok := o.(*model.StringObject)
+		if !ok {
+			t.Errorf("key %q has type %s, want string", o.GetKey(), o.GetType())
+			return true
+		}
+		objects[o.GetKey()] = object{string(s.Value), o.GetExpiration()}
+		return true
+	})
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	if len(objects) != len(goldenKeys) {
+		t.Errorf("parsed %d keys, want %d", len(objects), len(goldenKeys))
+	}
+	for _, k := range goldenKeys {
+		o, ok := objects[k.key]
+		if !ok {
+			t.Errorf("key %q missing", k.key)
+			continue
+		}
+		if o.value != k.value {
+			t.Errorf("key %q = %q, want %q", k.key, o.value, k.value)
+		}
+		if (o.expiry == nil) != (k.expiry == nil) || (o.expiry != nil && !o.expiry.Equal(*k.expiry)) {
+			t.Errorf("key %q expires at %v, want %v", k.key, o.expiry, k.expiry)
+		}
+	}
+}
+
+func TestWriterStickyError(t *testing.T) {
+	w := NewWriter(failingWriter{})
+	w.String("k", []byte("v"), nil)
+	if err := w.Close(); err == nil {
+		t.Error("Close() = nil after a failed write")
+	}
+}
+
+// failingWriter fails every write
+type failingWriter struct{}
+
+func (failingWriter) Write(p []byte) (int, error) {
+	return 0, os.ErrClosed
+}
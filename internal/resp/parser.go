@@ -2,82 +2,394 @@ package resp
 
 import (
 	"bufio"
+	"bytes"
 	"fmt"
 	"io"
 	"strconv"
-	"strings"
+	"sync/atomic"
 )
 
+const (
+	// maxInlineSize bounds inline commands and protocol header lines, which
+	// are buffered whole before they can be parsed
+	maxInlineSize = 64 * 1024
+
+	// bulkChunkSize is the largest bulk string allocated up front. Bigger
+	// bulks grow as their data actually arrives, so a client can't make the
+	// server allocate memory just by announcing a huge length.
+	bulkChunkSize = 1024 * 1024
+)
+
+// Limits bounds the size of a single client command
+type Limits struct {
+	// MaxBulkLen is the largest accepted bulk string (proto-max-bulk-len)
+	MaxBulkLen int64
+	// MaxMultibulkLen is the largest accepted argument count
+	MaxMultibulkLen int64
+	// MaxQueryBuffer is the largest accepted total command size
+	// (client-query-buffer-limit)
+	MaxQueryBuffer int64
+}
+
+// DefaultLimits matches the Redis defaults
+var DefaultLimits = Limits{
+	MaxBulkLen:      512 * 1024 * 1024,
+	MaxMultibulkLen: 1024 * 1024,
+	MaxQueryBuffer:  1024 * 1024 * 1024,
+}
+
 // Parser defines the interface for parsing RESP protocol
 type Parser interface {
-	// ParseCommand parses a RESP command from a reader
-	ParseCommand(reader *bufio.Reader) ([]string, error)
+	// NewReader returns a CommandReader parsing commands sent over r,
+	// typically a client connection
+	NewReader(r io.Reader) CommandReader
+
+	// SetLimits changes the limits applied to subsequently parsed commands
+	SetLimits(limits Limits)
+}
+
+// CommandReader reads commands from a single connection
+type CommandReader interface {
+	// ReadCommand reads the next command. Arguments are binary-safe byte
+	// slices that may point into the reader's buffer, so they are only
+	// valid until the next call to ReadCommand.
+	ReadCommand() ([][]byte, error)
+
+	// HasCommand reports whether a complete command is already buffered,
+	// i.e. whether ReadCommand can return without reading from the
+	// connection. The server uses it to batch replies to pipelines.
+	HasCommand() bool
 }
 
-// DefaultParser is the default implementation of the RESP protocol parser
-type DefaultParser struct{}
+// ProtocolError reports malformed client input. Like Redis, the server
+// replies with the error and then closes the connection, since it can no
+// longer tell where the next command starts.
+type ProtocolError struct {
+	Message string
+}
 
-// NewParser creates a new RESP parser
+func (e *ProtocolError) Error() string {
+	return "Protocol error: " + e.Message
+}
+
+// DefaultParser is a simple RESP parser on top of bufio.Reader that
+// allocates a fresh slice for every argument
+type DefaultParser struct {
+	limits atomic.Pointer[Limits]
+}
+
+// Ensure DefaultParser implements Parser
+var _ Parser = (*DefaultParser)(nil)
+
+// NewParser creates a new RESP parser enforcing DefaultLimits
 func NewParser() *DefaultParser {
-	return &DefaultParser{}
+	p := &DefaultParser{}
+	p.SetLimits(DefaultLimits)
+	return p
+}
+
+// SetLimits changes the limits applied to subsequently parsed commands. It
+// is safe to call while connections are being served.
+func (p *DefaultParser) SetLimits(limits Limits) {
+	p.limits.Store(&limits)
+}
+
+// NewReader returns a CommandReader parsing commands from r with ParseCommand
+func (p *DefaultParser) NewReader(r io.Reader) CommandReader {
+	return &bufferedReader{parser: p, reader: bufio.NewReader(r)}
+}
+
+// bufferedReader adapts DefaultParser to the CommandReader interface
+type bufferedReader struct {
+	parser *DefaultParser
+	reader *bufio.Reader
 }
 
-// ParseCommand parses a RESP command from a reader
-func (p *DefaultParser) ParseCommand(reader *bufio.Reader) ([]string, error) {
-	line, err := reader.ReadString('\n')
+func (b *bufferedReader) ReadCommand() ([][]byte, error) {
+	return b.parser.ParseCommand(b.reader)
+}
+
+func (b *bufferedReader) HasCommand() bool {
+	return b.parser.HasCommand(b.reader)
+}
+
+// ParseCommand parses a RESP command from a reader. Besides RESP arrays of
+// bulk strings it accepts inline commands (a single line of space-separated
+// arguments, as typed into telnet), which yield an empty command for blank
+// lines.
+func (p *DefaultParser) ParseCommand(reader *bufio.Reader) ([][]byte, error) {
+	limits := p.limits.Load()
+
+	line, err := readLine(reader, "too big inline request")
 	if err != nil {
 		return nil, err
 	}
 
-	line = strings.TrimSpace(line)
-	if !strings.HasPrefix(line, "*") {
-		// TODO: Handle raw text commands later
-		return nil, fmt.Errorf("Invalid RESP format: expected array prefix '*'")
+	if len(line) == 0 || line[0] != '*' {
+		return SplitInline(line)
 	}
 
-	count, err := strconv.Atoi(line[1:])
-	if err != nil {
-		return nil, fmt.Errorf("Invalid RESP array length: %w", err)
+	count, err := strconv.ParseInt(string(line[1:]), 10, 64)
+	if err != nil || count > limits.MaxMultibulkLen {
+		return nil, &ProtocolError{Message: "invalid multibulk length"}
+	}
+	if count <= 0 {
+		return [][]byte{}, nil
 	}
 
-	commands := make([]string, count)
-	for i := range count {
+	// Grow the argument list as arguments arrive rather than trusting count
+	commands := make([][]byte, 0, min(count, 1024))
+	queryLen := int64(len(line))
+	for range count {
 		// Read bulk string header (e.g: `$3`)
-		line, err = reader.ReadString('\n')
+		line, err = readLine(reader, "too big bulk count string")
 		if err != nil {
 			return nil, err
 		}
-		line = strings.TrimSpace(line)
 
-		if !strings.HasPrefix(line, "$") {
-			return nil, fmt.Errorf("Invalid RESP format: expected bulk string prefix '$'")
+		if len(line) == 0 || line[0] != '$' {
+			got := "\\r"
+			if len(line) > 0 {
+				got = string(line[0])
+			}
+			return nil, &ProtocolError{Message: fmt.Sprintf("expected '$', got '%s'", got)}
 		}
 
 		// Parse bulk string length
-		length, err := strconv.Atoi(line[1:])
+		length, err := strconv.ParseInt(string(line[1:]), 10, 64)
+		if err != nil || length < 0 || length > limits.MaxBulkLen {
+			return nil, &ProtocolError{Message: "invalid bulk length"}
+		}
+
+		// Check the limit before adding, which lengths close to the largest
+		// int64 would overflow
+		if length > limits.MaxQueryBuffer-queryLen-int64(len(line))-4 {
+			return nil, &ProtocolError{Message: "client query buffer limit exceeded"}
+		}
+		queryLen += int64(len(line)) + length + 4
+
+		data, err := readBulk(reader, int(length))
 		if err != nil {
-			return nil, fmt.Errorf("invalid RESP bulk string length: %w", err)
+			return nil, err
 		}
+		commands = append(commands, data)
+	}
+
+	return commands, nil
+}
 
-		// Read actual command content
-		if length > 0 {
-			// Read the exact number of bytes, plus the trailing \r\n
-			data := make([]byte, length+2)
-			_, err := io.ReadFull(reader, data)
-			if err != nil {
-				return nil, err
+// readBulk reads a bulk string payload of the given length and its trailing
+// \r\n. Large payloads are read in growing chunks, so memory is only
+// committed for data the client has actually sent.
+func readBulk(reader *bufio.Reader, length int) ([]byte, error) {
+	total := length + 2
+	data := make([]byte, 0, min(total, bulkChunkSize))
+	for len(data) < total {
+		n := min(total-len(data), max(cap(data)-len(data), len(data)))
+		if cap(data)-len(data) < n {
+			grown := make([]byte, len(data), len(data)+n)
+			copy(grown, data)
+			data = grown
+		}
+
+		_, err := io.ReadFull(reader, data[len(data):len(data)+n])
+		if err != nil {
+			return nil, err
+		}
+		data = data[:len(data)+n]
+	}
+
+	return data[:length:length], nil
+}
+
+// HasCommand reports whether the reader buffers a complete command. Malformed
+// input, including lengths beyond the limits, counts as complete, since
+// parsing it fails without blocking.
+func (p *DefaultParser) HasCommand(reader *bufio.Reader) bool {
+	limits := p.limits.Load()
+	buf, _ := reader.Peek(reader.Buffered())
+
+	nl := bytes.IndexByte(buf, '\n')
+	if nl < 0 {
+		return false
+	}
+	if buf[0] != '*' {
+		// Inline commands end at the first newline
+		return true
+	}
+
+	count, err := strconv.ParseInt(string(bytes.TrimSuffix(buf[1:nl], []byte("\r"))), 10, 64)
+	if err != nil || count > limits.MaxMultibulkLen {
+		return true
+	}
+
+	pos := nl + 1
+	for range count {
+		nl = bytes.IndexByte(buf[pos:], '\n')
+		if nl < 0 {
+			return false
+		}
+
+		header := bytes.TrimSuffix(buf[pos:pos+nl], []byte("\r"))
+		if len(header) == 0 || header[0] != '$' {
+			return true
+		}
+		length, err := strconv.ParseInt(string(header[1:]), 10, 64)
+		if err != nil || length < 0 || length > limits.MaxBulkLen {
+			return true
+		}
+
+		// Compare before adding, as a huge length would overflow pos
+		pos += nl + 1
+		if length > int64(len(buf)-pos-2) {
+			return false
+		}
+		pos += int(length) + 2
+	}
+
+	return true
+}
+
+// readLine reads a line terminated by \n and strips the line terminator,
+// including a preceding \r. Lines longer than maxInlineSize are rejected
+// with a protocol error carrying tooBig.
+func readLine(reader *bufio.Reader, tooBig string) ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := reader.ReadSlice('\n')
+		line = append(line, chunk...)
+		if len(line) > maxInlineSize {
+			return nil, &ProtocolError{Message: tooBig}
+		}
+		if err == nil {
+			break
+		}
+		if err != bufio.ErrBufferFull {
+			return nil, err
+		}
+	}
+
+	line = line[:len(line)-1]
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		line = line[:len(line)-1]
+	}
+
+	return line, nil
+}
+
+// SplitInline splits an inline command line into arguments using the same
+// rules as redis-cli: arguments are separated by whitespace, double-quoted
+// arguments support \n, \r, \t, \b, \a, \xHH and backslash escapes,
+// single-quoted arguments only support \', and a closing quote must be
+// followed by whitespace or the end of the line.
+func SplitInline(line []byte) ([][]byte, error) {
+	args := make([][]byte, 0)
+	p := 0
+	for {
+		for p < len(line) && isSpace(line[p]) {
+			p++
+		}
+		if p == len(line) {
+			return args, nil
+		}
+
+		var (
+			current       = make([]byte, 0)
+			inDoubleQuote bool
+			inSingleQuote bool
+			done          bool
+		)
+		for !done {
+			switch {
+			case inDoubleQuote:
+				switch {
+				case p == len(line):
+					return nil, &ProtocolError{Message: "unbalanced quotes in request"}
+				case line[p] == '\\' && p+3 < len(line) && line[p+1] == 'x' && isHexDigit(line[p+2]) && isHexDigit(line[p+3]):
+					current = append(current, hexValue(line[p+2])<<4|hexValue(line[p+3]))
+					p += 3
+				case line[p] == '\\' && p+1 < len(line):
+					p++
+					current = append(current, unescape(line[p]))
+				case line[p] == '"':
+					// The closing quote must be followed by a space or nothing at all
+					if p+1 < len(line) && !isSpace(line[p+1]) {
+						return nil, &ProtocolError{Message: "unbalanced quotes in request"}
+					}
+					done = true
+				default:
+					current = append(current, line[p])
+				}
+			case inSingleQuote:
+				switch {
+				case p == len(line):
+					return nil, &ProtocolError{Message: "unbalanced quotes in request"}
+				case line[p] == '\\' && p+1 < len(line) && line[p+1] == '\'':
+					p++
+					current = append(current, '\'')
+				case line[p] == '\'':
+					if p+1 < len(line) && !isSpace(line[p+1]) {
+						return nil, &ProtocolError{Message: "unbalanced quotes in request"}
+					}
+					done = true
+				default:
+					current = append(current, line[p])
+				}
+			default:
+				switch {
+				case p == len(line) || isSpace(line[p]) || line[p] == 0:
+					done = true
+				case line[p] == '"':
+					inDoubleQuote = true
+				case line[p] == '\'':
+					inSingleQuote = true
+				default:
+					current = append(current, line[p])
+				}
 			}
-			commands[i] = string(data[:length])
 
-		} else {
-			// Empty string case, just read \r\n
-			_, err = reader.ReadString('\n')
-			if err != nil {
-				return nil, err
+			if p < len(line) {
+				p++
 			}
-			commands[i] = ""
 		}
+
+		args = append(args, current)
 	}
+}
 
-	return commands, nil
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\n' || c == '\v' || c == '\f' || c == '\r'
+}
+
+func isHexDigit(c byte) bool {
+	return (c >= '0' && c <= '9') || (c >= 'a' && c <= 'f') || (c >= 'A' && c <= 'F')
+}
+
+func hexValue(c byte) byte {
+	switch {
+	case c >= '0' && c <= '9':
+		return c - '0'
+	case c >= 'a' && c <= 'f':
+		return c - 'a' + 10
+	default:
+		return c - 'A' + 10
+	}
+}
+
+// unescape returns the byte a backslash escape inside double quotes stands for
+func unescape(c byte) byte {
+	switch c {
+	case 'n':
+		return '\n'
+	case 'r':
+		return '\r'
+	case 't':
+		return '\t'
+	case 'b':
+		return '\b'
+	case 'a':
+		return '\a'
+	default:
+		return c
+	}
 }
@@ -0,0 +1,301 @@
+package resp
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+// parsers are the parser implementations every parsing test runs against
+var parsers = []struct {
+	name string
+	new  func() Parser
+}{
+	{"DefaultParser", func() Parser { return NewParser() }},
+	{"StreamParser", func() Parser { return NewStreamParser() }},
+}
+
+func TestReadCommand(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []string
+	}{
+		{"command", "*2\r\n$3\r\nGET\r\n$1\r\nk\r\n", []string{"GET", "k"}},
+		{"empty argument", "*2\r\n$4\r\nECHO\r\n$0\r\n\r\n", []string{"ECHO", ""}},
+		{"binary argument", "*2\r\n$4\r\nECHO\r\n$6\r\n\x00\xff\r\n$\n\r\n", []string{"ECHO", "\x00\xff\r\n$\n"}},
+		{"empty array", "*0\r\n", []string{}},
+		{"bare newlines", "*1\n$4\nPING\r\n", []string{"PING"}},
+		{"inline", "SET k \"a b\"\r\n", []string{"SET", "k", "a b"}},
+		{"inline without CR", "PING\n", []string{"PING"}},
+		{"blank line", "\r\n", []string{}},
+		{"whitespace only", "  \t \r\n", []string{}},
+	}
+
+	for _, p := range parsers {
+		for _, tt := range tests {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				args, err := p.new().NewReader(bytes.NewReader([]byte(tt.input))).ReadCommand()
+				if err != nil {
+					t.Fatalf("ReadCommand() = %v", err)
+				}
+				if len(args) != len(tt.want) {
+					t.Fatalf("ReadCommand() = %q, want %q", args, tt.want)
+				}
+				for i := range args {
+					if string(args[i]) != tt.want[i] {
+						t.Errorf("argument %d = %q, want %q", i, args[i], tt.want[i])
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestSplitInline(t *testing.T) {
+	tests := []struct {
+		name    string
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{"empty", "", []string{}, false},
+		{"spaces", " \t ", []string{}, false},
+		{"words", "SET  key\tvalue ", []string{"SET", "key", "value"}, false},
+		{"double quotes", `"hello world" x`, []string{"hello world", "x"}, false},
+		{"empty quotes", `"" ''`, []string{"", ""}, false},
+		{"escapes", `"\n\r\t\b\a\\\"\q"`, []string{"\n\r\t\b\a\\\"q"}, false},
+		{"hex escape", `"\x41\x00\xfF"`, []string{"A\x00\xff"}, false},
+		{"incomplete hex escape", `"\x4"`, []string{"x4"}, false},
+		{"single quotes", `'a "b" \n'`, []string{`a "b" \n`}, false},
+		{"escaped single quote", `'it\'s'`, []string{"it's"}, false},
+		{"quote inside word", `a"b c"`, []string{"ab c"}, false},
+		{"unterminated double quote", `"abc`, nil, true},
+		{"unterminated single quote", `'abc`, nil, true},
+		{"text after closing quote", `"a"b`, nil, true},
+		{"text after closing single quote", `'a'b`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, err := SplitInline([]byte(tt.line))
+			if tt.wantErr {
+				var protoErr *ProtocolError
+				if !errors.As(err, &protoErr) {
+					t.Fatalf("SplitInline() = %q, %v, want a protocol error", args, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("SplitInline() = %v", err)
+			}
+			if len(args) != len(tt.want) {
+				t.Fatalf("SplitInline() = %q, want %q", args, tt.want)
+			}
+			for i := range args {
+				if string(args[i]) != tt.want[i] {
+					t.Errorf("argument %d = %q, want %q", i, args[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestHasCommand(t *testing.T) {
+	tests := []struct {
+		name string
+		rest string
+		want bool
+	}{
+		{"nothing", "", false},
+		{"complete", "*1\r\n$4\r\nPING\r\n", true},
+		{"partial header", "*2\r\n$3\r\nGET\r\n$1", false},
+		{"partial bulk", "*1\r\n$4\r\nPIN", false},
+		{"missing terminator", "*2\r\n$3\r\nGET\r\n$1\r\nk", false},
+		{"bulk not received", "*2\r\n$100\r\nab\r\n", false},
+		{"bulk overflowing the position", "*2\r\n$9223372036854775800\r\nab\r\n", true},
+		{"bulk beyond int64", "*2\r\n$99999999999999999999\r\nab\r\n", true},
+		{"bulk over the limit", "*1\r\n$600000000\r\n", true},
+		{"count over the limit", "*9999999999\r\n", true},
+		{"bad bulk header", "*1\r\nx\r\n", true},
+		{"negative bulk", "*1\r\n$-5\r\n", true},
+		{"inline", "GET k\r\n", true},
+		{"partial inline", "GET k", false},
+	}
+
+	for _, p := range parsers {
+		for _, tt := range tests {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				// Reading a first command buffers the rest of the input
+				reader := p.new().NewReader(bytes.NewReader([]byte("PING\r\n" + tt.rest)))
+				if _, err := reader.ReadCommand(); err != nil {
+					t.Fatal(err)
+				}
+				if got := reader.HasCommand(); got != tt.want {
+					t.Errorf("HasCommand() = %v, want %v", got, tt.want)
+				}
+			})
+		}
+	}
+}
+
+func TestCommandLimits(t *testing.T) {
+	small := Limits{MaxBulkLen: 10, MaxMultibulkLen: 3, MaxQueryBuffer: 30}
+	unlimited := Limits{MaxBulkLen: math.MaxInt64, MaxMultibulkLen: math.MaxInt64, MaxQueryBuffer: math.MaxInt64}
+	tests := []struct {
+		name    string
+		limits  Limits
+		input   string
+		wantErr string
+	}{
+		{"within limits", small, "*2\r\n$3\r\nGET\r\n$10\r\n0123456789\r\n", ""},
+		{"too many arguments", small, "*4\r\n", "invalid multibulk length"},
+		{"huge count", DefaultLimits, "*2147483647\r\n", "invalid multibulk length"},
+		{"bulk too long", small, "*1\r\n$11\r\n", "invalid bulk length"},
+		{"negative bulk", small, "*1\r\n$-1\r\n", "invalid bulk length"},
+		{"query too long", small, "*3\r\n$10\r\n0123456789\r\n$10\r\n", "client query buffer limit exceeded"},
+		{"length overflowing the query size", unlimited, "*1\r\n$9223372036854775800\r\nab\r\n", "client query buffer limit exceeded"},
+		{"largest length", unlimited, "*1\r\n$9223372036854775807\r\n", "client query buffer limit exceeded"},
+		{"longest inline", unlimited, strings.Repeat("a", maxInlineSize-2) + "\r\n", ""},
+		{"inline too long", unlimited, strings.Repeat("a", maxInlineSize+1) + "\r\n", "too big inline request"},
+		{"bulk header too long", unlimited, "*1\r\n$" + strings.Repeat("1", maxInlineSize+1) + "\r\n", "too big bulk count string"},
+		{"unbalanced quotes", unlimited, "SET k \"v\r\n", "unbalanced quotes in request"},
+		{"missing bulk header", unlimited, "*1\r\nPING\r\n", "expected '$', got 'P'"},
+	}
+
+	for _, p := range parsers {
+		for _, tt := range tests {
+			t.Run(p.name+"/"+tt.name, func(t *testing.T) {
+				parser := p.new()
+				parser.SetLimits(tt.limits)
+				_, err := parser.NewReader(bytes.NewReader([]byte(tt.input))).ReadCommand()
+
+				if tt.wantErr == "" {
+					if err != nil {
+						t.Fatalf("ReadCommand() = %v", err)
+					}
+					return
+				}
+				var protoErr *ProtocolError
+				if !errors.As(err, &protoErr) || protoErr.Message != tt.wantErr {
+					t.Errorf("ReadCommand() = %v, want protocol error %q", err, tt.wantErr)
+				}
+			})
+		}
+	}
+}
+
+// Commands split across reads at every possible point parse the same as
+// when they arrive at once, including bulks bigger than the read buffer
+func TestPartialReads(t *testing.T) {
+	big := strings.Repeat("x", 3*initialBufferSize+7)
+	input := "*3\r\n$3\r\nSET\r\n$1\r\nk\r\n$" + strconv.Itoa(len(big)) + "\r\n" + big + "\r\n" +
+		"PING inline\r\n" +
+		"*2\r\n$4\r\nECHO\r\n$0\r\n\r\n"
+	want := [][]string{{"SET", "k", big}, {"PING", "inline"}, {"ECHO", ""}}
+
+	readers := []struct {
+		name string
+		wrap func(io.Reader) io.Reader
+	}{
+		{"whole", func(r io.Reader) io.Reader { return r }},
+		{"one byte", iotest.OneByteReader},
+		{"half", iotest.HalfReader},
+	}
+
+	for _, p := range parsers {
+		for _, rd := range readers {
+			t.Run(p.name+"/"+rd.name, func(t *testing.T) {
+				reader := p.new().NewReader(rd.wrap(strings.NewReader(input)))
+				for i, w := range want {
+					args, err := reader.ReadCommand()
+					if err != nil {
+						t.Fatalf("command %d: %v", i, err)
+					}
+					if len(args) != len(w) {
+						t.Fatalf("command %d has %d arguments, want %d", i, len(args), len(w))
+					}
+					for j := range args {
+						if string(args[j]) != w[j] {
+							t.Errorf("command %d argument %d differs", i, j)
+						}
+					}
+				}
+				if _, err := reader.ReadCommand(); err != io.EOF {
+					t.Errorf("ReadCommand() at the end = %v, want EOF", err)
+				}
+			})
+		}
+	}
+}
+
+// Buffers grown for a big command are released once it has been handled
+func TestStreamReaderReleasesBuffer(t *testing.T) {
+	big := strings.Repeat("x", 4*maxIdleBufferSize)
+	input := "*1\r\n$" + strconv.Itoa(len(big)) + "\r\n" + big + "\r\nPING\r\n"
+	reader := NewStreamParser().NewReader(strings.NewReader(input)).(*StreamReader)
+
+	if _, err := reader.ReadCommand(); err != nil {
+		t.Fatal(err)
+	}
+	if len(reader.buf) <= maxIdleBufferSize {
+		t.Fatalf("buffer of %d bytes holds a %d byte command", len(reader.buf), len(big))
+	}
+	if _, err := reader.ReadCommand(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.ReadCommand(); err != io.EOF {
+		t.Fatalf("ReadCommand() at the end = %v, want EOF", err)
+	}
+	if len(reader.buf) > maxIdleBufferSize {
+		t.Errorf("buffer still %d bytes once drained", len(reader.buf))
+	}
+}
+
+// pipeline returns n commands, alternating SET and GET, as sent by a client
+// pipelining them
+func pipeline(n int) []byte {
+	var b bytes.Buffer
+	for i := range n {
+		if i%2 == 0 {
+			b.WriteString("*3\r\n$3\r\nSET\r\n$3\r\nkey\r\n$5\r\nvalue\r\n")
+		} else {
+			b.WriteString("*2\r\n$3\r\nGET\r\n$3\r\nkey\r\n")
+		}
+	}
+	return b.Bytes()
+}
+
+// benchmarkParser parses pipelines of 100 commands, the way the server reads
+// them: a command at a time, checking whether another one is buffered
+func benchmarkParser(b *testing.B, parser Parser) {
+	input := pipeline(100)
+	r := bytes.NewReader(input)
+	reader := parser.NewReader(r)
+
+	b.ReportAllocs()
+	b.SetBytes(int64(len(input)))
+	b.ResetTimer()
+	for range b.N {
+		r.Reset(input)
+		for range 100 {
+			if _, err := reader.ReadCommand(); err != nil {
+				b.Fatal(err)
+			}
+			reader.HasCommand()
+		}
+	}
+}
+
+func BenchmarkDefaultParser(b *testing.B) {
+	benchmarkParser(b, NewParser())
+}
+
+func BenchmarkStreamParser(b *testing.B) {
+	benchmarkParser(b, NewStreamParser())
+}
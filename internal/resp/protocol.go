@@ -1,11 +1,28 @@
 package resp
 
-import "strconv"
+import (
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Protocol is a RESP protocol version, negotiated per connection with HELLO
+type Protocol int
+
+const (
+	// RESP2 is the protocol every connection starts with
+	RESP2 Protocol = 2
+	// RESP3 adds maps, sets, doubles, booleans, nulls, push messages and more
+	RESP3 Protocol = 3
+)
 
 // RedisValue represents a RESP (Redis Serialization Protocol) value
 type RedisValue interface {
-	// Serialize returns the RESP representation of the value
-	Serialize() []byte
+	// Serialize returns the representation of the value in the given
+	// protocol version. Types that only exist in RESP3 fall back to their
+	// closest RESP2 equivalent.
+	Serialize(proto Protocol) []byte
 }
 
 // SimpleString represents RESP Simple String
@@ -13,7 +30,7 @@ type SimpleString struct {
 	Value string
 }
 
-func (s SimpleString) Serialize() []byte {
+func (s SimpleString) Serialize(proto Protocol) []byte {
 	return []byte("+" + s.Value + "\r\n")
 }
 
@@ -23,22 +40,34 @@ type Error struct {
 }
 
 // Serialize returns the RESP representation of an Error
-func (e Error) Serialize() []byte {
+func (e Error) Serialize(proto Protocol) []byte {
 	return []byte("-" + e.Value + "\r\n")
 }
 
-// BulkString represents a RESP Bulk String
+// BulkString represents a RESP Bulk String. Its payload is binary safe and
+// may be empty; a missing value is represented by Null instead.
 type BulkString struct {
-	Value string
+	Value []byte
+}
+
+// NewBulkString creates a Bulk String holding s
+func NewBulkString(s string) BulkString {
+	return BulkString{Value: []byte(s)}
 }
 
 // Serialize returns the RESP representation of a Bulk String
-func (b BulkString) Serialize() []byte {
-	if b.Value == "" {
-		return []byte("$-1\r\n")
-	}
+func (b BulkString) Serialize(proto Protocol) []byte {
+	return bulk('$', b.Value)
+}
+
+// Integer represents a RESP Integer
+type Integer struct {
+	Value int64
+}
 
-	return []byte("$" + strconv.Itoa(len(b.Value)) + "\r\n" + b.Value + "\r\n")
+// Serialize returns the RESP representation of an Integer
+func (i Integer) Serialize(proto Protocol) []byte {
+	return []byte(":" + strconv.FormatInt(i.Value, 10) + "\r\n")
 }
 
 // Array represents a RESP Array
@@ -47,23 +76,229 @@ type Array struct {
 }
 
 // Serialize returns the RESP representation of an Array
-func (a Array) Serialize() []byte {
-	result := []byte("*" + strconv.Itoa(len(a.Values)) + "\r\n")
-	for _, value := range a.Values {
-		result = append(result, value.Serialize()...)
+func (a Array) Serialize(proto Protocol) []byte {
+	return aggregate('*', a.Values, proto)
+}
+
+// Null represents the RESP3 Null, sent as a null bulk string in RESP2. It is
+// the reply for missing values, e.g. GET on a key that does not exist.
+type Null struct{}
+
+// Serialize returns the RESP representation of a Null
+func (n Null) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		return []byte("_\r\n")
+	}
+	return []byte("$-1\r\n")
+}
+
+// Double represents a RESP3 Double, sent as a bulk string in RESP2
+type Double struct {
+	Value float64
+}
+
+// Serialize returns the RESP representation of a Double
+func (d Double) Serialize(proto Protocol) []byte {
+	var s string
+	switch {
+	case math.IsInf(d.Value, 1):
+		s = "inf"
+	case math.IsInf(d.Value, -1):
+		s = "-inf"
+	case math.IsNaN(d.Value):
+		s = "nan"
+	default:
+		s = strconv.FormatFloat(d.Value, 'g', -1, 64)
+	}
+
+	if proto == RESP3 {
+		return []byte("," + s + "\r\n")
+	}
+	return bulk('$', []byte(s))
+}
+
+// Boolean represents a RESP3 Boolean, sent as the integer 1 or 0 in RESP2
+type Boolean struct {
+	Value bool
+}
+
+// Serialize returns the RESP representation of a Boolean
+func (b Boolean) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		if b.Value {
+			return []byte("#t\r\n")
+		}
+		return []byte("#f\r\n")
+	}
+
+	if b.Value {
+		return []byte(":1\r\n")
+	}
+	return []byte(":0\r\n")
+}
+
+// BigNumber represents a RESP3 Big Number, sent as a bulk string in RESP2
+type BigNumber struct {
+	Value *big.Int
+}
+
+// Serialize returns the RESP representation of a Big Number
+func (b BigNumber) Serialize(proto Protocol) []byte {
+	s := b.Value.String()
+	if proto == RESP3 {
+		return []byte("(" + s + "\r\n")
+	}
+	return bulk('$', []byte(s))
+}
+
+// BlobError represents a RESP3 Blob Error, a binary-safe error that may span
+// lines. RESP2 has no equivalent, so it is sent as a simple error with line
+// breaks replaced by spaces.
+type BlobError struct {
+	Value string
+}
+
+// Serialize returns the RESP representation of a Blob Error
+func (b BlobError) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		return bulk('!', []byte(b.Value))
+	}
+
+	flat := strings.NewReplacer("\r", " ", "\n", " ").Replace(b.Value)
+	return []byte("-" + flat + "\r\n")
+}
+
+// VerbatimString represents a RESP3 Verbatim String, a string with a
+// three-character format hint such as "txt" or "mkd". It is sent as a plain
+// bulk string in RESP2.
+type VerbatimString struct {
+	Format string
+	Value  []byte
+}
+
+// Serialize returns the RESP representation of a Verbatim String
+func (v VerbatimString) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		return bulk('=', append([]byte(v.Format+":"), v.Value...))
+	}
+	return bulk('$', v.Value)
+}
+
+// MapEntry is a key-value pair of a Map or Attribute
+type MapEntry struct {
+	Key   RedisValue
+	Value RedisValue
+}
+
+// Map represents a RESP3 Map, sent as a flat array of alternating keys and
+// values in RESP2. Entries keep their order on the wire.
+type Map struct {
+	Entries []MapEntry
+}
+
+// Serialize returns the RESP representation of a Map
+func (m Map) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		return entries('%', m.Entries, proto)
+	}
+	return aggregate('*', flatten(m.Entries), proto)
+}
+
+// Set represents a RESP3 Set, sent as an array in RESP2
+type Set struct {
+	Values []RedisValue
+}
+
+// Serialize returns the RESP representation of a Set
+func (s Set) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		return aggregate('~', s.Values, proto)
+	}
+	return aggregate('*', s.Values, proto)
+}
+
+// Attribute represents a RESP3 Attribute: auxiliary key-value data sent
+// ahead of the reply it describes. RESP2 clients only receive the reply.
+type Attribute struct {
+	Entries []MapEntry
+	Value   RedisValue
+}
+
+// Serialize returns the RESP representation of an Attribute and its reply
+func (a Attribute) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		return append(entries('|', a.Entries, proto), a.Value.Serialize(proto)...)
+	}
+	return a.Value.Serialize(proto)
+}
+
+// Push represents a RESP3 Push, an out-of-band message such as a pub/sub
+// message. It is sent as an array in RESP2.
+type Push struct {
+	Values []RedisValue
+}
+
+// Serialize returns the RESP representation of a Push
+func (p Push) Serialize(proto Protocol) []byte {
+	if proto == RESP3 {
+		return aggregate('>', p.Values, proto)
+	}
+	return aggregate('*', p.Values, proto)
+}
+
+// Replies is a sequence of values written back-to-back as separate replies.
+// Commands such as SUBSCRIBE answer once per argument using it.
+type Replies struct {
+	Values []RedisValue
+}
+
+// Serialize returns the concatenated RESP representation of every reply
+func (r Replies) Serialize(proto Protocol) []byte {
+	var result []byte
+	for _, value := range r.Values {
+		result = append(result, value.Serialize(proto)...)
 	}
 	return result
 }
 
-// NullBulkString represents a RESP Null Bulk String
-var NullBulkString = BulkString{Value: ""}
+// bulk encodes a length-prefixed string type such as a bulk string
+func bulk(prefix byte, s []byte) []byte {
+	result := make([]byte, 0, len(s)+16)
+	result = append(result, prefix)
+	result = strconv.AppendInt(result, int64(len(s)), 10)
+	result = append(result, "\r\n"...)
+	result = append(result, s...)
+	return append(result, "\r\n"...)
+}
+
+// aggregate encodes a counted sequence of values such as an array
+func aggregate(prefix byte, values []RedisValue, proto Protocol) []byte {
+	result := []byte{prefix}
+	result = strconv.AppendInt(result, int64(len(values)), 10)
+	result = append(result, "\r\n"...)
+	for _, value := range values {
+		result = append(result, value.Serialize(proto)...)
+	}
+	return result
+}
 
-// CustomResponse allows sending raw RESP data for compatibility with specific implementations
-type CustomResponse struct {
-	Data []byte
+// entries encodes a counted sequence of key-value pairs such as a map
+func entries(prefix byte, pairs []MapEntry, proto Protocol) []byte {
+	result := []byte{prefix}
+	result = strconv.AppendInt(result, int64(len(pairs)), 10)
+	result = append(result, "\r\n"...)
+	for _, pair := range pairs {
+		result = append(result, pair.Key.Serialize(proto)...)
+		result = append(result, pair.Value.Serialize(proto)...)
+	}
+	return result
 }
 
-// Serialize returns the raw bytes for the custom response
-func (c *CustomResponse) Serialize() []byte {
-	return c.Data
+// flatten turns key-value pairs into alternating keys and values
+func flatten(pairs []MapEntry) []RedisValue {
+	values := make([]RedisValue, 0, len(pairs)*2)
+	for _, pair := range pairs {
+		values = append(values, pair.Key, pair.Value)
+	}
+	return values
 }
@@ -0,0 +1,63 @@
+package resp
+
+import (
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestSerialize(t *testing.T) {
+	bigNumber, _ := new(big.Int).SetString("3492890328409238509324850943850943825024385", 10)
+	tests := []struct {
+		name  string
+		value RedisValue
+		resp2 string
+		resp3 string
+	}{
+		{"simple string", SimpleString{Value: "OK"}, "+OK\r\n", "+OK\r\n"},
+		{"error", Error{Value: "ERR bad"}, "-ERR bad\r\n", "-ERR bad\r\n"},
+		{"bulk string", NewBulkString("hello"), "$5\r\nhello\r\n", "$5\r\nhello\r\n"},
+		{"empty bulk string", NewBulkString(""), "$0\r\n\r\n", "$0\r\n\r\n"},
+		{"nil bulk string", BulkString{}, "$0\r\n\r\n", "$0\r\n\r\n"},
+		{"binary bulk string", BulkString{Value: []byte("a\x00\r\n")}, "$4\r\na\x00\r\n\r\n", "$4\r\na\x00\r\n\r\n"},
+		{"integer", Integer{Value: -42}, ":-42\r\n", ":-42\r\n"},
+		{"null", Null{}, "$-1\r\n", "_\r\n"},
+		{"double", Double{Value: 1.5}, "$3\r\n1.5\r\n", ",1.5\r\n"},
+		{"integral double", Double{Value: 3}, "$1\r\n3\r\n", ",3\r\n"},
+		{"infinity", Double{Value: math.Inf(1)}, "$3\r\ninf\r\n", ",inf\r\n"},
+		{"negative infinity", Double{Value: math.Inf(-1)}, "$4\r\n-inf\r\n", ",-inf\r\n"},
+		{"nan", Double{Value: math.NaN()}, "$3\r\nnan\r\n", ",nan\r\n"},
+		{"true", Boolean{Value: true}, ":1\r\n", "#t\r\n"},
+		{"false", Boolean{Value: false}, ":0\r\n", "#f\r\n"},
+		{"big number", BigNumber{Value: bigNumber}, "$43\r\n3492890328409238509324850943850943825024385\r\n", "(3492890328409238509324850943850943825024385\r\n"},
+		{"blob error", BlobError{Value: "SYNTAX invalid\r\nsyntax"}, "-SYNTAX invalid  syntax\r\n", "!22\r\nSYNTAX invalid\r\nsyntax\r\n"},
+		{"verbatim string", VerbatimString{Format: "txt", Value: []byte("Some string")}, "$11\r\nSome string\r\n", "=15\r\ntxt:Some string\r\n"},
+		{"array", Array{Values: []RedisValue{Integer{Value: 1}, Null{}}}, "*2\r\n:1\r\n$-1\r\n", "*2\r\n:1\r\n_\r\n"},
+		{"empty array", Array{Values: []RedisValue{}}, "*0\r\n", "*0\r\n"},
+		{"map", Map{Entries: []MapEntry{
+			{Key: NewBulkString("b"), Value: Integer{Value: 2}},
+			{Key: NewBulkString("a"), Value: Boolean{Value: true}},
+		}}, "*4\r\n$1\r\nb\r\n:2\r\n$1\r\na\r\n:1\r\n", "%2\r\n$1\r\nb\r\n:2\r\n$1\r\na\r\n#t\r\n"},
+		{"set", Set{Values: []RedisValue{NewBulkString("x")}}, "*1\r\n$1\r\nx\r\n", "~1\r\n$1\r\nx\r\n"},
+		{"attribute", Attribute{
+			Entries: []MapEntry{{Key: NewBulkString("ttl"), Value: Integer{Value: 3}}},
+			Value:   NewBulkString("v"),
+		}, "$1\r\nv\r\n", "|1\r\n$3\r\nttl\r\n:3\r\n$1\r\nv\r\n"},
+		{"push", Push{Values: []RedisValue{NewBulkString("message"), Double{Value: 0.5}}}, "*2\r\n$7\r\nmessage\r\n$3\r\n0.5\r\n", ">2\r\n$7\r\nmessage\r\n,0.5\r\n"},
+		{"replies", Replies{Values: []RedisValue{SimpleString{Value: "OK"}, Null{}}}, "+OK\r\n$-1\r\n", "+OK\r\n_\r\n"},
+		{"nested", Map{Entries: []MapEntry{
+			{Key: NewBulkString("list"), Value: Set{Values: []RedisValue{Null{}}}},
+		}}, "*2\r\n$4\r\nlist\r\n*1\r\n$-1\r\n", "%1\r\n$4\r\nlist\r\n~1\r\n_\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tt.value.Serialize(RESP2)); got != tt.resp2 {
+				t.Errorf("RESP2 %q, want %q", got, tt.resp2)
+			}
+			if got := string(tt.value.Serialize(RESP3)); got != tt.resp3 {
+				t.Errorf("RESP3 %q, want %q", got, tt.resp3)
+			}
+		})
+	}
+}
@@ -0,0 +1,302 @@
+package resp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"sync/atomic"
+)
+
+const (
+	// initialBufferSize is the size of a connection's read buffer. It grows
+	// as needed to hold a whole command.
+	initialBufferSize = 16 * 1024
+
+	// maxIdleBufferSize is the largest read buffer kept once it has been
+	// drained; buffers grown for big commands are released afterwards
+	maxIdleBufferSize = 64 * 1024
+
+	// maxIdleArgs is the largest argument list kept for reuse
+	maxIdleArgs = 1024
+)
+
+// StreamParser parses commands in place: each connection gets a read
+// buffer that commands are scanned from, and arguments are slices into it.
+// Apart from inline commands, parsing a command allocates nothing once the
+// buffers have warmed up.
+type StreamParser struct {
+	limits atomic.Pointer[Limits]
+}
+
+// Ensure StreamParser implements Parser
+var _ Parser = (*StreamParser)(nil)
+
+// NewStreamParser creates a zero-copy RESP parser enforcing DefaultLimits
+func NewStreamParser() *StreamParser {
+	p := &StreamParser{}
+	p.SetLimits(DefaultLimits)
+	return p
+}
+
+// SetLimits changes the limits applied to subsequently parsed commands. It
+// is safe to call while connections are being served.
+func (p *StreamParser) SetLimits(limits Limits) {
+	p.limits.Store(&limits)
+}
+
+// NewReader returns a StreamReader parsing commands from r
+func (p *StreamParser) NewReader(r io.Reader) CommandReader {
+	return &StreamReader{
+		r:      r,
+		parser: p,
+		buf:    make([]byte, initialBufferSize),
+		count:  -1,
+	}
+}
+
+// span locates an argument in the read buffer, relative to the start of
+// the command
+type span struct {
+	offset int
+	length int
+}
+
+// StreamReader reads commands from a connection into a reusable buffer.
+// A command may arrive across any number of reads; parsing resumes where it
+// stopped instead of rescanning what was already seen.
+type StreamReader struct {
+	r      io.Reader
+	parser *StreamParser
+
+	// Unread data is buf[start:end]
+	buf   []byte
+	start int
+	end   int
+
+	// State of the multibulk command being received. count is -1 until its
+	// header has been parsed; pos and spans are relative to start.
+	count    int64
+	pos      int
+	queryLen int64
+	spans    []span
+
+	// args is reused for every command returned
+	args [][]byte
+
+	// peeked holds a command parsed by HasCommand for ReadCommand to return
+	peeked    [][]byte
+	peekedErr error
+}
+
+// ReadCommand reads the next command. The returned arguments point into
+// the reader's buffer and are only valid until the next call.
+func (s *StreamReader) ReadCommand() ([][]byte, error) {
+	if s.peeked != nil || s.peekedErr != nil {
+		args, err := s.peeked, s.peekedErr
+		s.peeked, s.peekedErr = nil, nil
+		return args, err
+	}
+
+	s.reclaim()
+	for {
+		args, err := s.parse()
+		if err != nil || args != nil {
+			return args, err
+		}
+
+		if err := s.fill(); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// HasCommand reports whether a complete (or malformed) command is already
+// buffered
+func (s *StreamReader) HasCommand() bool {
+	if s.peeked != nil || s.peekedErr != nil {
+		return true
+	}
+
+	s.peeked, s.peekedErr = s.parse()
+	return s.peeked != nil || s.peekedErr != nil
+}
+
+// reclaim resets a drained buffer and releases memory grown for big commands
+func (s *StreamReader) reclaim() {
+	if s.start != s.end || s.count >= 0 {
+		return
+	}
+
+	s.start, s.end = 0, 0
+	if len(s.buf) > maxIdleBufferSize {
+		s.buf = make([]byte, initialBufferSize)
+	}
+	if cap(s.args) > maxIdleArgs {
+		s.args, s.spans = nil, nil
+	}
+}
+
+// fill reads more data from the connection, making room first by moving
+// unread data to the front of the buffer or by growing it. The buffer only
+// grows when it is full, so memory follows the data actually received.
+func (s *StreamReader) fill() error {
+	if s.end == len(s.buf) {
+		if s.start > 0 {
+			copy(s.buf, s.buf[s.start:s.end])
+			s.end -= s.start
+			s.start = 0
+		} else {
+			grown := make([]byte, 2*len(s.buf))
+			copy(grown, s.buf[:s.end])
+			s.buf = grown
+		}
+	}
+
+	n, err := s.r.Read(s.buf[s.end:])
+	s.end += n
+	if n > 0 {
+		return nil
+	}
+
+	if err == io.EOF && s.end > s.start {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// parse tries to parse a command from the buffered data. It returns nil
+// arguments and a nil error when more data is needed.
+func (s *StreamReader) parse() ([][]byte, error) {
+	limits := s.parser.limits.Load()
+	data := s.buf[s.start:s.end]
+
+	if s.count < 0 {
+		if len(data) == 0 {
+			return nil, nil
+		}
+
+		tooBig := "too big mbulk count string"
+		if data[0] != '*' {
+			tooBig = "too big inline request"
+		}
+		nl, err := findLine(data, tooBig)
+		if nl < 0 {
+			return nil, err
+		}
+
+		if data[0] != '*' {
+			s.start += nl + 1
+			return SplitInline(trimCR(data[:nl]))
+		}
+
+		count, ok := parseInt(trimCR(data[1:nl]))
+		if !ok || count > limits.MaxMultibulkLen {
+			return nil, &ProtocolError{Message: "invalid multibulk length"}
+		}
+		if count <= 0 {
+			s.start += nl + 1
+			return [][]byte{}, nil
+		}
+
+		s.count = count
+		s.pos = nl + 1
+		s.queryLen = int64(nl + 1)
+		s.spans = s.spans[:0]
+	}
+
+	for int64(len(s.spans)) < s.count {
+		rest := data[s.pos:]
+		nl, err := findLine(rest, "too big bulk count string")
+		if nl < 0 {
+			return nil, err
+		}
+
+		header := trimCR(rest[:nl])
+		if len(header) == 0 || header[0] != '$' {
+			got := "\\r"
+			if len(header) > 0 {
+				got = string(header[0])
+			}
+			return nil, &ProtocolError{Message: fmt.Sprintf("expected '$', got '%s'", got)}
+		}
+
+		length, ok := parseInt(header[1:])
+		if !ok || length < 0 || length > limits.MaxBulkLen {
+			return nil, &ProtocolError{Message: "invalid bulk length"}
+		}
+
+		// Check the limit before computing the frame size, which lengths
+		// close to the largest int64 would overflow
+		if length > limits.MaxQueryBuffer-s.queryLen-int64(nl+3) {
+			return nil, &ProtocolError{Message: "client query buffer limit exceeded"}
+		}
+		frame := nl + 1 + int(length) + 2
+		if len(rest) < frame {
+			return nil, nil
+		}
+
+		s.spans = append(s.spans, span{offset: s.pos + nl + 1, length: int(length)})
+		s.pos += frame
+		s.queryLen += int64(frame)
+	}
+
+	args := s.args[:0]
+	for _, sp := range s.spans {
+		end := sp.offset + sp.length
+		args = append(args, data[sp.offset:end:end])
+	}
+	s.args = args
+
+	s.start += s.pos
+	s.count = -1
+	return args, nil
+}
+
+// findLine returns the index of the newline ending the line data starts
+// with, or -1 if it hasn't arrived yet. Like readLine, it rejects lines
+// longer than maxInlineSize with a protocol error carrying tooBig, whether
+// or not they are complete.
+func findLine(data []byte, tooBig string) (int, error) {
+	nl := bytes.IndexByte(data, '\n')
+	if nl >= maxInlineSize || (nl < 0 && len(data) > maxInlineSize) {
+		return -1, &ProtocolError{Message: tooBig}
+	}
+	return nl, nil
+}
+
+// trimCR strips a trailing \r from a line
+func trimCR(line []byte) []byte {
+	if len(line) > 0 && line[len(line)-1] == '\r' {
+		return line[:len(line)-1]
+	}
+	return line
+}
+
+// parseInt parses a base-10 integer without allocating
+func parseInt(b []byte) (int64, bool) {
+	neg := len(b) > 0 && b[0] == '-'
+	if neg || (len(b) > 0 && b[0] == '+') {
+		b = b[1:]
+	}
+	if len(b) == 0 {
+		return 0, false
+	}
+
+	var n int64
+	for _, c := range b {
+		if c < '0' || c > '9' {
+			return 0, false
+		}
+		d := int64(c - '0')
+		if n > (math.MaxInt64-d)/10 {
+			return 0, false
+		}
+		n = n*10 + d
+	}
+
+	if neg {
+		return -n, true
+	}
+	return n, true
+}
@@ -0,0 +1,162 @@
+package server
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestListenTCP(t *testing.T) {
+	tests := []struct {
+		name    string
+		bind    []string
+		want    int
+		wantErr bool
+	}{
+		{"loopback", []string{"127.0.0.1"}, 1, false},
+		{"wildcard", []string{"*"}, 1, false},
+		{"several addresses", []string{"127.0.0.1", "*"}, 2, false},
+		{"optional address unavailable", []string{"127.0.0.1", "-192.0.2.1"}, 1, false},
+		{"required address unavailable", []string{"127.0.0.1", "192.0.2.1"}, 0, true},
+		{"only optional addresses unavailable", []string{"-192.0.2.1"}, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Port 0 gives each address its own free port
+			listeners, err := listenTCP(tt.bind, 0)
+			defer closeAll(listeners)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("listenTCP() = %v, want error %v", err, tt.wantErr)
+			}
+			if len(listeners) != tt.want {
+				t.Errorf("%d listeners, want %d", len(listeners), tt.want)
+			}
+		})
+	}
+}
+
+// addrConn is a connection from remote
+type addrConn struct {
+	net.Conn
+	remote net.Addr
+}
+
+func (c addrConn) RemoteAddr() net.Addr {
+	return c.remote
+}
+
+func TestProtectedMode(t *testing.T) {
+	loopback := &net.TCPAddr{IP: net.IPv4(127, 0, 0, 1), Port: 5000}
+	loopback6 := &net.TCPAddr{IP: net.IPv6loopback, Port: 5000}
+	external := &net.TCPAddr{IP: net.IPv4(192, 0, 2, 1), Port: 5000}
+	unix := &net.UnixAddr{Name: "@", Net: "unix"}
+
+	tests := []struct {
+		name      string
+		protected string
+		bind      []string
+		password  bool
+		remote    net.Addr
+		wantErr   bool
+	}{
+		{"loopback", "yes", nil, false, loopback, false},
+		{"IPv6 loopback", "yes", nil, false, loopback6, false},
+		{"unix socket", "yes", nil, false, unix, false},
+		{"external", "yes", nil, false, external, true},
+		{"external with protected mode off", "no", nil, false, external, false},
+		{"external with a password", "yes", nil, true, external, false},
+		{"external with explicit bind", "yes", []string{"192.0.2.10"}, false, external, true},
+		{"external with bind 0.0.0.0", "yes", []string{"0.0.0.0"}, false, external, true},
+		{"external with bind 0.0.0.0 and protected mode off", "no", []string{"0.0.0.0"}, false, external, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			setConfig(t, s, map[string]string{"protected-mode": tt.protected})
+			if tt.bind != nil {
+				s.config.Bind = tt.bind
+			}
+			if tt.password {
+				acls := newTestACL(s)
+				acls.SetDefaultPassword("secret")
+				s.SetACL(acls)
+			}
+
+			local, remote := net.Pipe()
+			defer local.Close()
+			c, err := s.addClient(addrConn{Conn: remote, remote: tt.remote})
+			if c != nil {
+				defer c.Close()
+			}
+			if tt.wantErr != errors.Is(err, errProtectedMode) {
+				t.Errorf("addClient() = %v, want protected mode error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Refused clients are told how to get out of protected mode
+func TestProtectedModeReply(t *testing.T) {
+	s := newTestServer(t)
+	local, remote := net.Pipe()
+	defer local.Close()
+	go s.reject(remote, errProtectedMode)
+
+	local.SetReadDeadline(time.Now().Add(time.Second))
+	reply, err := io.ReadAll(local)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "-" + protectedModeError + "\r\n"; string(reply) != want {
+		t.Errorf("reply %q, want %q", reply, want)
+	}
+}
+
+// Listening on every address doesn't lift protected mode: without a
+// password, connections from other hosts are refused
+func TestProtectedModeWildcardBind(t *testing.T) {
+	var external net.IP
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, addr := range addrs {
+		if ipNet, ok := addr.(*net.IPNet); ok && !ipNet.IP.IsLoopback() && ipNet.IP.To4() != nil {
+			external = ipNet.IP
+			break
+		}
+	}
+	if external == nil {
+		t.Skip("no external IPv4 address")
+	}
+
+	s := newTestServer(t)
+	s.config.Bind = []string{"0.0.0.0"}
+	listeners, err := listenTCP(s.config.Bind, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer closeAll(listeners)
+	go s.serve(listeners[0])
+
+	port := listeners[0].Addr().(*net.TCPAddr).Port
+	conn, err := net.Dial("tcp", net.JoinHostPort(external.String(), strconv.Itoa(port)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	reply, err := io.ReadAll(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "-" + protectedModeError + "\r\n"; string(reply) != want {
+		t.Errorf("reply %q, want %q", reply, want)
+	}
+}
@@ -0,0 +1,78 @@
+package server
+
+import (
+	"net"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// keepalive returns whether keepalive is enabled on conn and its idle time
+func keepalive(t *testing.T, conn *net.TCPConn) (bool, time.Duration) {
+	t.Helper()
+	raw, err := conn.SyscallConn()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var enabled, idle int
+	var sockErr error
+	err = raw.Control(func(fd uintptr) {
+		if enabled, sockErr = syscall.GetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_KEEPALIVE); sockErr != nil {
+			return
+		}
+		idle, sockErr = syscall.GetsockoptInt(int(fd), syscall.IPPROTO_TCP, syscall.TCP_KEEPIDLE)
+	})
+	if err == nil {
+		err = sockErr
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enabled != 0, time.Duration(idle) * time.Second
+}
+
+func TestSetKeepalive(t *testing.T) {
+	tests := []struct {
+		value       string
+		wantEnabled bool
+		wantIdle    time.Duration
+	}{
+		{"60", true, 60 * time.Second},
+		{"300", true, 300 * time.Second},
+		{"0", false, 0},
+	}
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			s := newTestServer(t)
+			setConfig(t, s, map[string]string{"tcp-keepalive": tt.value})
+
+			peer, err := net.Dial("tcp", listener.Addr().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer peer.Close()
+			conn, err := listener.Accept()
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer conn.Close()
+
+			s.setKeepalive(conn)
+			enabled, idle := keepalive(t, conn.(*net.TCPConn))
+			if enabled != tt.wantEnabled {
+				t.Errorf("keepalive enabled = %v, want %v", enabled, tt.wantEnabled)
+			}
+			if tt.wantEnabled && idle != tt.wantIdle {
+				t.Errorf("keepalive idle time %v, want %v", idle, tt.wantIdle)
+			}
+		})
+	}
+}
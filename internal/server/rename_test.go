@@ -0,0 +1,73 @@
+package server
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/command"
+	"github.com/codecrafters-io/redis-starter-go/internal/config"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/stats"
+	"github.com/codecrafters-io/redis-starter-go/internal/storage/memory"
+)
+
+// Renamed commands are called by their new name only, while ACL rules and
+// stats keep referring to them by their original name. Disabled commands
+// can't be called at all.
+func TestRenamedCommands(t *testing.T) {
+	s := newTestServer(t)
+	store := memory.NewStore()
+	for _, handler := range []command.Handler{command.NewGetCommand(store), command.NewSetCommand(store), command.NewKeysCommand(store), command.NewConfigCommand(config.NewConfig())} {
+		if err := s.commands.Register(handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	acls := newTestACL(s)
+	if err := acls.SetUser("default", []string{"-set"}); err != nil {
+		t.Fatal(err)
+	}
+	s.SetACL(acls)
+	commandStats := stats.NewCommandStats()
+	s.commands.Use(command.NewStatsMiddleware(commandStats))
+	s.commands.Use(command.NewACLMiddleware(acls))
+
+	for _, rename := range []config.Rename{{Name: "keys", NewName: "MYKEYS"}, {Name: "SET", NewName: "store"}, {Name: "CONFIG"}} {
+		if err := s.commands.Rename(rename.Name, rename.NewName); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setConfig(t, s, map[string]string{"protected-mode": "no"})
+	c, _ := newTestClient(t, s)
+
+	steps := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"KEYS", "*"}, "-ERR unknown command 'KEYS'\r\n"},
+		{[]string{"mykeys", "*"}, "*0\r\n"},
+		{[]string{"MYKEYS"}, "-ERR wrong number of arguments for 'mykeys' command\r\n"},
+		{[]string{"STORE", "key", "value"}, "-NOPERM User default has no permissions to run the 'set' command\r\n"},
+		{[]string{"CONFIG", "GET", "port"}, "-ERR unknown command 'CONFIG'\r\n"},
+		{[]string{"GET", "key"}, "$-1\r\n"},
+	}
+
+	for _, step := range steps {
+		args := make([][]byte, len(step.args))
+		for i, arg := range step.args {
+			args[i] = []byte(arg)
+		}
+		if got := string(s.dispatch(c, args).Serialize(resp.RESP2)); got != step.want {
+			t.Errorf("%v = %q, want %q", step.args, got, step.want)
+		}
+	}
+
+	info := commandStats.Info()
+	for _, want := range []string{"cmdstat_keys:calls=1,", "cmdstat_set:calls=0,", "cmdstat_get:calls=1,"} {
+		if !strings.Contains(info, want) {
+			t.Errorf("commandstats %q missing %q", info, want)
+		}
+	}
+	if strings.Contains(info, "mykeys") || strings.Contains(info, "store") {
+		t.Errorf("commandstats %q use the new names", info)
+	}
+}
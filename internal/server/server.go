@@ -1,92 +1,750 @@
 package server
 
 import (
-	"bufio"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"io"
 	"net"
+	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
 	"github.com/codecrafters-io/redis-starter-go/internal/command"
+	"github.com/codecrafters-io/redis-starter-go/internal/config"
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/rdb"
 	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/stats"
 )
 
+// subscribedModeCommands are the only commands a RESP2 client may issue
+// while it holds at least one subscription. RESP3 clients receive messages
+// as push replies and may keep issuing any command.
+var subscribedModeCommands = map[string]bool{
+	"SUBSCRIBE":    true,
+	"UNSUBSCRIBE":  true,
+	"PSUBSCRIBE":   true,
+	"PUNSUBSCRIBE": true,
+	"SSUBSCRIBE":   true,
+	"SUNSUBSCRIBE": true,
+	"PING":         true,
+	"QUIT":         true,
+	"RESET":        true,
+}
+
+// Shutdown errors reported to SHUTDOWN callers
+var (
+	errShutdownFailed     = errors.New("Errors trying to SHUTDOWN. Check logs.")
+	errShutdownInProgress = errors.New("shutdown already in progress")
+	errNoShutdown         = errors.New("No shutdown in progress.")
+)
+
+// Reasons for refusing a connection
+var (
+	errMaxClients    = errors.New("max number of clients reached")
+	errProtectedMode = errors.New("connection from a non-loopback address in protected mode")
+	errShuttingDown  = errors.New("server is shutting down")
+)
+
+// cronInterval is how often the server cron runs background checks, such
+// as disconnecting idle clients
+const cronInterval = 100 * time.Millisecond
+
+// Saver writes the dataset to disk
+type Saver interface {
+	// SaveOnShutdown saves the dataset once background saves are done
+	SaveOnShutdown() error
+
+	// CheckSavePoints starts a background save if a save point was reached
+	CheckSavePoints(points []rdb.SavePoint)
+}
+
+// closeTimeout bounds how long a client being closed on shutdown may take
+// to receive its pending output
+const closeTimeout = time.Second
+
 // Server represents a Redis server
 type Server struct {
-	host     string
-	port     int
+	config   *config.Config
 	commands command.Registry
 	parser   resp.Parser
+	pubsub   *pubsub.Hub
+	limits   *client.OutputLimits
+	stats    *stats.Stats
+
+	// tlsConfig is the configuration of new TLS connections, swapped when
+	// certificates are reloaded
+	tlsConfig atomic.Pointer[tls.Config]
+
+	// acl authenticates clients and checks their permissions; nil means
+	// every client may run every command
+	acl *acl.ACL
+
+	// saver writes the dataset to disk at the configured save points and
+	// on shutdown; nil when persistence is not available
+	saver Saver
+
+	// mu guards the fields below. idle is signaled whenever a command
+	// finishes or the server stops draining.
+	mu           sync.Mutex
+	idle         *sync.Cond
+	listeners    []net.Listener
+	clients      map[*client.Client]struct{}
+	executing    int
+	draining     bool
+	closed       bool
+	shuttingDown bool
+	abort        chan struct{}
+
+	// done is closed once a shutdown has completed
+	done chan struct{}
 }
 
-// NewServer creates a new Redis server
-func NewServer(host string, port int, commands command.Registry, parser resp.Parser) *Server {
-	return &Server{
-		host:     host,
-		port:     port,
+// NewServer creates a new Redis server listening on the configured
+// addresses. Client output is bounded by limits.
+func NewServer(cfg *config.Config, commands command.Registry, parser resp.Parser, hub *pubsub.Hub,
+	limits *client.OutputLimits, stats *stats.Stats) *Server {
+	s := &Server{
+		config:   cfg,
 		commands: commands,
 		parser:   parser,
+		pubsub:   hub,
+		limits:   limits,
+		stats:    stats,
+		clients:  make(map[*client.Client]struct{}),
+		done:     make(chan struct{}),
 	}
+	s.idle = sync.NewCond(&s.mu)
+	return s
+}
+
+// SetACL sets the access control list deciding whether new clients must
+// authenticate and what each client may run
+func (s *Server) SetACL(a *acl.ACL) {
+	s.acl = a
+}
+
+// SetSaver sets how the dataset is saved at save points and on shutdown
+func (s *Server) SetSaver(saver Saver) {
+	s.saver = saver
 }
 
-// Start starts the Redis server
+// Done returns a channel closed once the server has shut down
+func (s *Server) Done() <-chan struct{} {
+	return s.done
+}
+
+// ReloadTLS loads the configured certificates for subsequent TLS
+// connections. On error the previous certificates stay in use.
+func (s *Server) ReloadTLS() error {
+	tlsConfig, err := s.config.TLSSettings().Load()
+	if err != nil {
+		return err
+	}
+
+	s.tlsConfig.Store(tlsConfig)
+	return nil
+}
+
+// Start starts the Redis server, listening on TCP unless the port is 0,
+// and on a TLS port and a Unix socket if configured. It returns nil once
+// the server has been shut down.
 func (s *Server) Start() error {
-	addr := fmt.Sprintf("%s:%d", s.host, s.port)
-	listener, err := net.Listen("tcp", addr)
+	var listeners []net.Listener
+	if s.config.Port != 0 {
+		tcpListeners, err := listenTCP(s.config.Bind, s.config.Port)
+		if err != nil {
+			return err
+		}
+		listeners = append(listeners, tcpListeners...)
+	}
+	if s.config.TLSPort != 0 {
+		tlsListeners, err := s.listenTLS(s.config.Bind, s.config.TLSPort)
+		if err != nil {
+			closeAll(listeners)
+			return err
+		}
+		listeners = append(listeners, tlsListeners...)
+	}
+	if s.config.UnixSocket != "" {
+		listener, err := listenUnix(s.config.UnixSocket, s.config.UnixSocketPerm)
+		if err != nil {
+			closeAll(listeners)
+			return err
+		}
+		listeners = append(listeners, listener)
+	}
+	if len(listeners) == 0 {
+		return errors.New("no port or unix socket to listen on")
+	}
+	defer closeAll(listeners)
+
+	s.mu.Lock()
+	s.listeners = listeners
+	s.mu.Unlock()
+
+	go s.cron()
+
+	errs := make(chan error, len(listeners))
+	for _, listener := range listeners {
+		fmt.Printf("Redis server running on %s...\n", listener.Addr())
+		go func() {
+			errs <- s.serve(listener)
+		}()
+	}
+
+	return <-errs
+}
+
+// listenTCP listens on port at every bind address. "*" stands for every
+// IPv4 address and "::*" for every IPv6 address; addresses prefixed with
+// "-" are optional and skipped if they are unavailable, e.g. on hosts
+// without IPv6.
+func listenTCP(bind []string, port int) ([]net.Listener, error) {
+	var listeners []net.Listener
+	for _, addr := range bind {
+		host, optional := strings.CutPrefix(addr, "-")
+		switch host {
+		case "*":
+			host = "0.0.0.0"
+		case "::*":
+			host = "::"
+		}
+
+		// Listen on IPv4 and IPv6 separately, so that binding both wildcard
+		// addresses doesn't conflict
+		network := "tcp"
+		if ip := net.ParseIP(host); ip != nil {
+			network = "tcp6"
+			if ip.To4() != nil {
+				network = "tcp4"
+			}
+		}
+
+		hostport := net.JoinHostPort(host, strconv.Itoa(port))
+		listener, err := net.Listen(network, hostport)
+		if err != nil {
+			if optional {
+				fmt.Printf("Warning: could not bind to optional address %s: %v\n", hostport, err)
+				continue
+			}
+			closeAll(listeners)
+			return nil, fmt.Errorf("failed to bind to %s: %w", hostport, err)
+		}
+		listeners = append(listeners, listener)
+	}
+
+	if len(listeners) == 0 {
+		return nil, fmt.Errorf("failed to bind to any address on port %d", port)
+	}
+	return listeners, nil
+}
+
+// listenTLS listens for TLS connections on port at every bind address.
+// Each handshake uses the configuration loaded last, so reloaded
+// certificates apply to new connections without a restart.
+func (s *Server) listenTLS(bind []string, port int) ([]net.Listener, error) {
+	if err := s.ReloadTLS(); err != nil {
+		return nil, fmt.Errorf("failed to configure TLS: %w", err)
+	}
+
+	listeners, err := listenTCP(bind, port)
 	if err != nil {
-		return fmt.Errorf("failed to bind to %s: %w", addr, err)
+		return nil, err
+	}
+
+	tlsConfig := &tls.Config{
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			return s.tlsConfig.Load(), nil
+		},
 	}
-	defer listener.Close()
+	for i, listener := range listeners {
+		listeners[i] = tls.NewListener(listener, tlsConfig)
+	}
+	return listeners, nil
+}
+
+// listenUnix listens on a Unix socket, replacing a stale socket file left
+// behind by a previous run, and applies perm to the socket unless it is 0
+func listenUnix(path string, perm os.FileMode) (net.Listener, error) {
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove stale unix socket %s: %w", path, err)
+	}
+
+	listener, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to bind to unix socket %s: %w", path, err)
+	}
+
+	if perm != 0 {
+		if err := os.Chmod(path, perm); err != nil {
+			listener.Close()
+			return nil, fmt.Errorf("failed to set permissions of unix socket %s: %w", path, err)
+		}
+	}
+
+	return listener, nil
+}
+
+// closeAll closes every listener
+func closeAll(listeners []net.Listener) {
+	for _, listener := range listeners {
+		listener.Close()
+	}
+}
 
-	fmt.Printf("Redis server running on %s...\n", addr)
+// serve accepts connections from a listener until the server shuts down
+func (s *Server) serve(listener net.Listener) error {
 	for {
 		conn, err := listener.Accept()
 		if err != nil {
+			if s.isClosed() {
+				<-s.done
+				return nil
+			}
 			return fmt.Errorf("error accepting connection: %w", err)
 		}
 
-		go s.handleConnection(conn)
+		s.setKeepalive(conn)
+		c, err := s.addClient(conn)
+		if err != nil {
+			s.reject(conn, err)
+			continue
+		}
+
+		go s.handleConnection(c)
 	}
 }
 
-// handleConnection processes client connections
-func (s *Server) handleConnection(conn net.Conn) {
+// protectedModeError explains why a connection was refused in protected
+// mode and how to fix it
+const protectedModeError = "DENIED Redis is running in protected mode because protected mode is enabled and no password is set for the default user. " +
+	"In this mode connections are only accepted from the loopback interface. " +
+	"If you want to connect from external computers to Redis you may adopt one of the following solutions: " +
+	"1) Just disable protected mode sending the command 'CONFIG SET protected-mode no' from the loopback interface by connecting to Redis from the same host the server is running, however MAKE SURE Redis is not publicly accessible from internet if you do so. " +
+	"2) Alternatively you can just disable the protected mode by editing the Redis configuration file, and setting the protected mode option to 'no', and then restarting the server. " +
+	"3) If you started the server manually just for testing, restart it with the '--protected-mode no' option. " +
+	"4) Set up an authentication password for the default user. " +
+	"NOTE: You only need to do one of the above things in order for the server to start accepting connections from the outside."
+
+// reject refuses a connection, telling the client why unless the server is
+// shutting down
+func (s *Server) reject(conn net.Conn, reason error) {
 	defer conn.Close()
-	reader := bufio.NewReader(conn)
+
+	var reply resp.Error
+	switch reason {
+	case errMaxClients:
+		s.stats.RejectedConnections.Add(1)
+		reply = resp.Error{Value: "ERR " + reason.Error()}
+	case errProtectedMode:
+		reply = resp.Error{Value: protectedModeError}
+	default:
+		return
+	}
+
+	conn.SetWriteDeadline(time.Now().Add(closeTimeout))
+	conn.Write(reply.Serialize(resp.RESP2))
+}
+
+// protected reports whether protected mode is in effect: it is enabled and
+// the default user needs no password. The bind addresses don't matter.
+func (s *Server) protected() bool {
+	return s.config.GetProtectedMode() && (s.acl == nil || !s.acl.Required())
+}
+
+// isLocal reports whether a connection comes from the loopback interface
+// or a Unix socket
+func isLocal(conn net.Conn) bool {
+	switch addr := conn.RemoteAddr().(type) {
+	case *net.TCPAddr:
+		return addr.IP.IsLoopback()
+	case *net.UnixAddr:
+		return true
+	default:
+		return conn.LocalAddr().Network() == "unix"
+	}
+}
+
+// setKeepalive applies the configured TCP keepalive period to a connection
+func (s *Server) setKeepalive(conn net.Conn) {
+	if tlsConn, ok := conn.(*tls.Conn); ok {
+		conn = tlsConn.NetConn()
+	}
+	tcpConn, ok := conn.(*net.TCPConn)
+	if !ok {
+		return
+	}
+
+	period := s.config.GetTCPKeepalive()
+	tcpConn.SetKeepAlive(period > 0)
+	if period > 0 {
+		tcpConn.SetKeepAlivePeriod(period)
+	}
+}
+
+// cron periodically runs background checks until the server shuts down
+func (s *Server) cron() {
+	ticker := time.NewTicker(cronInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-s.done:
+			return
+		case <-ticker.C:
+			s.closeIdleClients()
+			s.checkOutputLimits()
+			if s.saver != nil {
+				s.saver.CheckSavePoints(s.config.GetSavePoints())
+			}
+		}
+	}
+}
+
+// closeIdleClients disconnects clients that sent no command within the
+// configured timeout. Replicas, blocked clients and subscribers are
+// expected to stay quiet, so they are never considered idle.
+func (s *Server) closeIdleClients() {
+	timeout := s.config.GetTimeout()
+	if timeout == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	var idle []*client.Client
+	for c := range s.clients {
+		if c.HasFlag(client.FlagReplica | client.FlagBlocked | client.FlagPubSub) {
+			continue
+		}
+		if c.Idle() > timeout {
+			idle = append(idle, c)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, c := range idle {
+		fmt.Printf("Closing idle client id=%d addr=%s\n", c.ID(), c.RemoteAddr())
+		c.Close()
+	}
+}
+
+// checkOutputLimits disconnects clients that stayed over their soft output
+// buffer limit for too long, even if their writer is stalled and nothing
+// new is queued for them
+func (s *Server) checkOutputLimits() {
+	s.mu.Lock()
+	clients := make([]*client.Client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	s.mu.Unlock()
+
+	for _, c := range clients {
+		c.CheckOutputLimits()
+	}
+}
+
+// handleConnection processes client connections
+func (s *Server) handleConnection(c *client.Client) {
+	defer s.closeClient(c)
+	reader := s.parser.NewReader(c.Conn())
 
 	for {
 		// Parse incoming command
-		args, err := s.parser.ParseCommand(reader)
+		args, err := reader.ReadCommand()
 		if err != nil {
 			if err == io.EOF {
 				fmt.Println("Client disconnected")
 				return
 			}
+
+			// Tell the client what went wrong before dropping it
+			var protoErr *resp.ProtocolError
+			if errors.As(err, &protoErr) {
+				c.Write(resp.Error{Value: "ERR " + protoErr.Error()})
+			}
+			c.Flush()
 			fmt.Printf("Error parsing command: %v\n", err)
 			return
 		}
 
-		if len(args) == 0 {
-			continue
+		c.Touch()
+		if len(args) > 0 {
+			if !s.beginCommand() {
+				return
+			}
+			s.stats.TotalCommandsProcessed.Add(1)
+			c.Write(s.dispatch(c, args))
+			s.endCommand()
 		}
 
-		// Find command handler
-		handlerName := strings.ToUpper(args[0])
-		handler, found := s.commands.Get(handlerName)
-
-		var response resp.RedisValue
-		if !found {
-			response = resp.Error{Value: fmt.Sprintf("ERR unknown command '%s'", handlerName)}
-		} else {
-			// Execute command with arguments (skip the command name)
-			response = handler.Execute(args[1:])
+		// Replies are buffered and sent in one write once the client has no
+		// further pipelined commands waiting
+		if reader.HasCommand() {
+			continue
 		}
 
-		// Send response
-		_, err = conn.Write(response.Serialize())
+		err = c.Flush()
 		if err != nil {
 			fmt.Printf("Error writing response: %v\n", err)
 			return
 		}
 	}
 }
+
+// addClient admits an accepted connection as a client, unless the server
+// is shutting down or already serving maxclients clients
+func (s *Server) addClient(conn net.Conn) (*client.Client, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, errShuttingDown
+	}
+	if len(s.clients) >= s.config.GetMaxClients() {
+		return nil, errMaxClients
+	}
+	if s.protected() && !isLocal(conn) {
+		return nil, errProtectedMode
+	}
+
+	s.stats.TotalConnectionsReceived.Add(1)
+	c := client.New(conn, s.limits)
+	c.SetAuthenticated(s.acl == nil || !s.acl.Required())
+	s.clients[c] = struct{}{}
+	return c, nil
+}
+
+// closeClient drops a disconnected client's subscriptions and closes its
+// connection
+func (s *Server) closeClient(c *client.Client) {
+	s.mu.Lock()
+	delete(s.clients, c)
+	s.mu.Unlock()
+
+	s.pubsub.RemoveSubscriber(c)
+	c.Close()
+
+	if c.OutputLimitExceeded() {
+		s.stats.ClientOutputBufferLimitDisconnections.Add(1)
+	}
+}
+
+// beginCommand waits until commands may run and counts one as executing.
+// It returns false if the server shut down in the meantime.
+func (s *Server) beginCommand() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for s.draining && !s.closed {
+		s.idle.Wait()
+	}
+	if s.closed {
+		return false
+	}
+
+	s.executing++
+	return true
+}
+
+// endCommand marks a command as finished
+func (s *Server) endCommand() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.executing--
+	s.idle.Broadcast()
+}
+
+func (s *Server) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.closed
+}
+
+// Shutdown stops the server: it waits for replicas to catch up, lets
+// commands in flight finish while holding back new ones, saves the dataset
+// if requested or configured, and closes every client and the listener.
+// requester is the client running SHUTDOWN, or nil for a signal. If the
+// save fails without opts.Force, the server resumes serving clients.
+func (s *Server) Shutdown(requester *client.Client, opts command.ShutdownOptions) error {
+	s.mu.Lock()
+	if opts.Abort {
+		defer s.mu.Unlock()
+		if !s.shuttingDown || s.abort == nil {
+			return errNoShutdown
+		}
+		close(s.abort)
+		s.abort = nil
+		return nil
+	}
+	if s.shuttingDown {
+		s.mu.Unlock()
+		return errShutdownInProgress
+	}
+	s.shuttingDown = true
+	abort := make(chan struct{})
+	s.abort = abort
+	s.mu.Unlock()
+
+	fmt.Println("User requested shutdown...")
+	if !opts.Now && !s.waitForReplicas(abort) {
+		fmt.Println("Shutdown aborted")
+		s.resume()
+		return errShutdownFailed
+	}
+
+	s.drain(requester)
+
+	// Without SAVE or NOSAVE, the dataset is saved if save points are set
+	if !opts.NoSave && (opts.Save || (s.saver != nil && len(s.config.GetSavePoints()) > 0)) {
+		fmt.Println("Saving the final RDB snapshot before exiting.")
+		err := errors.New("no RDB writer available")
+		if s.saver != nil {
+			err = s.saver.SaveOnShutdown()
+		}
+		if err != nil {
+			fmt.Printf("Error trying to save the DB: %v\n", err)
+			if !opts.Force {
+				s.resume()
+				return errShutdownFailed
+			}
+		}
+	}
+
+	s.close()
+	fmt.Println("Redis is now ready to exit, bye bye...")
+	close(s.done)
+	return nil
+}
+
+// waitForReplicas waits until every replica has received its pending
+// output, for at most the configured shutdown timeout. It returns false if
+// the shutdown was aborted.
+func (s *Server) waitForReplicas(abort chan struct{}) bool {
+	deadline := time.NewTimer(s.config.GetShutdownTimeout())
+	defer deadline.Stop()
+	ticker := time.NewTicker(10 * time.Millisecond)
+	defer ticker.Stop()
+
+	for !s.replicasCaughtUp() {
+		select {
+		case <-abort:
+			return false
+		case <-deadline.C:
+			fmt.Println("Lagging replicas were not caught up before the shutdown timeout")
+			return true
+		case <-ticker.C:
+		}
+	}
+
+	s.mu.Lock()
+	s.abort = nil
+	s.mu.Unlock()
+	return true
+}
+
+// replicasCaughtUp reports whether no replica has output pending
+func (s *Server) replicasCaughtUp() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for c := range s.clients {
+		if c.HasFlag(client.FlagReplica) && c.Pending() > 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// drain holds back new commands and waits for those in flight to finish,
+// apart from the requester's own SHUTDOWN
+func (s *Server) drain(requester *client.Client) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.abort = nil
+	s.draining = true
+	own := 0
+	if requester != nil {
+		own = 1
+	}
+	for s.executing > own {
+		s.idle.Wait()
+	}
+}
+
+// resume lets clients run commands again after a failed shutdown
+func (s *Server) resume() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.shuttingDown = false
+	s.draining = false
+	s.abort = nil
+	s.idle.Broadcast()
+}
+
+// close stops accepting connections and closes every client once its
+// pending output has been sent
+func (s *Server) close() {
+	s.mu.Lock()
+	s.closed = true
+	s.idle.Broadcast()
+	clients := make([]*client.Client, 0, len(s.clients))
+	for c := range s.clients {
+		clients = append(clients, c)
+	}
+	listeners := s.listeners
+	s.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, c := range clients {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			c.CloseGracefully(closeTimeout)
+		}()
+	}
+	wg.Wait()
+
+	closeAll(listeners)
+}
+
+// dispatch looks up and runs the command in args, returning its reply
+func (s *Server) dispatch(c *client.Client, args [][]byte) resp.RedisValue {
+	handlerName := strings.ToUpper(string(args[0]))
+	handler, found := s.commands.Get(handlerName)
+	meta, _ := s.commands.Metadata(handlerName)
+
+	// Commands such as AUTH and HELLO are how clients authenticate, so they
+	// are exempt from authentication
+	switch {
+	case !c.Authenticated() && meta.Flags&command.FlagNoAuth == 0:
+		return resp.Error{Value: "NOAUTH Authentication required."}
+	case !found:
+		return resp.Error{Value: fmt.Sprintf("ERR unknown command '%s'", handlerName)}
+	}
+	if reply := meta.CheckArity(args); reply != nil {
+		return reply
+	}
+	if c.Protocol() == resp.RESP2 && !subscribedModeCommands[strings.ToUpper(handler.Name())] && s.pubsub.IsSubscribed(c) {
+		return resp.Error{Value: fmt.Sprintf("ERR Can't execute '%s': only (P|S)SUBSCRIBE / (P|S)UNSUBSCRIBE / PING / QUIT / RESET are allowed in this context", strings.ToLower(handlerName))}
+	}
+
+	// Past this point commands go by their original name, so that renaming
+	// a command doesn't affect ACL rules, stats or replication
+	args[0] = []byte(handler.Name())
+
+	// Permission checks, stats and the slow log are applied by the
+	// registry's middleware chain
+	return s.commands.Run(&command.Context{Client: c, Args: args, Metadata: meta, Handler: handler})
+}
@@ -0,0 +1,694 @@
+package server
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/acl"
+	"github.com/codecrafters-io/redis-starter-go/internal/client"
+	"github.com/codecrafters-io/redis-starter-go/internal/command"
+	"github.com/codecrafters-io/redis-starter-go/internal/config"
+	"github.com/codecrafters-io/redis-starter-go/internal/pubsub"
+	"github.com/codecrafters-io/redis-starter-go/internal/rdb"
+	"github.com/codecrafters-io/redis-starter-go/internal/replication"
+	"github.com/codecrafters-io/redis-starter-go/internal/resp"
+	"github.com/codecrafters-io/redis-starter-go/internal/stats"
+)
+
+// newTestServer creates a server with the default configuration and no
+// commands, without listening
+func newTestServer(t *testing.T) *Server {
+	t.Helper()
+	return NewServer(config.NewConfig(), command.NewRegistry(), resp.NewStreamParser(), pubsub.NewHub(), client.NewOutputLimits(), stats.NewStats())
+}
+
+// serve serves the server end of conn until it is closed
+func serve(t testing.TB, s *Server, conn net.Conn) {
+	t.Helper()
+	c, err := s.addClient(conn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.handleConnection(c)
+}
+
+// fakeSaver counts shutdown saves, failing them with err, and sends the
+// save points it is asked to check on checks, if set
+type fakeSaver struct {
+	err    error
+	saves  int
+	checks chan []rdb.SavePoint
+}
+
+func (s *fakeSaver) SaveOnShutdown() error {
+	s.saves++
+	return s.err
+}
+
+func (s *fakeSaver) CheckSavePoints(points []rdb.SavePoint) {
+	if s.checks != nil {
+		select {
+		case s.checks <- points:
+		default:
+		}
+	}
+}
+
+func TestShutdownSave(t *testing.T) {
+	failure := errors.New("disk full")
+	tests := []struct {
+		name       string
+		savePoints string
+		noSaver    bool
+		saveErr    error
+		opts       command.ShutdownOptions
+		wantSaves  int
+		wantErr    bool
+	}{
+		{"default with save points", "3600 1", false, nil, command.ShutdownOptions{}, 1, false},
+		{"default without save points", "", false, nil, command.ShutdownOptions{}, 0, false},
+		{"NOSAVE", "3600 1", false, nil, command.ShutdownOptions{NoSave: true}, 0, false},
+		{"SAVE without save points", "", false, nil, command.ShutdownOptions{Save: true}, 1, false},
+		{"SAVE without saver", "", true, nil, command.ShutdownOptions{Save: true}, 0, true},
+		{"failed save", "3600 1", false, failure, command.ShutdownOptions{}, 1, true},
+		{"failed save with FORCE", "3600 1", false, failure, command.ShutdownOptions{Force: true}, 1, false},
+		{"failure ignored without save points", "", false, failure, command.ShutdownOptions{}, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			if err := s.config.SetString("save", tt.savePoints); err != nil {
+				t.Fatal(err)
+			}
+			saver := &fakeSaver{err: tt.saveErr}
+			if !tt.noSaver {
+				s.SetSaver(saver)
+			}
+
+			err := s.Shutdown(nil, tt.opts)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Shutdown() = %v, want error %v", err, tt.wantErr)
+			}
+			if saver.saves != tt.wantSaves {
+				t.Errorf("saved %d times, want %d", saver.saves, tt.wantSaves)
+			}
+
+			select {
+			case <-s.done:
+				if tt.wantErr {
+					t.Error("server shut down despite the error")
+				}
+			default:
+				if !tt.wantErr {
+					t.Error("server still running")
+				}
+			}
+		})
+	}
+}
+
+// newTestClient connects a client to s over a pipe and returns it with the
+// peer's end of the pipe
+func newTestClient(t *testing.T, s *Server) (*client.Client, net.Conn) {
+	t.Helper()
+	local, remote := net.Pipe()
+	t.Cleanup(func() { local.Close() })
+	c, err := s.addClient(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, local
+}
+
+// isDone reports whether s has shut down
+func isDone(s *Server) bool {
+	select {
+	case <-s.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestShutdownAbort(t *testing.T) {
+	s := newTestServer(t)
+	setConfig(t, s, map[string]string{"protected-mode": "no", "save": "", "shutdown-timeout": "10"})
+
+	if err := s.Shutdown(nil, command.ShutdownOptions{Abort: true}); !errors.Is(err, errNoShutdown) {
+		t.Fatalf("ABORT without a shutdown = %v, want errNoShutdown", err)
+	}
+
+	// A replica that reads nothing keeps the shutdown waiting
+	replica, _ := newTestClient(t, s)
+	replica.SetFlag(client.FlagReplica, true)
+	replica.Push(resp.SimpleString{Value: "PING"})
+
+	errs := make(chan error, 1)
+	go func() { errs <- s.Shutdown(nil, command.ShutdownOptions{}) }()
+	waitFor(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.abort != nil
+	})
+	if err := s.Shutdown(nil, command.ShutdownOptions{}); !errors.Is(err, errShutdownInProgress) {
+		t.Errorf("second Shutdown() = %v, want errShutdownInProgress", err)
+	}
+	if err := s.Shutdown(nil, command.ShutdownOptions{Abort: true}); err != nil {
+		t.Fatalf("ABORT = %v", err)
+	}
+	if err := <-errs; !errors.Is(err, errShutdownFailed) {
+		t.Errorf("aborted Shutdown() = %v, want errShutdownFailed", err)
+	}
+	if isDone(s) {
+		t.Fatal("server shut down despite ABORT")
+	}
+
+	// NOW doesn't wait for replicas, and closing the stuck replica only
+	// takes up to closeTimeout
+	if err := s.Shutdown(nil, command.ShutdownOptions{Now: true}); err != nil {
+		t.Fatalf("Shutdown(NOW) = %v", err)
+	}
+	if !isDone(s) {
+		t.Error("server still running")
+	}
+}
+
+// Commands in flight finish before the server shuts down, new ones don't
+// start, and clients get their pending output before being disconnected
+func TestShutdownDrainsClients(t *testing.T) {
+	s := newTestServer(t)
+	setConfig(t, s, map[string]string{"protected-mode": "no", "save": ""})
+	c, peer := newTestClient(t, s)
+
+	if !s.beginCommand() {
+		t.Fatal("beginCommand() = false before the shutdown")
+	}
+	errs := make(chan error, 1)
+	go func() { errs <- s.Shutdown(nil, command.ShutdownOptions{Now: true}) }()
+
+	time.Sleep(20 * time.Millisecond)
+	if isDone(s) {
+		t.Fatal("server shut down with a command in flight")
+	}
+	c.Write(resp.SimpleString{Value: "OK"})
+	s.endCommand()
+
+	reply := make([]byte, len("+OK\r\n"))
+	if _, err := io.ReadFull(peer, reply); err != nil || string(reply) != "+OK\r\n" {
+		t.Fatalf("reply %q, %v, want +OK", reply, err)
+	}
+	if err := <-errs; err != nil {
+		t.Fatalf("Shutdown() = %v", err)
+	}
+	if _, err := peer.Read(reply); err != io.EOF {
+		t.Errorf("read after the shutdown = %v, want EOF", err)
+	}
+	if s.beginCommand() {
+		t.Error("beginCommand() = true after the shutdown")
+	}
+}
+
+// Clients silent for longer than timeout are disconnected, unless they are
+// expected to be quiet
+func TestCloseIdleClients(t *testing.T) {
+	s := newTestServer(t)
+	setConfig(t, s, map[string]string{"protected-mode": "no", "timeout": "1"})
+
+	tests := []struct {
+		name       string
+		flags      client.Flag
+		active     bool
+		wantClosed bool
+	}{
+		{"idle", 0, false, true},
+		{"active", 0, true, false},
+		{"replica", client.FlagReplica, false, false},
+		{"blocked", client.FlagBlocked, false, false},
+		{"subscriber", client.FlagPubSub, false, false},
+	}
+	clients := make([]*client.Client, len(tests))
+	for i, tt := range tests {
+		clients[i], _ = newTestClient(t, s)
+		clients[i].SetFlag(tt.flags, true)
+	}
+
+	time.Sleep(time.Second + 50*time.Millisecond)
+	for i, tt := range tests {
+		if tt.active {
+			clients[i].Touch()
+		}
+	}
+
+	// A zero timeout disables the check
+	setConfig(t, s, map[string]string{"timeout": "0"})
+	s.closeIdleClients()
+	for i, tt := range tests {
+		if err := clients[i].Write(resp.SimpleString{Value: "OK"}); err != nil {
+			t.Errorf("%s client closed without a timeout", tt.name)
+		}
+	}
+
+	setConfig(t, s, map[string]string{"timeout": "1"})
+	s.closeIdleClients()
+	for i, tt := range tests {
+		closed := errors.Is(clients[i].Write(resp.SimpleString{Value: "OK"}), client.ErrClosed)
+		if closed != tt.wantClosed {
+			t.Errorf("%s client closed = %v, want %v", tt.name, closed, tt.wantClosed)
+		}
+	}
+}
+
+// The cron checks the configured save points, as they are when it runs
+func TestCronChecksSavePoints(t *testing.T) {
+	s := newTestServer(t)
+	saver := &fakeSaver{checks: make(chan []rdb.SavePoint)}
+	s.SetSaver(saver)
+	go s.cron()
+	defer close(s.done)
+
+	tests := []struct {
+		save string
+		want string
+	}{
+		{"60 5", "[{1m0s 5}]"},
+		{"3600 1 300 100", "[{1h0m0s 1} {5m0s 100}]"},
+		{"", "[]"},
+	}
+	for _, tt := range tests {
+		setConfig(t, s, map[string]string{"save": tt.save})
+
+		// A check may have read the save points before they changed, so
+		// wait for one using the new ones
+		deadline := time.After(time.Second)
+		for got := ""; got != tt.want; {
+			select {
+			case points := <-saver.checks:
+				got = fmt.Sprint(points)
+			case <-deadline:
+				t.Fatalf("save %q: save points not checked", tt.save)
+			}
+		}
+	}
+}
+
+// dial connects to listener, closing the connection when the test ends
+func dial(t *testing.T, listener net.Listener) net.Conn {
+	t.Helper()
+	conn, err := net.Dial("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	conn.SetDeadline(time.Now().Add(time.Second))
+	return conn
+}
+
+// expect reads a reply from conn, failing the test unless it is want
+func expect(t *testing.T, conn net.Conn, want string) {
+	t.Helper()
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(conn, got); err != nil {
+		t.Fatalf("reading %q: %v", want, err)
+	}
+	if string(got) != want {
+		t.Fatalf("reply %q, want %q", got, want)
+	}
+}
+
+func TestMaxClients(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.commands.Register(&command.PingCommand{}); err != nil {
+		t.Fatal(err)
+	}
+	setConfig(t, s, map[string]string{"maxclients": "2"})
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	go s.serve(listener)
+
+	first := dial(t, listener)
+	first.Write([]byte("PING\r\n"))
+	expect(t, first, "+PONG\r\n")
+	second := dial(t, listener)
+	second.Write([]byte("PING\r\n"))
+	expect(t, second, "+PONG\r\n")
+
+	// The third client is turned away before any command is read
+	third := dial(t, listener)
+	expect(t, third, "-ERR max number of clients reached\r\n")
+	if _, err := third.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("read after the rejection = %v, want EOF", err)
+	}
+	if got := s.stats.RejectedConnections.Load(); got != 1 {
+		t.Errorf("rejected_connections = %d, want 1", got)
+	}
+
+	// Disconnecting frees a slot
+	first.Close()
+	waitFor(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.clients) < 2
+	})
+	fourth := dial(t, listener)
+	fourth.Write([]byte("PING\r\n"))
+	expect(t, fourth, "+PONG\r\n")
+
+	// Lowering the limit keeps connected clients but admits no new ones
+	setConfig(t, s, map[string]string{"maxclients": "1"})
+	expect(t, dial(t, listener), "-ERR max number of clients reached\r\n")
+	second.Write([]byte("PING\r\n"))
+	expect(t, second, "+PONG\r\n")
+	if got := s.stats.RejectedConnections.Load(); got != 2 {
+		t.Errorf("rejected_connections = %d, want 2", got)
+	}
+}
+
+// newTestACL returns an ACL validating command names against the commands
+// of s
+func newTestACL(s *Server) *acl.ACL {
+	return acl.New(func(name string) bool {
+		_, ok := s.commands.Original(name)
+		return ok
+	})
+}
+
+// Until they authenticate, clients may only run the commands used to
+// authenticate
+func TestAuthRequired(t *testing.T) {
+	s := newTestServer(t)
+	acls := newTestACL(s)
+	acls.SetDefaultPassword("secret")
+	s.SetACL(acls)
+	for _, handler := range []command.Handler{&command.PingCommand{}, command.NewAuthCommand(acls), command.NewHelloCommand(replication.NewConfig(), acls)} {
+		if err := s.commands.Register(handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setConfig(t, s, map[string]string{"protected-mode": "no"})
+	c, _ := newTestClient(t, s)
+
+	steps := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"PING"}, "-NOAUTH Authentication required.\r\n"},
+		{[]string{"NOSUCHCOMMAND"}, "-NOAUTH Authentication required.\r\n"},
+		{[]string{"HELLO", "3"}, "-NOAUTH HELLO must be called with the client already authenticated, otherwise the HELLO <proto> AUTH <user> <pass> option can be used to authenticate the client and select the RESP protocol version at the same time\r\n"},
+		{[]string{"AUTH", "wrong"}, "-WRONGPASS invalid username-password pair or user is disabled.\r\n"},
+		{[]string{"PING"}, "-NOAUTH Authentication required.\r\n"},
+		{[]string{"auth", "secret"}, "+OK\r\n"},
+		{[]string{"PING"}, "+PONG\r\n"},
+		{[]string{"NOSUCHCOMMAND"}, "-ERR unknown command 'NOSUCHCOMMAND'\r\n"},
+	}
+
+	for _, step := range steps {
+		args := make([][]byte, len(step.args))
+		for i, arg := range step.args {
+			args[i] = []byte(arg)
+		}
+		if got := string(s.dispatch(c, args).Serialize(resp.RESP2)); got != step.want {
+			t.Errorf("%v = %q, want %q", step.args, got, step.want)
+		}
+	}
+
+	// Clients connecting without a password being required are
+	// authenticated right away
+	acls.SetDefaultPassword("")
+	if c, _ := newTestClient(t, s); !c.Authenticated() {
+		t.Error("client not authenticated without requirepass")
+	}
+}
+
+// Commands called with the wrong number of arguments are refused before
+// they run
+func TestArity(t *testing.T) {
+	s := newTestServer(t)
+	for _, handler := range []command.Handler{&command.EchoCommand{}, command.NewConfigCommand(s.config)} {
+		if err := s.commands.Register(handler); err != nil {
+			t.Fatal(err)
+		}
+	}
+	setConfig(t, s, map[string]string{"protected-mode": "no"})
+	c, _ := newTestClient(t, s)
+
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"ECHO", "hi"}, "$2\r\nhi\r\n"},
+		{[]string{"ECHO"}, "-ERR wrong number of arguments for 'echo' command\r\n"},
+		{[]string{"echo", "a", "b"}, "-ERR wrong number of arguments for 'echo' command\r\n"},
+		{[]string{"CONFIG"}, "-ERR wrong number of arguments for 'config' command\r\n"},
+		{[]string{"CONFIG", "SET", "timeout"}, "-ERR wrong number of arguments for 'config|set' command\r\n"},
+	}
+
+	for _, tt := range tests {
+		args := make([][]byte, len(tt.args))
+		for i, arg := range tt.args {
+			args[i] = []byte(arg)
+		}
+		if got := string(s.dispatch(c, args).Serialize(resp.RESP2)); got != tt.want {
+			t.Errorf("%v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+}
+
+// waitFor polls cond until it holds, failing the test after a second
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// Malformed input gets an error reply before the connection is closed
+func TestProtocolErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		want   string
+		closed bool
+	}{
+		{"inline", "PING\r\nPING hello\r\n", "+PONG\r\n$5\r\nhello\r\n", false},
+		{"bad multibulk length", "PING\r\n*x\r\nPING\r\n", "+PONG\r\n-ERR Protocol error: invalid multibulk length\r\n", true},
+		{"missing bulk header", "*1\r\nPING\r\n", "-ERR Protocol error: expected '$', got 'P'\r\n", true},
+		{"unbalanced quotes", "ECHO \"hello\r\n", "-ERR Protocol error: unbalanced quotes in request\r\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			if err := s.commands.Register(&command.PingCommand{}); err != nil {
+				t.Fatal(err)
+			}
+			setConfig(t, s, map[string]string{"protected-mode": "no"})
+
+			local, remote := net.Pipe()
+			defer local.Close()
+			serve(t, s, remote)
+
+			go local.Write([]byte(tt.input))
+			local.SetReadDeadline(time.Now().Add(time.Second))
+			got := make([]byte, len(tt.want))
+			if _, err := io.ReadFull(local, got); err != nil {
+				t.Fatalf("reading replies: %v", err)
+			}
+			if string(got) != tt.want {
+				t.Fatalf("replies %q, want %q", got, tt.want)
+			}
+			if !tt.closed {
+				return
+			}
+
+			// The server hangs up after replying with a protocol error
+			if _, err := local.Read(make([]byte, 1)); err != io.EOF {
+				t.Errorf("read after the error = %v, want EOF", err)
+			}
+		})
+	}
+}
+
+// Clients dropped for their output buffer limits are counted in INFO
+func TestOutputLimitDisconnections(t *testing.T) {
+	s := newTestServer(t)
+	limits := client.DefaultOutputLimits
+	limits[client.ClassPubSub] = client.OutputLimit{Hard: 1}
+	s.limits.Set(limits)
+	setConfig(t, s, map[string]string{"protected-mode": "no"})
+
+	tests := []struct {
+		flags client.Flag
+		want  int64
+	}{
+		{0, 0},
+		{client.FlagPubSub, 1},
+	}
+
+	for _, tt := range tests {
+		local, remote := net.Pipe()
+		defer local.Close()
+		c, err := s.addClient(remote)
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.SetFlag(tt.flags, true)
+		c.Push(resp.SimpleString{Value: "OK"})
+		s.closeClient(c)
+
+		if got := s.stats.ClientOutputBufferLimitDisconnections.Load(); got != tt.want {
+			t.Errorf("client_output_buffer_limit_disconnections = %d with flags %b, want %d", got, tt.flags, tt.want)
+		}
+	}
+}
+
+// The cron disconnects subscribers that stay over their soft limit while
+// their peer reads nothing and no new messages arrive
+func TestCronChecksOutputLimits(t *testing.T) {
+	s := newTestServer(t)
+	limits := client.DefaultOutputLimits
+	limits[client.ClassPubSub] = client.OutputLimit{Soft: 10, SoftSeconds: 10 * time.Millisecond}
+	s.limits.Set(limits)
+	setConfig(t, s, map[string]string{"protected-mode": "no"})
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	c, err := s.addClient(remote)
+	if err != nil {
+		t.Fatal(err)
+	}
+	go s.handleConnection(c)
+	c.SetFlag(client.FlagPubSub, true)
+	if err := c.Push(resp.SimpleString{Value: "a message over the soft limit"}); err != nil {
+		t.Fatal(err)
+	}
+
+	go s.cron()
+	defer close(s.done)
+	waitFor(t, func() bool { return s.stats.ClientOutputBufferLimitDisconnections.Load() == 1 })
+}
+
+// pingPipeline is n pipelined PING commands
+func pingPipeline(n int) []byte {
+	return bytes.Repeat([]byte("*1\r\n$4\r\nPING\r\n"), n)
+}
+
+// countingConn counts the writes made to a connection
+type countingConn struct {
+	net.Conn
+	writes atomic.Int64
+}
+
+func (c *countingConn) Write(p []byte) (int, error) {
+	c.writes.Add(1)
+	return c.Conn.Write(p)
+}
+
+func TestPipelineBatchesReplies(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.commands.Register(&command.PingCommand{}); err != nil {
+		t.Fatal(err)
+	}
+	// Pipes have no address, so they don't count as loopback connections
+	if err := s.config.SetString("protected-mode", "no"); err != nil {
+		t.Fatal(err)
+	}
+
+	local, remote := net.Pipe()
+	defer local.Close()
+	conn := &countingConn{Conn: remote}
+	serve(t, s, conn)
+
+	if _, err := local.Write(pingPipeline(100)); err != nil {
+		t.Fatal(err)
+	}
+	want := bytes.Repeat([]byte("+PONG\r\n"), 100)
+	got := make([]byte, len(want))
+	if _, err := io.ReadFull(local, got); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, want) {
+		t.Fatalf("replies %q, want %q", got, want)
+	}
+	if n := conn.writes.Load(); n != 1 {
+		t.Errorf("replies sent in %d writes, want 1", n)
+	}
+}
+
+// unbatchedParser reads commands with parser but never reports one as
+// buffered, so the server flushes after every reply as it used to
+type unbatchedParser struct {
+	resp.Parser
+}
+
+type unbatchedReader struct {
+	resp.CommandReader
+}
+
+func (p unbatchedParser) NewReader(r io.Reader) resp.CommandReader {
+	return unbatchedReader{p.Parser.NewReader(r)}
+}
+
+func (unbatchedReader) HasCommand() bool { return false }
+
+// BenchmarkPipeline measures the throughput of pipelines of 100 commands
+// over loopback TCP, with replies batched and flushed after every reply
+func BenchmarkPipeline(b *testing.B) {
+	for _, bench := range []struct {
+		name   string
+		parser resp.Parser
+	}{
+		{"batched", resp.NewStreamParser()},
+		{"unbatched", unbatchedParser{resp.NewStreamParser()}},
+	} {
+		b.Run(bench.name, func(b *testing.B) {
+			s := NewServer(config.NewConfig(), command.NewRegistry(), bench.parser, pubsub.NewHub(), client.NewOutputLimits(), stats.NewStats())
+			if err := s.commands.Register(&command.PingCommand{}); err != nil {
+				b.Fatal(err)
+			}
+
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				b.Skip(err)
+			}
+			defer listener.Close()
+			conn, err := net.Dial("tcp", listener.Addr().String())
+			if err != nil {
+				b.Fatal(err)
+			}
+			defer conn.Close()
+			accepted, err := listener.Accept()
+			if err != nil {
+				b.Fatal(err)
+			}
+			serve(b, s, accepted)
+
+			pipeline := pingPipeline(100)
+			replies := make([]byte, 100*len("+PONG\r\n"))
+			b.ResetTimer()
+			for range b.N {
+				if _, err := conn.Write(pipeline); err != nil {
+					b.Fatal(err)
+				}
+				if _, err := io.ReadFull(conn, replies); err != nil {
+					b.Fatal(err)
+				}
+			}
+			b.ReportMetric(float64(100*b.N)/b.Elapsed().Seconds(), "commands/s")
+		})
+	}
+}
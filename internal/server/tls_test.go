@@ -0,0 +1,285 @@
+package server
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// testCA is a certificate authority issuing certificates for tests
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+	pem  []byte
+}
+
+// newTestCA creates a self-signed certificate authority
+func newTestCA(t *testing.T, name string) *testCA {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: name},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testCA{cert: cert, key: key, pem: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})}
+}
+
+// issue signs a leaf certificate for name, valid for serving 127.0.0.1 and
+// authenticating clients, and returns it with its key in PEM
+func (ca *testCA) issue(t *testing.T, name string) (certPEM, keyPEM []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.IPv4(127, 0, 0, 1)},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+// clientCert issues a client certificate usable in a tls.Config
+func (ca *testCA) clientCert(t *testing.T, name string) tls.Certificate {
+	t.Helper()
+	certPEM, keyPEM := ca.issue(t, name)
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return cert
+}
+
+// writeFile writes data to a file named name in dir and returns its path
+func writeFile(t *testing.T, dir, name string, data []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// setConfig applies configuration parameters, failing the test on error
+func setConfig(t *testing.T, s *Server, params map[string]string) {
+	t.Helper()
+	for name, value := range params {
+		if err := s.config.SetString(name, value); err != nil {
+			t.Fatalf("set %s: %v", name, err)
+		}
+	}
+}
+
+// newTLSServer returns a server configured with a certificate for the
+// server issued by ca, trusting ca for client certificates, and a TLS
+// listener for it on a loopback port
+func newTLSServer(t *testing.T, ca *testCA, authClients string) (*Server, net.Listener) {
+	t.Helper()
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, "server")
+
+	s := newTestServer(t)
+	setConfig(t, s, map[string]string{
+		"tls-cert-file":    writeFile(t, dir, "server.crt", certPEM),
+		"tls-key-file":     writeFile(t, dir, "server.key", keyPEM),
+		"tls-ca-cert-file": writeFile(t, dir, "ca.crt", ca.pem),
+		"tls-auth-clients": authClients,
+	})
+
+	listeners, err := s.listenTLS([]string{"127.0.0.1"}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { closeAll(listeners) })
+	return s, listeners[0]
+}
+
+// handshake connects to listener, presenting certs and trusting roots, and
+// returns the server's certificate and the result of the handshake as seen
+// by the server, which is where rejected client certificates show up
+func handshake(t *testing.T, listener net.Listener, roots *x509.CertPool, certs ...tls.Certificate) (*x509.Certificate, error) {
+	t.Helper()
+	serverErr := make(chan error, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+			return
+		}
+		defer conn.Close()
+		serverErr <- conn.(*tls.Conn).Handshake()
+	}()
+
+	// Present the certificate even if the server doesn't list its issuer as
+	// acceptable, which Go clients would otherwise skip
+	clientConfig := &tls.Config{RootCAs: roots}
+	if len(certs) > 0 {
+		clientConfig.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			return &certs[0], nil
+		}
+	}
+	// A failed handshake is reported by the server; the client may only
+	// find out on its first read
+	conn, _ := tls.Dial("tcp", listener.Addr().String(), clientConfig)
+	err := <-serverErr
+	if conn == nil {
+		return nil, err
+	}
+	defer conn.Close()
+	return conn.ConnectionState().PeerCertificates[0], err
+}
+
+func TestTLSClientAuth(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	trusted := ca.clientCert(t, "trusted client")
+	untrusted := newTestCA(t, "other CA").clientCert(t, "untrusted client")
+
+	tests := []struct {
+		authClients string
+		certs       []tls.Certificate
+		wantErr     bool
+	}{
+		{"yes", []tls.Certificate{trusted}, false},
+		{"yes", nil, true},
+		{"yes", []tls.Certificate{untrusted}, true},
+		{"optional", []tls.Certificate{trusted}, false},
+		{"optional", nil, false},
+		{"optional", []tls.Certificate{untrusted}, true},
+		{"no", []tls.Certificate{trusted}, false},
+		{"no", nil, false},
+		{"no", []tls.Certificate{untrusted}, false},
+	}
+
+	for _, tt := range tests {
+		name := tt.authClients + "/no certificate"
+		if len(tt.certs) > 0 {
+			name = tt.authClients + "/" + tt.certs[0].Leaf.Subject.CommonName
+		}
+		t.Run(name, func(t *testing.T) {
+			_, listener := newTLSServer(t, ca, tt.authClients)
+			_, err := handshake(t, listener, roots, tt.certs...)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("handshake error = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestTLSReload(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	roots := x509.NewCertPool()
+	roots.AddCert(ca.cert)
+	s, listener := newTLSServer(t, ca, "no")
+
+	cert, err := handshake(t, listener, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cert.Subject.CommonName != "server" {
+		t.Fatalf("server certificate %q, want server", cert.Subject.CommonName)
+	}
+
+	// New connections get the reloaded certificate without a restart
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, "reloaded")
+	setConfig(t, s, map[string]string{
+		"tls-cert-file": writeFile(t, dir, "reloaded.crt", certPEM),
+		"tls-key-file":  writeFile(t, dir, "reloaded.key", keyPEM),
+	})
+	if err := s.ReloadTLS(); err != nil {
+		t.Fatalf("ReloadTLS() = %v", err)
+	}
+	if cert, err = handshake(t, listener, roots); err != nil {
+		t.Fatal(err)
+	}
+	if cert.Subject.CommonName != "reloaded" {
+		t.Errorf("server certificate %q after reload, want reloaded", cert.Subject.CommonName)
+	}
+
+	// A failed reload keeps the previous certificate
+	setConfig(t, s, map[string]string{"tls-cert-file": filepath.Join(dir, "missing.crt")})
+	if err := s.ReloadTLS(); err == nil {
+		t.Fatal("ReloadTLS() succeeded with a missing certificate")
+	}
+	if cert, err = handshake(t, listener, roots); err != nil {
+		t.Fatal(err)
+	}
+	if cert.Subject.CommonName != "reloaded" {
+		t.Errorf("server certificate %q after a failed reload, want reloaded", cert.Subject.CommonName)
+	}
+}
+
+func TestTLSConfigErrors(t *testing.T) {
+	ca := newTestCA(t, "test CA")
+	dir := t.TempDir()
+	certPEM, keyPEM := ca.issue(t, "server")
+	certFile := writeFile(t, dir, "server.crt", certPEM)
+	keyFile := writeFile(t, dir, "server.key", keyPEM)
+	caFile := writeFile(t, dir, "ca.crt", ca.pem)
+
+	tests := []struct {
+		name    string
+		params  map[string]string
+		wantErr bool
+	}{
+		{"no certificate", map[string]string{"tls-auth-clients": "no"}, true},
+		{"missing key", map[string]string{"tls-cert-file": certFile, "tls-key-file": filepath.Join(dir, "missing"), "tls-auth-clients": "no"}, true},
+		{"mismatched key", map[string]string{"tls-cert-file": certFile, "tls-key-file": certFile, "tls-auth-clients": "no"}, true},
+		{"no client auth", map[string]string{"tls-cert-file": certFile, "tls-key-file": keyFile, "tls-auth-clients": "no"}, false},
+		{"client auth without CA", map[string]string{"tls-cert-file": certFile, "tls-key-file": keyFile, "tls-auth-clients": "yes"}, true},
+		{"CA without certificates", map[string]string{"tls-cert-file": certFile, "tls-key-file": keyFile, "tls-ca-cert-file": keyFile}, true},
+		{"client auth", map[string]string{"tls-cert-file": certFile, "tls-key-file": keyFile, "tls-ca-cert-file": caFile}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestServer(t)
+			setConfig(t, s, tt.params)
+			if err := s.ReloadTLS(); (err != nil) != tt.wantErr {
+				t.Errorf("ReloadTLS() = %v, want error %v", err, tt.wantErr)
+			}
+		})
+	}
+}
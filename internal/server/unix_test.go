@@ -0,0 +1,126 @@
+package server
+
+import (
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/command"
+)
+
+func TestListenUnix(t *testing.T) {
+	tests := []struct {
+		name     string
+		stale    bool
+		perm     os.FileMode
+		wantPerm os.FileMode
+	}{
+		{"new socket", false, 0o700, 0o700},
+		{"stale socket file", true, 0o770, 0o770},
+		{"default permissions", false, 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "redis.sock")
+			if tt.stale {
+				writeFile(t, filepath.Dir(path), "redis.sock", []byte("left behind"))
+			}
+
+			listener, err := listenUnix(path, tt.perm)
+			if err != nil {
+				t.Fatalf("listenUnix() = %v", err)
+			}
+			defer listener.Close()
+
+			info, err := os.Stat(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if info.Mode()&os.ModeSocket == 0 {
+				t.Errorf("%s is not a socket", path)
+			}
+			if tt.wantPerm != 0 && info.Mode().Perm() != tt.wantPerm {
+				t.Errorf("permissions %o, want %o", info.Mode().Perm(), tt.wantPerm)
+			}
+		})
+	}
+
+	if _, err := listenUnix(filepath.Join(t.TempDir(), "missing", "redis.sock"), 0); err == nil {
+		t.Error("listenUnix() succeeded in a missing directory")
+	}
+}
+
+// freePort returns a TCP port that was free on the loopback interface
+func freePort(t *testing.T) int {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+	return listener.Addr().(*net.TCPAddr).Port
+}
+
+// Clients of the TCP and Unix socket listeners are served by the same
+// server
+func TestStartTCPAndUnix(t *testing.T) {
+	s := newTestServer(t)
+	if err := s.commands.Register(&command.PingCommand{}); err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "redis.sock")
+	port := freePort(t)
+	s.config.Bind = []string{"127.0.0.1"}
+	s.config.Port = port
+	s.config.UnixSocket = path
+	s.config.UnixSocketPerm = 0o700
+
+	errs := make(chan error, 1)
+	go func() { errs <- s.Start() }()
+
+	var tcpConn, unixConn net.Conn
+	waitFor(t, func() bool {
+		var err error
+		tcpConn, err = net.Dial("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)))
+		return err == nil
+	})
+	defer tcpConn.Close()
+	unixConn, err := net.Dial("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unixConn.Close()
+
+	for _, conn := range []net.Conn{tcpConn, unixConn} {
+		conn.SetDeadline(time.Now().Add(time.Second))
+		conn.Write([]byte("PING\r\n"))
+		expect(t, conn, "+PONG\r\n")
+	}
+
+	s.mu.Lock()
+	addrs := make(map[string]bool)
+	for c := range s.clients {
+		addrs[c.RemoteAddr()] = true
+	}
+	s.mu.Unlock()
+	if len(addrs) != 2 || !addrs[path+":0"] {
+		t.Errorf("client addresses %v, want a TCP client and %s:0", addrs, path)
+	}
+
+	if err := s.Shutdown(nil, command.ShutdownOptions{NoSave: true, Now: true}); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-errs; err != nil {
+		t.Errorf("Start() = %v after a shutdown", err)
+	}
+	for _, conn := range []net.Conn{tcpConn, unixConn} {
+		if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+			t.Errorf("read after the shutdown = %v, want EOF", err)
+		}
+	}
+}
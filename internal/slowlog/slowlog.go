@@ -0,0 +1,113 @@
+package slowlog
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limits on what an entry keeps of a command, as in Redis
+const (
+	maxArgs   = 32
+	maxArgLen = 128
+)
+
+// Entry is a SLOWLOG entry: a command that took longer than the configured
+// threshold to execute
+type Entry struct {
+	ID         int64
+	Time       time.Time
+	Duration   time.Duration
+	Args       []string
+	ClientAddr string
+	ClientName string
+}
+
+// Log records slow commands, newest first
+type Log struct {
+	mu      sync.Mutex
+	entries []Entry
+	maxLen  int
+	nextID  int64
+}
+
+// NewLog creates a log keeping at most maxLen entries
+func NewLog(maxLen int) *Log {
+	return &Log{maxLen: maxLen}
+}
+
+// SetMaxLen changes how many entries are kept, dropping the oldest ones
+func (l *Log) SetMaxLen(maxLen int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.maxLen = maxLen
+	l.trim()
+}
+
+// Add records a command that took d to execute. The arguments are copied,
+// keeping at most 32 of them and 128 bytes of each.
+func (l *Log) Add(args [][]byte, d time.Duration, clientAddr, clientName string) {
+	kept := min(len(args), maxArgs)
+	e := Entry{
+		Time:       time.Now(),
+		Duration:   d,
+		Args:       make([]string, kept),
+		ClientAddr: clientAddr,
+		ClientName: clientName,
+	}
+	for i := range kept {
+		if i == maxArgs-1 && len(args) > maxArgs {
+			e.Args[i] = fmt.Sprintf("... (%d more arguments)", len(args)-maxArgs+1)
+			break
+		}
+		if len(args[i]) > maxArgLen {
+			e.Args[i] = fmt.Sprintf("%s... (%d more bytes)", args[i][:maxArgLen], len(args[i])-maxArgLen)
+			continue
+		}
+		e.Args[i] = string(args[i])
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e.ID = l.nextID
+	l.nextID++
+	l.entries = append([]Entry{e}, l.entries...)
+	l.trim()
+}
+
+// Entries returns up to count of the newest entries, or all of them if
+// count is negative
+func (l *Log) Entries(count int) []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if count < 0 || count > len(l.entries) {
+		count = len(l.entries)
+	}
+	return append([]Entry(nil), l.entries[:count]...)
+}
+
+// Len returns the number of entries
+func (l *Log) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.entries)
+}
+
+// Reset removes every entry
+func (l *Log) Reset() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.entries = nil
+}
+
+// trim drops entries beyond maxLen. Callers must hold l.mu.
+func (l *Log) trim() {
+	if len(l.entries) > l.maxLen {
+		l.entries = l.entries[:l.maxLen]
+	}
+}
@@ -0,0 +1,104 @@
+package slowlog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// args returns n arguments of the given length
+func args(n, length int) [][]byte {
+	a := make([][]byte, n)
+	for i := range a {
+		a[i] = []byte(strings.Repeat("x", length))
+	}
+	return a
+}
+
+// Entries keep at most 32 arguments and 128 bytes of each, noting what was
+// left out
+func TestAddTruncates(t *testing.T) {
+	long := strings.Repeat("x", maxArgLen)
+	tests := []struct {
+		name     string
+		args     [][]byte
+		wantLen  int
+		wantLast string
+	}{
+		{"short", args(3, 10), 3, strings.Repeat("x", 10)},
+		{"max arguments", args(maxArgs, 1), maxArgs, "x"},
+		{"too many arguments", args(maxArgs+5, 1), maxArgs, "... (6 more arguments)"},
+		{"max length", args(1, maxArgLen), 1, long},
+		{"too long", args(1, maxArgLen+10), 1, long + "... (10 more bytes)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := NewLog(10)
+			l.Add(tt.args, time.Millisecond, "127.0.0.1:6000", "worker")
+
+			e := l.Entries(-1)[0]
+			if len(e.Args) != tt.wantLen {
+				t.Fatalf("args = %d, want %d", len(e.Args), tt.wantLen)
+			}
+			if last := e.Args[len(e.Args)-1]; last != tt.wantLast {
+				t.Errorf("last arg = %q, want %q", last, tt.wantLast)
+			}
+		})
+	}
+}
+
+func TestEntries(t *testing.T) {
+	l := NewLog(3)
+	for i := range 5 {
+		l.Add([][]byte{[]byte("PING")}, time.Duration(i), "", "")
+	}
+
+	tests := []struct {
+		name    string
+		count   int
+		wantIDs []int64
+	}{
+		{"all", -1, []int64{4, 3, 2}},
+		{"newest", 2, []int64{4, 3}},
+		{"more than kept", 10, []int64{4, 3, 2}},
+		{"none", 0, []int64{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries := l.Entries(tt.count)
+			if len(entries) != len(tt.wantIDs) {
+				t.Fatalf("entries = %d, want %d", len(entries), len(tt.wantIDs))
+			}
+			for i, e := range entries {
+				if e.ID != tt.wantIDs[i] {
+					t.Errorf("entry %d ID = %d, want %d", i, e.ID, tt.wantIDs[i])
+				}
+			}
+		})
+	}
+}
+
+// Shrinking the log drops the oldest entries, and IDs keep increasing
+// after a reset
+func TestSetMaxLenAndReset(t *testing.T) {
+	l := NewLog(10)
+	for range 5 {
+		l.Add([][]byte{[]byte("PING")}, 0, "", "")
+	}
+
+	l.SetMaxLen(2)
+	if entries := l.Entries(-1); len(entries) != 2 || entries[1].ID != 3 {
+		t.Fatalf("entries after SetMaxLen = %+v", entries)
+	}
+
+	l.Reset()
+	if l.Len() != 0 {
+		t.Fatalf("Len() after Reset = %d", l.Len())
+	}
+	l.Add([][]byte{[]byte("PING")}, 0, "", "")
+	if id := l.Entries(1)[0].ID; id != 5 {
+		t.Errorf("ID after Reset = %d, want 5", id)
+	}
+}
@@ -0,0 +1,105 @@
+package stats
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Stats holds the server-wide counters reported in the stats section of INFO
+type Stats struct {
+	TotalConnectionsReceived              atomic.Int64
+	TotalCommandsProcessed                atomic.Int64
+	RejectedConnections                   atomic.Int64
+	ClientOutputBufferLimitDisconnections atomic.Int64
+}
+
+// NewStats creates zeroed stats
+func NewStats() *Stats {
+	return &Stats{}
+}
+
+// Info returns the stats section of INFO
+func (s *Stats) Info() string {
+	var b strings.Builder
+	b.WriteString("# Stats\n")
+	fmt.Fprintf(&b, "total_connections_received:%d\n", s.TotalConnectionsReceived.Load())
+	fmt.Fprintf(&b, "total_commands_processed:%d\n", s.TotalCommandsProcessed.Load())
+	fmt.Fprintf(&b, "rejected_connections:%d\n", s.RejectedConnections.Load())
+	fmt.Fprintf(&b, "client_output_buffer_limit_disconnections:%d\n", s.ClientOutputBufferLimitDisconnections.Load())
+
+	return b.String()
+}
+
+// CommandStats holds the per-command counters reported in the
+// commandstats section of INFO
+type CommandStats struct {
+	mu       sync.Mutex
+	commands map[string]*commandStat
+}
+
+// commandStat counts the calls of one command
+type commandStat struct {
+	calls    int64
+	duration time.Duration
+	rejected int64
+	failed   int64
+}
+
+// NewCommandStats creates empty command stats
+func NewCommandStats() *CommandStats {
+	return &CommandStats{commands: make(map[string]*commandStat)}
+}
+
+// Record counts a call of the named command. A rejected command was refused
+// before it ran; a failed one ran and replied with an error.
+func (s *CommandStats) Record(name string, d time.Duration, rejected, failed bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stat, ok := s.commands[name]
+	if !ok {
+		stat = &commandStat{}
+		s.commands[name] = stat
+	}
+
+	if rejected {
+		stat.rejected++
+		return
+	}
+	stat.calls++
+	stat.duration += d
+	if failed {
+		stat.failed++
+	}
+}
+
+// Info returns the commandstats section of INFO
+func (s *CommandStats) Info() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.commands))
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteString("# Commandstats\n")
+	for _, name := range names {
+		stat := s.commands[name]
+		usec := stat.duration.Microseconds()
+		perCall := 0.0
+		if stat.calls > 0 {
+			perCall = float64(usec) / float64(stat.calls)
+		}
+		fmt.Fprintf(&b, "cmdstat_%s:calls=%d,usec=%d,usec_per_call=%.2f,rejected_calls=%d,failed_calls=%d\n",
+			name, stat.calls, usec, perCall, stat.rejected, stat.failed)
+	}
+
+	return b.String()
+}
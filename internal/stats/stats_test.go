@@ -0,0 +1,48 @@
+package stats
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCommandStatsInfo(t *testing.T) {
+	type call struct {
+		name     string
+		d        time.Duration
+		rejected bool
+		failed   bool
+	}
+	tests := []struct {
+		name  string
+		calls []call
+		want  []string
+	}{
+		{"empty", nil, nil},
+		{"calls", []call{{"get", 10 * time.Microsecond, false, false}, {"get", 20 * time.Microsecond, false, false}}, []string{
+			"cmdstat_get:calls=2,usec=30,usec_per_call=15.00,rejected_calls=0,failed_calls=0",
+		}},
+		{"rejected and failed", []call{{"set", time.Microsecond, true, false}, {"incr", 3 * time.Microsecond, false, true}}, []string{
+			"cmdstat_incr:calls=1,usec=3,usec_per_call=3.00,rejected_calls=0,failed_calls=1",
+			"cmdstat_set:calls=0,usec=0,usec_per_call=0.00,rejected_calls=1,failed_calls=0",
+		}},
+		{"subcommands", []call{{"config|get", 2 * time.Microsecond, false, false}, {"config|set", time.Microsecond, false, true}}, []string{
+			"cmdstat_config|get:calls=1,usec=2,usec_per_call=2.00,rejected_calls=0,failed_calls=0",
+			"cmdstat_config|set:calls=1,usec=1,usec_per_call=1.00,rejected_calls=0,failed_calls=1",
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewCommandStats()
+			for _, c := range tt.calls {
+				s.Record(c.name, c.d, c.rejected, c.failed)
+			}
+
+			want := strings.Join(append([]string{"# Commandstats"}, tt.want...), "\n") + "\n"
+			if got := s.Info(); got != want {
+				t.Errorf("Info() = %q, want %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"maps"
+	"time"
+	"unsafe"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/rdb"
+)
+
+// Ensure Store implements rdb.Dataset
+var _ rdb.Dataset = (*Store)(nil)
+
+// slotSize estimates the memory a copied map slot takes. Keys and values
+// are shared with the original, only the map itself is duplicated.
+const slotSize = int64(unsafe.Sizeof("") + unsafe.Sizeof(entry{}))
+
+// snapshot is a point-in-time view of the store. It holds the shards as they
+// were when it was taken, which the store copies instead of modifying while
+// any snapshot is open, like the pages of a forked process.
+type snapshot struct {
+	store  *Store
+	shards [shardCount]map[string]entry
+}
+
+// Snapshot returns a point-in-time view of the data for saving. Taking it
+// costs no copy; writes go on and copy the shards they modify instead.
+func (s *Store) Snapshot() rdb.Snapshot {
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+
+	// Every shard is locked at once so that the snapshot doesn't see some
+	// writes made while it is taken and miss earlier ones
+	for i := range s.shards {
+		s.shards[i].mu.Lock()
+	}
+	snap := &snapshot{store: s}
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.shared = true
+		snap.shards[i] = sh.entries
+		sh.mu.Unlock()
+	}
+
+	s.snapshots++
+	return snap
+}
+
+// Dirty returns the number of changes made to the data so far
+func (s *Store) Dirty() int64 {
+	return s.dirty.Load()
+}
+
+// writable returns the entries of sh, first copying them if a snapshot may
+// be reading them. Callers must hold sh.mu for writing.
+func (s *Store) writable(sh *shard) map[string]entry {
+	if sh.shared {
+		sh.entries = maps.Clone(sh.entries)
+		sh.shared = false
+		s.cowSize.Add(int64(len(sh.entries)) * slotSize)
+	}
+	return sh.entries
+}
+
+// WriteRDB writes the keys that have not expired as database 0
+func (snap *snapshot) WriteRDB(w *rdb.Writer) {
+	now := time.Now()
+	size, expires := 0, 0
+	for _, shard := range snap.shards {
+		for _, e := range shard {
+			if e.expired(now) {
+				continue
+			}
+			size++
+			if e.expiryTime != nil {
+				expires++
+			}
+		}
+	}
+
+	w.SelectDB(0, size, expires)
+	for _, shard := range snap.shards {
+		for key, e := range shard {
+			if !e.expired(now) {
+				w.String(key, e.value, e.expiryTime)
+			}
+		}
+	}
+}
+
+// CopyOnWriteSize returns the size of the shards copied by writes while
+// snapshots are open
+func (snap *snapshot) CopyOnWriteSize() int64 {
+	return snap.store.cowSize.Load()
+}
+
+// Release closes the snapshot. Once none are open, the store modifies its
+// shards in place again.
+func (snap *snapshot) Release() {
+	s := snap.store
+	s.snapMu.Lock()
+	defer s.snapMu.Unlock()
+
+	snap.shards = [shardCount]map[string]entry{}
+	s.snapshots--
+	if s.snapshots == 0 {
+		for i := range s.shards {
+			sh := &s.shards[i]
+			sh.mu.Lock()
+			sh.shared = false
+			sh.mu.Unlock()
+		}
+		s.cowSize.Store(0)
+	}
+}
@@ -0,0 +1,209 @@
+package memory
+
+import (
+	"bytes"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/rdb"
+)
+
+// Saving and loading the store again must give back every key
+func TestSaveAndLoadRDB(t *testing.T) {
+	values := map[string][]byte{
+		"empty":      {},
+		"raw":        []byte("hello"),
+		"int":        []byte("-123456"),
+		"big int":    []byte("12345678901234567890"),
+		"compressed": bytes.Repeat([]byte("compressible "), 100),
+		"binary":     {0x00, 0xff, '\r', '\n'},
+	}
+
+	store := NewStore()
+	for k, v := range values {
+		store.Set(k, v)
+	}
+	store.SetPX("expiring", []byte("soon"), 60000)
+	store.SetPX("expired", []byte("gone"), 1)
+	time.Sleep(5 * time.Millisecond)
+
+	path := filepath.Join(t.TempDir(), "dump.rdb")
+	saver := rdb.NewSaver(store, func() string { return path }, "7.4.0")
+	if err := saver.Save(); err != nil {
+		t.Fatalf("Save() = %v", err)
+	}
+
+	loaded := NewStore()
+	if err := loaded.LoadRDB(path); err != nil {
+		t.Fatalf("LoadRDB() = %v", err)
+	}
+
+	keys := loaded.GetKeys()
+	slices.Sort(keys)
+	want := append(sortedKeys(values), "expiring")
+	slices.Sort(want)
+	if !slices.Equal(keys, want) {
+		t.Errorf("keys = %q, want %q", keys, want)
+	}
+	for k, v := range values {
+		if got, ok := loaded.Get(k); !ok || !bytes.Equal(got, v) {
+			t.Errorf("Get(%q) = %q, %v, want %q", k, got, ok, v)
+		}
+		if ttl, _ := loaded.TTL(k); ttl != -1 {
+			t.Errorf("TTL(%q) = %v, want none", k, ttl)
+		}
+	}
+	if ttl, ok := loaded.TTL("expiring"); !ok || ttl <= 59*time.Second || ttl > 60*time.Second {
+		t.Errorf("TTL(expiring) = %v, %v, want about a minute", ttl, ok)
+	}
+}
+
+func sortedKeys(m map[string][]byte) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// view returns the values a snapshot holds, ignoring expiry
+func view(snap rdb.Snapshot) map[string]string {
+	values := make(map[string]string)
+	for _, shard := range snap.(*snapshot).shards {
+		for k, e := range shard {
+			values[k] = string(e.value)
+		}
+	}
+	return values
+}
+
+// Writes made after a snapshot is taken are not seen through it
+func TestSnapshotIsFrozen(t *testing.T) {
+	tests := []struct {
+		name  string
+		write func(s *Store)
+		want  map[string]string
+	}{
+		{"set", func(s *Store) { s.Set("a", []byte("changed")) }, map[string]string{"a": "changed", "n": "1"}},
+		{"add", func(s *Store) { s.Set("b", []byte("new")) }, map[string]string{"a": "1", "b": "new", "n": "1"}},
+		{"delete", func(s *Store) { s.Delete("a") }, map[string]string{"n": "1"}},
+		{"incr", func(s *Store) { s.IncrBy("n", 5) }, map[string]string{"a": "1", "n": "6"}},
+		{"expire", func(s *Store) { s.SetPX("a", []byte("2"), 60000) }, map[string]string{"a": "2", "n": "1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			s.Set("a", []byte("1"))
+			s.Set("n", []byte("1"))
+			snap := s.Snapshot()
+			defer snap.Release()
+
+			tt.write(s)
+			if got, want := view(snap), map[string]string{"a": "1", "n": "1"}; !maps.Equal(got, want) {
+				t.Errorf("snapshot = %v, want %v", got, want)
+			}
+			got := make(map[string]string)
+			for _, k := range s.GetKeys() {
+				v, _ := s.Get(k)
+				got[k] = string(v)
+			}
+			if !maps.Equal(got, tt.want) {
+				t.Errorf("store = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// The copies made by writes are reported until every snapshot is released,
+// and only the first write to a shard copies it
+func TestCopyOnWriteSize(t *testing.T) {
+	s := NewStore()
+	for i := range 1000 {
+		s.Set(strconv.Itoa(i), []byte("value"))
+	}
+
+	first := s.Snapshot()
+	if size := first.CopyOnWriteSize(); size != 0 {
+		t.Fatalf("CopyOnWriteSize() before writes = %d", size)
+	}
+	s.Set("0", []byte("changed"))
+	copied := first.CopyOnWriteSize()
+	if copied <= 0 {
+		t.Fatalf("CopyOnWriteSize() after a write = %d", copied)
+	}
+	s.Set("0", []byte("again"))
+	if size := first.CopyOnWriteSize(); size != copied {
+		t.Errorf("CopyOnWriteSize() after rewriting a shard = %d, want %d", size, copied)
+	}
+
+	// A second snapshot shares the shards again, so the next write copies
+	// once more
+	second := s.Snapshot()
+	s.Set("0", []byte("third"))
+	if size := second.CopyOnWriteSize(); size <= copied {
+		t.Errorf("CopyOnWriteSize() with two snapshots = %d, want more than %d", size, copied)
+	}
+	if got := view(second)["0"]; got != "again" {
+		t.Errorf("second snapshot sees %q, want %q", got, "again")
+	}
+
+	first.Release()
+	if size := second.CopyOnWriteSize(); size <= copied {
+		t.Errorf("CopyOnWriteSize() with a snapshot open = %d", size)
+	}
+	second.Release()
+	if s.cowSize.Load() != 0 || s.snapshots != 0 {
+		t.Errorf("after Release: cowSize %d, snapshots %d", s.cowSize.Load(), s.snapshots)
+	}
+
+	// Without open snapshots writes modify the shards in place
+	s.Set("0", []byte("fourth"))
+	if size := s.cowSize.Load(); size != 0 {
+		t.Errorf("cowSize after writing without snapshots = %d", size)
+	}
+}
+
+// Snapshots can be read while writers keep changing the store. Run with
+// -race to check that readers and writers never share a map.
+func TestSnapshotConcurrentWrites(t *testing.T) {
+	s := NewStore()
+	for i := range 1000 {
+		s.Set(strconv.Itoa(i), []byte("before"))
+	}
+	snap := s.Snapshot()
+	defer snap.Release()
+
+	var wg sync.WaitGroup
+	for w := range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 1000 {
+				key := strconv.Itoa((i + w*250) % 1000)
+				s.Set(key, []byte("after"))
+				s.Delete(strconv.Itoa(i))
+			}
+		}()
+	}
+
+	var buf bytes.Buffer
+	snap.WriteRDB(rdb.NewWriter(&buf))
+	wg.Wait()
+
+	values := view(snap)
+	if len(values) != 1000 {
+		t.Fatalf("snapshot has %d keys, want 1000", len(values))
+	}
+	for k, v := range values {
+		if v != "before" {
+			t.Fatalf("snapshot %q = %q, want before", k, v)
+		}
+	}
+}
@@ -2,24 +2,73 @@ package memory
 
 import (
 	"fmt"
+	"hash/maphash"
+	"math"
 	"os"
+	"strconv"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/codecrafters-io/redis-starter-go/internal/notify"
 	"github.com/codecrafters-io/redis-starter-go/internal/storage"
 	"github.com/hdt3213/rdb/model"
 	"github.com/hdt3213/rdb/parser"
 )
 
+// shardCount is the number of maps keys are spread over. Each has its own
+// lock, and a write during a snapshot copies a single shard, so more shards
+// mean less contention and smaller copies.
+const shardCount = 256
+
+const (
+	// expireSample is how many keys with an expiry time active expiry
+	// checks in a shard at a time
+	expireSample = 20
+
+	// expireAcceptable is the percentage of expired keys in a sample at or
+	// below which active expiry moves on to the next shard
+	expireAcceptable = 25
+)
+
 // Store represents an in-memory Redis-like data store
 type Store struct {
-	mu   sync.RWMutex
-	data map[string]entry
+	seed     maphash.Seed
+	shards   [shardCount]shard
+	notifier atomic.Pointer[notify.Notifier]
+
+	// dirty counts the changes made to the data, including expirations
+	dirty atomic.Int64
+
+	// snapMu guards snapshots, the number of open snapshots. cowSize is the
+	// size of the shards copied while snapshots are open.
+	snapMu    sync.Mutex
+	snapshots int
+	cowSize   atomic.Int64
+
+	// expireMu serializes active expiry cycles. expireNext is the shard the
+	// next cycle starts from.
+	expireMu   sync.Mutex
+	expireNext int
+}
+
+// shard holds the keys hashing to it, guarded by its own lock
+type shard struct {
+	mu      sync.RWMutex
+	entries map[string]entry
+
+	// expires indexes the keys with an expiry time, which active expiry
+	// samples
+	expires map[string]struct{}
+
+	// shared marks entries as possibly read by open snapshots, so that it
+	// is copied before being written to
+	shared bool
 }
 
 // entry represents a value in the store
 type entry struct {
-	value      string
+	value      []byte
 	expiryTime *time.Time
 }
 
@@ -28,57 +77,147 @@ var _ storage.Storage = (*Store)(nil)
 
 // NewStore creates a new in-memory store
 func NewStore() *Store {
-	return &Store{
-		data: make(map[string]entry),
+	s := &Store{seed: maphash.MakeSeed()}
+	for i := range s.shards {
+		s.shards[i].entries = make(map[string]entry)
+		s.shards[i].expires = make(map[string]struct{})
 	}
+	return s
+}
+
+// SetNotifier sets the notifier used to publish keyspace events for
+// mutations and expirations
+func (s *Store) SetNotifier(notifier *notify.Notifier) {
+	s.notifier.Store(notifier)
 }
 
 // Set sets a key to a string value
-func (s *Store) Set(key, value string) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+func (s *Store) Set(key string, value []byte) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	_, existed := sh.live(key)
+	s.put(sh, key, entry{value: value})
+	sh.mu.Unlock()
 
-	s.data[key] = entry{value: value}
+	notifier := s.notifier.Load()
+	if !existed {
+		notifier.Notify(notify.New, "new", key)
+	}
+	notifier.Notify(notify.String, "set", key)
 }
 
 // SetPX sets a key with an expiration time in milliseconds
-func (s *Store) SetPX(key, value string, millisecond int) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
+func (s *Store) SetPX(key string, value []byte, millisecond int) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	_, existed := sh.live(key)
 	expiryTime := time.Now().Add(time.Duration(millisecond) * time.Millisecond)
-	s.data[key] = entry{
+	s.put(sh, key, entry{
 		value:      value,
 		expiryTime: &expiryTime,
+	})
+	sh.mu.Unlock()
+
+	notifier := s.notifier.Load()
+	if !existed {
+		notifier.Notify(notify.New, "new", key)
 	}
+	notifier.Notify(notify.String, "set", key)
+	notifier.Notify(notify.Generic, "expire", key)
 }
 
 // Get retrieves a string value for a key
-func (s *Store) Get(key string) (string, bool) {
-	s.mu.RLock()
-	defer s.mu.RUnlock()
+func (s *Store) Get(key string) ([]byte, bool) {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	e, ok := sh.entries[key]
+	sh.mu.RUnlock()
+
+	if ok && e.expired(time.Now()) {
+		s.expire(key)
+		ok = false
+	}
 
-	e, ok := s.data[key]
 	if !ok {
-		return "", false
+		s.notifier.Load().Notify(notify.KeyMiss, "keymiss", key)
+		return nil, false
+	}
+
+	return e.value, true
+}
+
+// IncrBy adds delta to the integer stored at key, keeping its expiration
+func (s *Store) IncrBy(key string, delta int64) (int64, error) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	e, existed := sh.live(key)
+
+	var current int64
+	if existed {
+		n, err := strconv.ParseInt(string(e.value), 10, 64)
+		if err != nil {
+			sh.mu.Unlock()
+			return 0, storage.ErrNotInteger
+		}
+		current = n
+	}
+
+	if (delta > 0 && current > math.MaxInt64-delta) || (delta < 0 && current < math.MinInt64-delta) {
+		sh.mu.Unlock()
+		return 0, storage.ErrOverflow
+	}
+
+	current += delta
+	e.value = strconv.AppendInt(nil, current, 10)
+	s.put(sh, key, e)
+	sh.mu.Unlock()
+
+	notifier := s.notifier.Load()
+	if !existed {
+		notifier.Notify(notify.New, "new", key)
 	}
+	notifier.Notify(notify.String, "incrby", key)
 
-	// Check if expired
-	if e.expiryTime != nil && time.Now().After(*e.expiryTime) {
-		// Should delete, but we're holding a read lock
-		// In a more complete implementation, we'd use a background goroutine for cleanup
-		return "", false
+	return current, nil
+}
+
+// TTL returns the remaining time to live of a key
+func (s *Store) TTL(key string) (time.Duration, bool) {
+	sh := s.shard(key)
+	sh.mu.RLock()
+	e, ok := sh.entries[key]
+	sh.mu.RUnlock()
+
+	now := time.Now()
+	if ok && e.expired(now) {
+		s.expire(key)
+		ok = false
+	}
+
+	if !ok {
+		return 0, false
+	}
+	if e.expiryTime == nil {
+		return -1, true
 	}
 
-	// str, ok := e.value.(string)
-	str := e.value
-	return str, ok
+	return e.expiryTime.Sub(now), true
 }
 
+// GetKeys returns all keys that have not expired. Shards are read one at a
+// time, so keys written meanwhile may or may not be included.
 func (s *Store) GetKeys() []string {
+	now := time.Now()
 	keys := make([]string, 0)
-	for k := range s.data {
-		keys = append(keys, k)
+	for i := range s.shards {
+		sh := &s.shards[i]
+		sh.mu.RLock()
+		for k, e := range sh.entries {
+			if !e.expired(now) {
+				keys = append(keys, k)
+			}
+		}
+		sh.mu.RUnlock()
 	}
 
 	return keys
@@ -86,18 +225,142 @@ func (s *Store) GetKeys() []string {
 
 // Delete removes a key from the store
 func (s *Store) Delete(key string) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
+	sh := s.shard(key)
+	sh.mu.Lock()
+	e, ok := sh.entries[key]
+	if ok {
+		s.remove(sh, key)
+	}
+	sh.mu.Unlock()
 
-	_, exists := s.data[key]
-	if !exists {
+	if !ok {
+		return false
+	}
+	if e.expired(time.Now()) {
+		s.notifier.Load().Notify(notify.Expired, "expired", key)
 		return false
 	}
 
-	delete(s.data, key)
+	s.notifier.Load().Notify(notify.Generic, "del", key)
 	return true
 }
 
+// ActiveExpire removes expired keys nobody reads again, so that they are
+// freed and their expired notifications published close to their TTL. As
+// in Redis, it samples the keys with an expiry time shard by shard,
+// sampling a shard again while more than a quarter of the sample had
+// expired, and stops once budget has elapsed; the next call resumes where
+// it stopped. It returns how many keys were removed.
+func (s *Store) ActiveExpire(budget time.Duration) int {
+	s.expireMu.Lock()
+	defer s.expireMu.Unlock()
+
+	deadline := time.Now().Add(budget)
+	removed := 0
+	for range shardCount {
+		for {
+			expired, sampled := s.expireSampled(&s.shards[s.expireNext])
+			removed += expired
+			if time.Now().After(deadline) {
+				return removed
+			}
+			if expired*100 <= sampled*expireAcceptable {
+				break
+			}
+		}
+		s.expireNext = (s.expireNext + 1) % shardCount
+	}
+
+	return removed
+}
+
+// expireSampled checks up to expireSample keys with an expiry time in sh,
+// picked at random by map iteration, and removes the expired ones holding
+// only the shard's lock. It returns how many keys it removed and sampled.
+func (s *Store) expireSampled(sh *shard) (expired, sampled int) {
+	now := time.Now()
+	var keys []string
+
+	sh.mu.Lock()
+	for key := range sh.expires {
+		if sampled == expireSample {
+			break
+		}
+		sampled++
+		if sh.entries[key].expired(now) {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		s.remove(sh, key)
+	}
+	sh.mu.Unlock()
+
+	notifier := s.notifier.Load()
+	for _, key := range keys {
+		notifier.Notify(notify.Expired, "expired", key)
+	}
+
+	return len(keys), sampled
+}
+
+// expire deletes a key found to be expired on access and publishes the
+// expired event. The entry is re-checked under the write lock since it may
+// have been overwritten since it was read.
+func (s *Store) expire(key string) {
+	sh := s.shard(key)
+	sh.mu.Lock()
+	e, ok := sh.entries[key]
+	deleted := ok && e.expired(time.Now())
+	if deleted {
+		s.remove(sh, key)
+	}
+	sh.mu.Unlock()
+
+	if deleted {
+		s.notifier.Load().Notify(notify.Expired, "expired", key)
+	}
+}
+
+// shard returns the shard holding key
+func (s *Store) shard(key string) *shard {
+	return &s.shards[maphash.String(s.seed, key)%shardCount]
+}
+
+// put stores the entry for key in sh. Callers must hold sh.mu for writing.
+func (s *Store) put(sh *shard, key string, e entry) {
+	s.writable(sh)[key] = e
+	if e.expiryTime != nil {
+		sh.expires[key] = struct{}{}
+	} else {
+		delete(sh.expires, key)
+	}
+	s.dirty.Add(1)
+}
+
+// remove deletes key from sh. Callers must hold sh.mu for writing.
+func (s *Store) remove(sh *shard, key string) {
+	delete(s.writable(sh), key)
+	delete(sh.expires, key)
+	s.dirty.Add(1)
+}
+
+// live returns the entry for key if it exists and has not expired.
+// Callers must hold sh.mu.
+func (sh *shard) live(key string) (entry, bool) {
+	e, ok := sh.entries[key]
+	if !ok || e.expired(time.Now()) {
+		return entry{}, false
+	}
+
+	return e, true
+}
+
+// expired reports whether the entry's expiry time has passed
+func (e entry) expired(now time.Time) bool {
+	return e.expiryTime != nil && now.After(*e.expiryTime)
+}
+
 // LoadRDB loads data from an RDB file
 func (s *Store) LoadRDB(filename string) error {
 	file, err := os.Open(filename)
@@ -115,7 +378,7 @@ func (s *Store) LoadRDB(filename string) error {
 
 		switch value := object.(type) {
 		case *model.StringObject:
-			val := string(value.Value)
+			val := value.Value
 			if expiry != nil {
 				if !time.Now().After(*expiry) { // not expired yet
 					expTimeMilli := time.Until(*expiry).Milliseconds()
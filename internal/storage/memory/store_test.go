@@ -0,0 +1,142 @@
+package memory
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/codecrafters-io/redis-starter-go/internal/notify"
+)
+
+// eventRecorder records keyevent notifications as "event key"
+type eventRecorder struct {
+	events []string
+}
+
+func (r *eventRecorder) Publish(channel string, message []byte) int {
+	r.events = append(r.events, channel[len("__keyevent@0__:"):]+" "+string(message))
+	return 0
+}
+
+func TestKeyspaceEvents(t *testing.T) {
+	tests := []struct {
+		name string
+		// setup runs before notifications are enabled
+		setup func(s *Store)
+		op    func(s *Store)
+		want  []string
+	}{
+		{"set new key", nil, func(s *Store) { s.Set("k", []byte("v")) }, []string{"new k", "set k"}},
+		{"overwrite", func(s *Store) { s.Set("k", []byte("v")) }, func(s *Store) { s.Set("k", []byte("w")) }, []string{"set k"}},
+		{"set with expiry", nil, func(s *Store) { s.SetPX("k", []byte("v"), 1000) }, []string{"new k", "set k", "expire k"}},
+		{"incr new key", nil, func(s *Store) { s.IncrBy("k", 1) }, []string{"new k", "incrby k"}},
+		{"incr not an integer", func(s *Store) { s.Set("k", []byte("v")) }, func(s *Store) { s.IncrBy("k", 1) }, nil},
+		{"delete", func(s *Store) { s.Set("k", []byte("v")) }, func(s *Store) { s.Delete("k") }, []string{"del k"}},
+		{"delete missing", nil, func(s *Store) { s.Delete("k") }, nil},
+		{"get", func(s *Store) { s.Set("k", []byte("v")) }, func(s *Store) { s.Get("k") }, nil},
+		{"get missing", nil, func(s *Store) { s.Get("k") }, []string{"keymiss k"}},
+		{"get expired", func(s *Store) { s.SetPX("k", []byte("v"), 1); time.Sleep(5 * time.Millisecond) }, func(s *Store) { s.Get("k") }, []string{"expired k", "keymiss k"}},
+		{"delete expired", func(s *Store) { s.SetPX("k", []byte("v"), 1); time.Sleep(5 * time.Millisecond) }, func(s *Store) { s.Delete("k") }, []string{"expired k"}},
+		{"active expiry", func(s *Store) { s.SetPX("k", []byte("v"), 1); time.Sleep(5 * time.Millisecond) }, func(s *Store) { s.ActiveExpire(time.Second) }, []string{"expired k"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+
+			recorder := &eventRecorder{}
+			notifier := notify.NewNotifier(recorder)
+			if err := notifier.Configure("EAmn"); err != nil {
+				t.Fatal(err)
+			}
+			s.SetNotifier(notifier)
+
+			tt.op(s)
+			if !slices.Equal(recorder.events, tt.want) {
+				t.Errorf("events %q, want %q", recorder.events, tt.want)
+			}
+		})
+	}
+}
+
+// Every change to the data counts towards the save points, including
+// expirations, while reads and failed writes don't
+func TestDirty(t *testing.T) {
+	tests := []struct {
+		name string
+		op   func(s *Store)
+		want int64
+	}{
+		{"set", func(s *Store) { s.Set("new", []byte("v")) }, 1},
+		{"overwrite", func(s *Store) { s.Set("k", []byte("w")) }, 1},
+		{"set with expiry", func(s *Store) { s.SetPX("k", []byte("w"), 60000) }, 1},
+		{"incr", func(s *Store) { s.IncrBy("n", 1) }, 1},
+		{"incr not an integer", func(s *Store) { s.IncrBy("k", 1) }, 0},
+		{"incr overflow", func(s *Store) { s.IncrBy("n", math.MaxInt64) }, 0},
+		{"delete", func(s *Store) { s.Delete("k") }, 1},
+		{"delete missing", func(s *Store) { s.Delete("missing") }, 0},
+		{"get", func(s *Store) { s.Get("k") }, 0},
+		{"get expired", func(s *Store) { s.Get("expired") }, 1},
+		{"ttl expired", func(s *Store) { s.TTL("expired") }, 1},
+		{"active expiry", func(s *Store) { s.ActiveExpire(time.Second) }, 1},
+		{"keys", func(s *Store) { s.GetKeys() }, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewStore()
+			s.Set("k", []byte("v"))
+			s.Set("n", []byte("1"))
+			s.SetPX("expired", []byte("v"), 1)
+			time.Sleep(2 * time.Millisecond)
+
+			before := s.Dirty()
+			tt.op(s)
+			if got := s.Dirty() - before; got != tt.want {
+				t.Errorf("changes = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestActiveExpire(t *testing.T) {
+	s := NewStore()
+	for i := range 5000 {
+		s.SetPX("short:"+strconv.Itoa(i), []byte("v"), 1)
+	}
+	for i := range 100 {
+		s.SetPX("long:"+strconv.Itoa(i), []byte("v"), 60000)
+		s.Set("plain:"+strconv.Itoa(i), []byte("v"))
+	}
+
+	// Overwriting a key without an expiry time takes it out of the index
+	s.SetPX("plain:0", []byte("v"), 1)
+	s.Set("plain:0", []byte("v"))
+	time.Sleep(5 * time.Millisecond)
+
+	// Without a budget each call samples a single shard, and the next one
+	// picks up where it stopped
+	if removed := s.ActiveExpire(0); removed == 0 || removed == 5000 {
+		t.Errorf("ActiveExpire(0) removed %d keys, want some", removed)
+	}
+	for range shardCount {
+		s.ActiveExpire(0)
+	}
+	s.ActiveExpire(time.Second)
+	if got := len(s.GetKeys()); got != 200 {
+		t.Errorf("%d keys left, want 200", got)
+	}
+
+	indexed := 0
+	for i := range s.shards {
+		indexed += len(s.shards[i].expires)
+	}
+	if indexed != 100 {
+		t.Errorf("%d keys indexed by expiry time, want 100", indexed)
+	}
+}
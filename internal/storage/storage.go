@@ -1,15 +1,38 @@
 package storage
 
-// Storage defines the interface for data persistence operations
+import (
+	"errors"
+	"time"
+)
+
+var (
+	// ErrNotInteger is returned when a value cannot be used as a counter
+	ErrNotInteger = errors.New("value is not an integer or out of range")
+
+	// ErrOverflow is returned when incrementing a counter would overflow it
+	ErrOverflow = errors.New("increment or decrement would overflow")
+)
+
+// Storage defines the interface for data persistence operations. Values are
+// binary safe and are stored without copying, so callers must not modify a
+// slice after handing it to the storage or after receiving it from it.
 type Storage interface {
 	// Set stores value with no expiration
-	Set(key, value string)
+	Set(key string, value []byte)
 
 	// SetPX stores value with expiration in milliseconds
-	SetPX(key, value string, millisecond int)
+	SetPX(key string, value []byte, millisecond int)
 
 	// Get retrieves value, returning the value and whether it exists
-	Get(key string) (string, bool)
+	Get(key string) ([]byte, bool)
+
+	// IncrBy atomically adds delta to the integer stored at key, treating a
+	// missing key as 0, and returns the new value
+	IncrBy(key string, delta int64) (int64, error)
+
+	// TTL returns the remaining time to live of a key and whether it exists.
+	// A negative TTL means the key has no expiration.
+	TTL(key string) (time.Duration, bool)
 
 	// GetKeys returns all keys in the storage
 	GetKeys() []string